@@ -1,12 +1,18 @@
 package api
 
 import (
+	"context"
+	"errors"
 	"net/http"
+	"time"
 
 	"github.com/go-chi/chi/v5"
 	"github.com/go-chi/chi/v5/middleware"
 )
 
+// shutdownTimeout bounds how long Run waits for in-flight requests on shutdown.
+const shutdownTimeout = 10 * time.Second
+
 // DeviceHandler mounts HTTP routes on a chi router.
 type DeviceHandler interface {
 	Register(r chi.Router)
@@ -16,6 +22,9 @@ type DeviceHandler interface {
 type Server struct {
 	listenAddress string
 	handlers      map[string]DeviceHandler
+	adminAddress  string
+	admin         http.Handler
+	onShutdown    []func()
 }
 
 // NewServer is a factory to instantiate a new Server.
@@ -26,13 +35,62 @@ func NewServer(listenAddress string, handlers map[string]DeviceHandler) *Server
 	}
 }
 
-// Run registers all HandlerFuncs for the existing HTTP routes and starts the Server.
-func (s *Server) Run() error {
+// WithAdmin serves handler on a separate listener at address, so operational
+// endpoints such as metrics are not reachable through the public API port.
+func (s *Server) WithAdmin(address string, handler http.Handler) {
+	if address != "" && handler != nil {
+		s.adminAddress = address
+		s.admin = handler
+	}
+}
+
+// OnShutdown registers fn to run after the listeners have stopped, e.g. to
+// stop background workers.
+func (s *Server) OnShutdown(fn func()) {
+	if fn != nil {
+		s.onShutdown = append(s.onShutdown, fn)
+	}
+}
+
+// Run registers all HandlerFuncs for the existing HTTP routes and starts the
+// Server. It serves until ctx is cancelled, then shuts the listeners down
+// gracefully and runs the shutdown hooks.
+func (s *Server) Run(ctx context.Context) error {
 	r := chi.NewRouter()
 	r.Use(middleware.RequestID, middleware.Logger, middleware.Recoverer)
 
 	for prefix, handlers := range s.handlers {
 		r.Route(prefix, handlers.Register)
 	}
-	return http.ListenAndServe(s.listenAddress, r)
+
+	servers := []*http.Server{{Addr: s.listenAddress, Handler: r}}
+	if s.admin != nil {
+		servers = append(servers, &http.Server{Addr: s.adminAddress, Handler: s.admin})
+	}
+	errs := make(chan error, len(servers))
+	for _, server := range servers {
+		go func(server *http.Server) {
+			if err := server.ListenAndServe(); !errors.Is(err, http.ErrServerClosed) {
+				errs <- err
+			}
+		}(server)
+	}
+
+	var err error
+	select {
+	case <-ctx.Done():
+	case err = <-errs:
+	}
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancel()
+	for _, server := range servers {
+		if shutdownErr := server.Shutdown(shutdownCtx); err == nil {
+			err = shutdownErr
+		}
+	}
+	for _, fn := range s.onShutdown {
+		fn()
+	}
+	return err
 }
@@ -0,0 +1,69 @@
+package v0
+
+import (
+	"net/http"
+
+	"github.com/fiskaly/coding-challenges/signing-service-challenge/api/v0/utils"
+)
+
+type KeySpecResponse struct {
+	Name     string `json:"name"`
+	Strength int    `json:"strength"`
+}
+
+type AlgorithmResponse struct {
+	Name           string            `json:"name"`
+	KeySpecs       []KeySpecResponse `json:"key_specs"`
+	DefaultKeySpec string            `json:"default_key_spec"`
+	Schemes        []string          `json:"schemes"`
+	DefaultScheme  string            `json:"default_scheme"`
+	Digests        []string          `json:"digests"`
+	Encodings      []string          `json:"encodings"`
+	Symmetric      bool              `json:"symmetric"`
+}
+
+type AlgorithmsResponse struct {
+	Algorithms []AlgorithmResponse `json:"algorithms"`
+}
+
+// listAlgorithms lists the signing algorithms the service offers.
+func (h *Handler) listAlgorithms(response http.ResponseWriter, _ *http.Request) {
+	supported := h.algorithms.SupportedAlgorithms()
+	payload := make([]AlgorithmResponse, 0, len(supported))
+	for _, algorithm := range supported {
+		descriptor, ok := h.algorithms.DescribeAlgorithm(algorithm)
+		if !ok {
+			continue
+		}
+		specs := make([]KeySpecResponse, 0, len(descriptor.KeySpecs))
+		for _, spec := range descriptor.KeySpecs {
+			specs = append(specs, KeySpecResponse{Name: string(spec.Spec), Strength: spec.Strength})
+		}
+		schemes := make([]string, 0, len(descriptor.Schemes))
+		for _, scheme := range descriptor.Schemes {
+			schemes = append(schemes, string(scheme))
+		}
+		digests := make([]string, 0, len(descriptor.Digests))
+		for _, digest := range descriptor.Digests {
+			digests = append(digests, string(digest))
+		}
+		encodings := make([]string, 0, len(descriptor.Encodings))
+		for _, encoding := range descriptor.Encodings {
+			encodings = append(encodings, string(encoding))
+		}
+		payload = append(payload, AlgorithmResponse{
+			Name:           string(algorithm),
+			KeySpecs:       specs,
+			DefaultKeySpec: string(descriptor.DefaultKeySpec),
+			Schemes:        schemes,
+			DefaultScheme:  string(descriptor.DefaultScheme),
+			Digests:        digests,
+			Encodings:      encodings,
+			Symmetric:      descriptor.Symmetric,
+		})
+	}
+
+	utils.WriteAPIResponse(response, http.StatusOK, AlgorithmsResponse{
+		Algorithms: payload,
+	})
+}
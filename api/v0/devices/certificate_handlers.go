@@ -0,0 +1,185 @@
+package devices
+
+import (
+	"encoding/pem"
+	"io"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// Media types served by the certificate endpoints. DER carries a single
+// certificate, so chains are only served as PEM.
+const (
+	mediaTypeCertificatePEM = "application/pem-certificate-chain"
+	mediaTypeCertificateDER = "application/pkix-cert"
+	mediaTypeRequestDER     = "application/pkcs10"
+)
+
+// maxCertificateChainBytes bounds uploaded certificate chains.
+const maxCertificateChainBytes = 64 << 10
+
+// certificateFormats maps accepted media types (and ?format= values) onto served formats.
+var certificateFormats = map[string]string{
+	mediaTypeCertificatePEM:    mediaTypeCertificatePEM,
+	mediaTypePEM:               mediaTypeCertificatePEM,
+	"text/plain":               mediaTypeCertificatePEM,
+	"pem":                      mediaTypeCertificatePEM,
+	mediaTypeCertificateDER:    mediaTypeCertificateDER,
+	"application/octet-stream": mediaTypeCertificateDER,
+	"der":                      mediaTypeCertificateDER,
+}
+
+// certificateRequestFormats maps accepted media types (and ?format= values) onto
+// the formats served for certificate requests.
+var certificateRequestFormats = map[string]string{
+	mediaTypePEM:               mediaTypePEM,
+	"text/plain":               mediaTypePEM,
+	"pem":                      mediaTypePEM,
+	mediaTypeRequestDER:        mediaTypeRequestDER,
+	"application/octet-stream": mediaTypeRequestDER,
+	"der":                      mediaTypeRequestDER,
+}
+
+// createCertificateRequest returns a PKCS#10 request for the current device
+// key as PEM (default) or DER, for certification by an external CA.
+func (h *Handler) createCertificateRequest(w http.ResponseWriter, r *http.Request) {
+	id, err := h.deviceID(r)
+	if err != nil {
+		writeDomainError(w, err)
+		return
+	}
+	format, ok := negotiateFormat(r, certificateRequestFormats, mediaTypePEM)
+	if !ok {
+		writeErrorResponse(w, http.StatusNotAcceptable, []string{"supported formats: " + mediaTypePEM + ", " + mediaTypeRequestDER})
+		return
+	}
+
+	result, err := h.service.CreateCertificateRequest(r.Context(), id)
+	if err != nil {
+		writeDomainError(w, err)
+		return
+	}
+
+	w.Header().Set(keyVersionHeader, strconv.Itoa(result.KeyVersion))
+	body := result.Request
+	if format == mediaTypePEM {
+		body = pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE REQUEST", Bytes: result.Request})
+	}
+	writeRawResponse(w, http.StatusOK, format, body)
+}
+
+// uploadCertificate stores a PEM chain, leaf first, issued by an external CA
+// for the current device key and echoes the stored chain.
+func (h *Handler) uploadCertificate(w http.ResponseWriter, r *http.Request) {
+	id, err := h.deviceID(r)
+	if err != nil {
+		writeDomainError(w, err)
+		return
+	}
+	chain, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxCertificateChainBytes))
+	if err != nil {
+		writeErrorResponse(w, http.StatusBadRequest, []string{"invalid certificate chain payload"})
+		return
+	}
+
+	result, err := h.service.UploadCertificate(r.Context(), id, chain)
+	if err != nil {
+		writeDomainError(w, err)
+		return
+	}
+
+	w.Header().Set(keyVersionHeader, strconv.Itoa(result.KeyVersion))
+	writeCertificates(w, mediaTypeCertificatePEM, result.Chain)
+}
+
+// getCertificate serves the certificate chain of a device key as PEM (default)
+// or the leaf certificate as DER. ?version= selects an earlier key version.
+func (h *Handler) getCertificate(w http.ResponseWriter, r *http.Request) {
+	id, err := h.deviceID(r)
+	if err != nil {
+		writeDomainError(w, err)
+		return
+	}
+	version, err := keyVersionParam(r)
+	if err != nil {
+		writeDomainError(w, err)
+		return
+	}
+	format, ok := negotiateCertificateFormat(r)
+	if !ok {
+		writeErrorResponse(w, http.StatusNotAcceptable, []string{"supported formats: " + mediaTypeCertificatePEM + ", " + mediaTypeCertificateDER})
+		return
+	}
+
+	result, err := h.service.GetCertificate(r.Context(), id, version)
+	if err != nil {
+		writeDomainError(w, err)
+		return
+	}
+
+	w.Header().Set(keyVersionHeader, strconv.Itoa(result.KeyVersion))
+	writeCertificates(w, format, result.Chain)
+}
+
+// getRootCertificate serves the service root CA certificate, the trust anchor
+// for device certificates.
+func (h *Handler) getRootCertificate(w http.ResponseWriter, r *http.Request) {
+	format, ok := negotiateCertificateFormat(r)
+	if !ok {
+		writeErrorResponse(w, http.StatusNotAcceptable, []string{"supported formats: " + mediaTypeCertificatePEM + ", " + mediaTypeCertificateDER})
+		return
+	}
+
+	root, err := h.service.GetRootCertificate(r.Context())
+	if err != nil {
+		writeDomainError(w, err)
+		return
+	}
+	writeCertificates(w, format, [][]byte{root})
+}
+
+func writeCertificates(w http.ResponseWriter, format string, chain [][]byte) {
+	if format == mediaTypeCertificateDER {
+		writeRawResponse(w, http.StatusOK, format, chain[0])
+		return
+	}
+	var body []byte
+	for _, certificate := range chain {
+		body = append(body, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: certificate})...)
+	}
+	writeRawResponse(w, http.StatusOK, format, body)
+}
+
+// negotiateCertificateFormat picks the format from ?format= or the first supported Accept entry.
+func negotiateCertificateFormat(r *http.Request) (string, bool) {
+	return negotiateFormat(r, certificateFormats, mediaTypeCertificatePEM)
+}
+
+// negotiateFormat resolves ?format= or the first Accept entry found in formats;
+// fallback is served when the client accepts anything.
+func negotiateFormat(r *http.Request, formats map[string]string, fallback string) (string, bool) {
+	if value := r.URL.Query().Get("format"); value != "" {
+		format, ok := formats[strings.ToLower(value)]
+		return format, ok
+	}
+
+	accept := r.Header.Get("Accept")
+	if strings.TrimSpace(accept) == "" {
+		return fallback, true
+	}
+	for _, entry := range strings.Split(accept, ",") {
+		mediaType, _, err := mime.ParseMediaType(strings.TrimSpace(entry))
+		if err != nil {
+			continue
+		}
+		if mediaType == "*/*" || mediaType == "application/*" {
+			return fallback, true
+		}
+		if format, ok := formats[mediaType]; ok {
+			return format, true
+		}
+	}
+	return "", false
+}
@@ -19,13 +19,13 @@ func (h *Handler) createDevice(w http.ResponseWriter, r *http.Request) {
 		writeErrorResponse(w, http.StatusBadRequest, []string{"invalid request payload"})
 		return
 	}
-	errs := request.Validate()
+	errs := request.Validate(h.algorithms)
 	if len(errs) > 0 {
 		writeErrorsResponse(w, http.StatusBadRequest, errs)
 		return
 	}
 
-	algorithm, err := domain.ParseAlgorithm(request.Algorithm)
+	algorithm, err := domain.ParseAlgorithm(h.algorithms, request.Algorithm)
 	if err != nil {
 		writeDomainError(w, err)
 		return
@@ -38,19 +38,87 @@ func (h *Handler) createDevice(w http.ResponseWriter, r *http.Request) {
 	}
 
 	result, err := h.service.CreateDevice(r.Context(), appdevices.CreateDeviceInput{
-		ID:        uid,
-		Algorithm: algorithm,
-		Label:     request.Label,
+		ID:         uid,
+		Algorithm:  algorithm,
+		KeySpec:    domain.ParseKeySpec(request.KeySpec),
+		Scheme:     domain.ParseScheme(request.Scheme),
+		SaltLength: request.SaltLength,
+		Digest:     domain.ParseDigest(request.Digest),
+		Encoding:   domain.ParseEncoding(request.Encoding),
+		Secondary:  request.Secondary.secondaryKey(h.algorithms),
+		Label:      request.Label,
+	})
+	if err != nil {
+		writeDomainError(w, err)
+		return
+	}
+	writeAPIResponse(w, http.StatusCreated, devicePayload{
+		ID:         result.Device.ID.String(),
+		Algorithm:  string(result.Device.Algorithm),
+		KeySpec:    string(result.Device.KeySpec),
+		Scheme:     string(result.Device.Scheme),
+		SaltLength: result.Device.SaltLength,
+		Digest:     string(result.Device.Digest),
+		Encoding:   string(result.Device.Encoding),
+		KeyVersion: result.Device.KeyVersion,
+		Label:      result.Device.Label,
+		Counter:    0,
+		Secondary:  result.Device.Secondary,
+	})
+}
+
+// importDevice provisions a device around a caller-supplied private key.
+func (h *Handler) importDevice(w http.ResponseWriter, r *http.Request) {
+	var request importDeviceRequest
+	decoder := json.NewDecoder(r.Body)
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&request); err != nil {
+		writeErrorResponse(w, http.StatusBadRequest, []string{"invalid request payload"})
+		return
+	}
+	errs := request.Validate(h.algorithms)
+	if len(errs) > 0 {
+		writeErrorsResponse(w, http.StatusBadRequest, errs)
+		return
+	}
+
+	algorithm, err := domain.ParseAlgorithm(h.algorithms, request.Algorithm)
+	if err != nil {
+		writeDomainError(w, err)
+		return
+	}
+
+	uid, err := uuid.Parse(request.ID)
+	if err != nil {
+		writeDomainError(w, domain.ErrInvalidDeviceID)
+		return
+	}
+
+	result, err := h.service.ImportDevice(r.Context(), appdevices.ImportDeviceInput{
+		ID:         uid,
+		Algorithm:  algorithm,
+		PrivateKey: []byte(request.PrivateKey),
+		Scheme:     domain.ParseScheme(request.Scheme),
+		SaltLength: request.SaltLength,
+		Digest:     domain.ParseDigest(request.Digest),
+		Encoding:   domain.ParseEncoding(request.Encoding),
+		Label:      request.Label,
 	})
 	if err != nil {
 		writeDomainError(w, err)
 		return
 	}
 	writeAPIResponse(w, http.StatusCreated, devicePayload{
-		ID:        result.Device.ID.String(),
-		Algorithm: string(result.Device.Algorithm),
-		Label:     result.Device.Label,
-		Counter:   0,
+		ID:         result.Device.ID.String(),
+		Algorithm:  string(result.Device.Algorithm),
+		KeySpec:    string(result.Device.KeySpec),
+		Scheme:     string(result.Device.Scheme),
+		SaltLength: result.Device.SaltLength,
+		Digest:     string(result.Device.Digest),
+		Encoding:   string(result.Device.Encoding),
+		KeyVersion: result.Device.KeyVersion,
+		Label:      result.Device.Label,
+		Counter:    0,
 	})
 }
 
@@ -146,10 +214,17 @@ func (h *Handler) makeDevicesPayload(ctx context.Context, devices []domain.Devic
 	res := make([]devicePayload, len(devices))
 	for i, device := range devices {
 		res[i] = devicePayload{
-			ID:        device.ID.String(),
-			Algorithm: string(device.Algorithm),
-			Label:     device.Label,
-			Counter:   counters[device.ID],
+			ID:         device.ID.String(),
+			Algorithm:  string(device.Algorithm),
+			KeySpec:    string(device.KeySpec),
+			Scheme:     string(device.Scheme),
+			SaltLength: device.SaltLength,
+			Digest:     string(device.Digest),
+			Encoding:   string(device.Encoding),
+			KeyVersion: device.KeyVersion,
+			Label:      device.Label,
+			Counter:    counters[device.ID],
+			Secondary:  device.Secondary,
 		}
 	}
 	return res, nil
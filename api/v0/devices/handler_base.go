@@ -18,39 +18,58 @@ var (
 // Service captures the contract used by HTTP handlers.
 type Service interface {
 	CreateDevice(ctx context.Context, input appdevices.CreateDeviceInput) (*appdevices.CreateDeviceResult, error)
+	ImportDevice(ctx context.Context, input appdevices.ImportDeviceInput) (*appdevices.CreateDeviceResult, error)
 	ListDevices(ctx context.Context) ([]domain.Device, error)
 	GetDevice(ctx context.Context, id uuid.UUID) (domain.Device, error)
 	UpdateDeviceLabel(ctx context.Context, id uuid.UUID, label string) (domain.Device, error)
 	DeleteDevice(ctx context.Context, id uuid.UUID) error
 	SignTransaction(ctx context.Context, input appdevices.SignTransactionInput) (*appdevices.SignatureResult, error)
+	VerifySignature(ctx context.Context, input appdevices.VerifySignatureInput) (*appdevices.VerificationResult, error)
+	GetPublicKey(ctx context.Context, id uuid.UUID, version int) (*appdevices.PublicKeyResult, error)
+	RotateKey(ctx context.Context, id uuid.UUID) (domain.Device, error)
+	GetCertificate(ctx context.Context, id uuid.UUID, version int) (*appdevices.CertificateResult, error)
+	GetRootCertificate(ctx context.Context) ([]byte, error)
+	CreateCertificateRequest(ctx context.Context, id uuid.UUID) (*appdevices.CertificateRequestResult, error)
+	UploadCertificate(ctx context.Context, id uuid.UUID, chainPEM []byte) (*appdevices.CertificateResult, error)
 	GetCounters(ctx context.Context, ids []uuid.UUID) (map[uuid.UUID]uint64, error)
 	ListSignatures(ctx context.Context, deviceID uuid.UUID) ([]appdevices.SignatureRecord, error)
 	GetSignature(ctx context.Context, deviceID uuid.UUID, counter uint64) (appdevices.SignatureRecord, error)
+	GetSignatureCOSE(ctx context.Context, deviceID uuid.UUID, counter uint64) ([]byte, error)
+	GetSignatureCMS(ctx context.Context, deviceID uuid.UUID, counter uint64) ([]byte, error)
 }
 
 // Handler manages device-related HTTP endpoints.
 type Handler struct {
-	service Service
+	service    Service
+	algorithms domain.Algorithms
 }
 
-// New constructs a device handler.
-func New(service Service) *Handler {
-	return &Handler{service: service}
+// New constructs a device handler validating requests against algorithms.
+func New(service Service, algorithms domain.Algorithms) *Handler {
+	return &Handler{service: service, algorithms: algorithms}
 }
 
 // Register wires handler routes into the provided mux.
 func (h *Handler) Register(r chi.Router) {
 	r.Route("/devices", h.registerDevices)
+	r.Get("/ca", h.getRootCertificate)
 }
 
 func (h *Handler) registerDevices(r chi.Router) {
 	r.Post("/", h.createDevice)
+	r.Post("/import", h.importDevice)
 	r.Get("/", h.listDevices)
 	r.Get("/{device_id}", h.getDevice)
 	r.Put("/{device_id}", h.updateDevice)
 	r.Delete("/{device_id}", h.deleteDevice)
+	r.Get("/{device_id}/public-key", h.getPublicKey)
+	r.Post("/{device_id}/rotate-key", h.rotateKey)
+	r.Get("/{device_id}/certificate", h.getCertificate)
+	r.Put("/{device_id}/certificate", h.uploadCertificate)
+	r.Post("/{device_id}/csr", h.createCertificateRequest)
 
 	r.Post("/{device_id}/sign", h.signTransaction)
+	r.Post("/{device_id}/verify", h.verifySignature)
 	r.Get("/{device_id}/signatures", h.listSignatures)
 	r.Get("/{device_id}/signatures/{counter}", h.getSignature)
 }
@@ -12,6 +12,7 @@ import (
 	"github.com/fiskaly/coding-challenges/signing-service-challenge/api/v0/devices"
 	"github.com/fiskaly/coding-challenges/signing-service-challenge/domain"
 	appdevices "github.com/fiskaly/coding-challenges/signing-service-challenge/internal/devices"
+	"github.com/fiskaly/coding-challenges/signing-service-challenge/pkg/crypto"
 	"github.com/fiskaly/coding-challenges/signing-service-challenge/pkg/mocks"
 	"github.com/go-chi/chi/v5"
 	"github.com/golang/mock/gomock"
@@ -20,7 +21,7 @@ import (
 
 func newRouter(service devices.Service) http.Handler {
 	r := chi.NewRouter()
-	h := devices.New(service)
+	h := devices.New(service, crypto.DefaultRegistry())
 	h.Register(r)
 	return r
 }
@@ -175,6 +176,199 @@ func TestSignTransaction_Success(t *testing.T) {
 	}
 }
 
+func TestVerifySignature_Success(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	svc := mocks.NewMockDevicesService(ctrl)
+	router := newRouter(svc)
+
+	deviceID := uuid.New()
+	svc.EXPECT().VerifySignature(gomock.Any(), appdevices.VerifySignatureInput{
+		DeviceID:   deviceID,
+		SignedData: "1_payload_ref",
+		Signature:  "c2ln",
+	}).Return(&appdevices.VerificationResult{Valid: true}, nil)
+
+	body, _ := json.Marshal(map[string]string{"signed_data": "1_payload_ref", "signature": "c2ln"})
+	req := httptest.NewRequest(http.MethodPost, "/devices/"+deviceID.String()+"/verify", bytes.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d", w.Code)
+	}
+	type verifyResp struct {
+		Valid bool `json:"valid"`
+	}
+	if resp := decodeResponse[verifyResp](t, w.Body); !resp.Valid {
+		t.Fatal("expected valid=true")
+	}
+}
+
+func TestVerifySignature_MissingFields(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	svc := mocks.NewMockDevicesService(ctrl)
+	router := newRouter(svc)
+
+	body, _ := json.Marshal(map[string]string{"signed_data": "payload"})
+	req := httptest.NewRequest(http.MethodPost, "/devices/"+uuid.New().String()+"/verify", bytes.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusBadRequest {
+		t.Fatalf("expected status 400, got %d", w.Code)
+	}
+}
+
+func TestGetPublicKey_JWK(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	svc := mocks.NewMockDevicesService(ctrl)
+	router := newRouter(svc)
+
+	deviceID := uuid.New()
+	svc.EXPECT().GetPublicKey(gomock.Any(), deviceID, 0).Return(&appdevices.PublicKeyResult{
+		Key: appdevices.PublicKeyExport{
+			SPKI:  []byte{0x30},
+			JWK:   map[string]string{"kty": "OKP", "crv": "Ed25519", "x": "abc", "kid": "thumb"},
+			KeyID: "thumb",
+		},
+	}, nil)
+
+	req := httptest.NewRequest(http.MethodGet, "/devices/"+deviceID.String()+"/public-key?format=jwk", nil)
+	w := httptest.NewRecorder()
+
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d", w.Code)
+	}
+	if ct := w.Header().Get("Content-Type"); ct != "application/jwk+json" {
+		t.Fatalf("unexpected content type %q", ct)
+	}
+	var jwk map[string]string
+	if err := json.NewDecoder(w.Body).Decode(&jwk); err != nil {
+		t.Fatalf("decode jwk: %v", err)
+	}
+	if jwk["kid"] != "thumb" {
+		t.Fatalf("unexpected kid %q", jwk["kid"])
+	}
+}
+
+func TestRotateKey_NotFound(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	svc := mocks.NewMockDevicesService(ctrl)
+	router := newRouter(svc)
+
+	deviceID := uuid.New()
+	svc.EXPECT().RotateKey(gomock.Any(), deviceID).Return(domain.Device{}, domain.NotFoundError{Resource: "device", ID: deviceID.String()})
+
+	req := httptest.NewRequest(http.MethodPost, "/devices/"+deviceID.String()+"/rotate-key", nil)
+	w := httptest.NewRecorder()
+
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusNotFound {
+		t.Fatalf("expected status 404, got %d", w.Code)
+	}
+}
+
+func TestGetCertificate_Formats(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	svc := mocks.NewMockDevicesService(ctrl)
+	router := newRouter(svc)
+
+	deviceID := uuid.New()
+	svc.EXPECT().GetCertificate(gomock.Any(), deviceID, 1).Return(&appdevices.CertificateResult{
+		KeyVersion: 1,
+		Chain:      [][]byte{[]byte("leaf"), []byte("intermediate")},
+	}, nil).Times(2)
+
+	req := httptest.NewRequest(http.MethodGet, "/devices/"+deviceID.String()+"/certificate?version=1", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	if w.Code != http.StatusOK || w.Header().Get("Content-Type") != "application/pem-certificate-chain" {
+		t.Fatalf("expected PEM chain, got %d %q", w.Code, w.Header().Get("Content-Type"))
+	}
+	if count := bytes.Count(w.Body.Bytes(), []byte("BEGIN CERTIFICATE")); count != 2 {
+		t.Fatalf("expected 2 PEM certificates, got %d", count)
+	}
+
+	req = httptest.NewRequest(http.MethodGet, "/devices/"+deviceID.String()+"/certificate?version=1", nil)
+	req.Header.Set("Accept", "application/pkix-cert")
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	if w.Code != http.StatusOK || w.Body.String() != "leaf" || w.Header().Get("Key-Version") != "1" {
+		t.Fatalf("expected DER leaf certificate, got %d %q", w.Code, w.Body.String())
+	}
+
+	req = httptest.NewRequest(http.MethodGet, "/ca?format=jwk", nil)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	if w.Code != http.StatusNotAcceptable {
+		t.Fatalf("expected status 406, got %d", w.Code)
+	}
+}
+
+func TestCertificateRequestAndUpload(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	svc := mocks.NewMockDevicesService(ctrl)
+	router := newRouter(svc)
+
+	deviceID := uuid.New()
+	svc.EXPECT().CreateCertificateRequest(gomock.Any(), deviceID).Return(&appdevices.CertificateRequestResult{
+		KeyVersion: 3,
+		Request:    []byte("csr"),
+	}, nil).Times(2)
+
+	req := httptest.NewRequest(http.MethodPost, "/devices/"+deviceID.String()+"/csr", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	if w.Code != http.StatusOK || !bytes.Contains(w.Body.Bytes(), []byte("BEGIN CERTIFICATE REQUEST")) || w.Header().Get("Key-Version") != "3" {
+		t.Fatalf("expected PEM certificate request, got %d %q", w.Code, w.Body.String())
+	}
+
+	req = httptest.NewRequest(http.MethodPost, "/devices/"+deviceID.String()+"/csr?format=der", nil)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	if w.Code != http.StatusOK || w.Body.String() != "csr" || w.Header().Get("Content-Type") != "application/pkcs10" {
+		t.Fatalf("expected DER certificate request, got %d %q", w.Code, w.Body.String())
+	}
+
+	svc.EXPECT().UploadCertificate(gomock.Any(), deviceID, []byte("chain")).Return(&appdevices.CertificateResult{
+		KeyVersion: 3,
+		Chain:      [][]byte{[]byte("leaf"), []byte("issuer")},
+	}, nil)
+	req = httptest.NewRequest(http.MethodPut, "/devices/"+deviceID.String()+"/certificate", bytes.NewBufferString("chain"))
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	if w.Code != http.StatusOK || bytes.Count(w.Body.Bytes(), []byte("BEGIN CERTIFICATE")) != 2 {
+		t.Fatalf("expected stored PEM chain, got %d %q", w.Code, w.Body.String())
+	}
+
+	svc.EXPECT().UploadCertificate(gomock.Any(), deviceID, []byte("other")).Return(nil, domain.ValidationError{Field: "certificate", Message: "leaf certificate does not match the device public key"})
+	req = httptest.NewRequest(http.MethodPut, "/devices/"+deviceID.String()+"/certificate", bytes.NewBufferString("other"))
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	if w.Code != http.StatusUnprocessableEntity {
+		t.Fatalf("expected status 422, got %d", w.Code)
+	}
+}
+
 func TestGetSignature_InvalidCounter(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
@@ -229,3 +423,56 @@ func TestListSignatures_Success(t *testing.T) {
 		t.Fatalf("unexpected payload: %+v", payload)
 	}
 }
+
+func TestSignatureCOSENegotiation(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	svc := mocks.NewMockDevicesService(ctrl)
+	router := newRouter(svc)
+
+	deviceID := uuid.New()
+	message := []byte{0xd2, 0x84}
+	svc.EXPECT().SignTransaction(gomock.Any(), appdevices.SignTransactionInput{DeviceID: deviceID, Data: "payload", Format: domain.FormatCOSE}).Return(&appdevices.SignatureResult{
+		Signature:    "sig",
+		CounterValue: 1,
+		COSE:         message,
+	}, nil)
+	svc.EXPECT().GetSignatureCOSE(gomock.Any(), deviceID, uint64(1)).Return(message, nil).Times(2)
+
+	sign := func(payload string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/devices/"+deviceID.String()+"/sign", bytes.NewReader([]byte(payload)))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Accept", "application/cose")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	w := sign(`{"data":"payload"}`)
+	if w.Code != http.StatusOK || !bytes.Equal(w.Body.Bytes(), message) {
+		t.Fatalf("expected raw COSE_Sign1, got %d %x", w.Code, w.Body.Bytes())
+	}
+	if contentType := w.Header().Get("Content-Type"); contentType != `application/cose; cose-type="cose-sign1"` {
+		t.Fatalf("unexpected content type %q", contentType)
+	}
+	if w := sign(`{"data":"payload","format":"jws"}`); w.Code != http.StatusNotAcceptable {
+		t.Fatalf("expected status 406 for a JWS request, got %d", w.Code)
+	}
+
+	for _, tc := range []struct {
+		query  string
+		accept string
+	}{
+		{"?format=cose", ""},
+		{"", "application/json, application/cose"},
+	} {
+		req := httptest.NewRequest(http.MethodGet, "/devices/"+deviceID.String()+"/signatures/1"+tc.query, nil)
+		req.Header.Set("Accept", tc.accept)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		if w.Code != http.StatusOK || !bytes.Equal(w.Body.Bytes(), message) {
+			t.Fatalf("expected raw COSE_Sign1 for %q/%q, got %d %x", tc.query, tc.accept, w.Code, w.Body.Bytes())
+		}
+	}
+}
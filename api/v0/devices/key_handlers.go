@@ -0,0 +1,178 @@
+package devices
+
+import (
+	"encoding/json"
+	"encoding/pem"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/fiskaly/coding-challenges/signing-service-challenge/domain"
+)
+
+// Media types served by the public key endpoint.
+const (
+	mediaTypePEM  = "application/x-pem-file"
+	mediaTypeDER  = "application/pkix-spki"
+	mediaTypeJWK  = "application/jwk+json"
+	mediaTypeJSON = "application/json"
+)
+
+// keyVersionHeader reports which key version a public key export belongs to.
+const keyVersionHeader = "Key-Version"
+
+// publicKeyFormats maps accepted media types (and ?format= values) onto served formats.
+var publicKeyFormats = map[string]string{
+	mediaTypePEM:               mediaTypePEM,
+	"application/pem-file":     mediaTypePEM,
+	"text/plain":               mediaTypePEM,
+	"pem":                      mediaTypePEM,
+	mediaTypeDER:               mediaTypeDER,
+	"application/octet-stream": mediaTypeDER,
+	"der":                      mediaTypeDER,
+	mediaTypeJWK:               mediaTypeJWK,
+	mediaTypeJSON:              mediaTypeJWK,
+	"jwk":                      mediaTypeJWK,
+}
+
+// getPublicKey exports a device public key as SPKI PEM (default), DER or JWK.
+// ?version= selects an earlier key version after rotation and ?key=secondary
+// the second key pair of a hybrid device.
+func (h *Handler) getPublicKey(w http.ResponseWriter, r *http.Request) {
+	id, err := h.deviceID(r)
+	if err != nil {
+		writeDomainError(w, err)
+		return
+	}
+
+	version, err := keyVersionParam(r)
+	if err != nil {
+		writeDomainError(w, err)
+		return
+	}
+	secondary, err := secondaryKeyParam(r)
+	if err != nil {
+		writeDomainError(w, err)
+		return
+	}
+
+	format, ok := negotiatePublicKeyFormat(r)
+	if !ok {
+		writeErrorResponse(w, http.StatusNotAcceptable, []string{"supported formats: " + strings.Join([]string{mediaTypePEM, mediaTypeDER, mediaTypeJWK}, ", ")})
+		return
+	}
+
+	result, err := h.service.GetPublicKey(r.Context(), id, version)
+	if err != nil {
+		writeDomainError(w, err)
+		return
+	}
+	key := result.Key
+	if secondary {
+		if result.Secondary == nil {
+			writeDomainError(w, domain.ValidationError{
+				Field:   "key",
+				Message: "device has no secondary key",
+			})
+			return
+		}
+		key = *result.Secondary
+	}
+
+	var body []byte
+	switch format {
+	case mediaTypeDER:
+		body = key.SPKI
+	case mediaTypeJWK:
+		body, err = json.Marshal(key.JWK)
+		if err != nil {
+			writeInternalError(w)
+			return
+		}
+	default:
+		body = pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: key.SPKI})
+	}
+
+	w.Header().Set("ETag", `"`+key.KeyID+`"`)
+	w.Header().Set(keyVersionHeader, strconv.Itoa(result.KeyVersion))
+	writeRawResponse(w, http.StatusOK, format, body)
+}
+
+// rotateKey replaces the device signing key while keeping its counter and chain.
+func (h *Handler) rotateKey(w http.ResponseWriter, r *http.Request) {
+	id, err := h.deviceID(r)
+	if err != nil {
+		writeDomainError(w, err)
+		return
+	}
+
+	rotated, err := h.service.RotateKey(r.Context(), id)
+	if err != nil {
+		writeDomainError(w, err)
+		return
+	}
+	payloads, err := h.makeDevicesPayload(r.Context(), []domain.Device{rotated})
+	if err != nil {
+		writeDomainError(w, err)
+		return
+	}
+	writeAPIResponse(w, http.StatusOK, payloads[0])
+}
+
+// keyVersionParam reads the optional ?version= query parameter; zero selects the current key.
+func keyVersionParam(r *http.Request) (int, error) {
+	value := r.URL.Query().Get("version")
+	if value == "" {
+		return 0, nil
+	}
+	version, err := strconv.Atoi(value)
+	if err != nil || version < 1 {
+		return 0, domain.ValidationError{
+			Field:   "version",
+			Message: "version must be a positive integer",
+		}
+	}
+	return version, nil
+}
+
+// secondaryKeyParam reads the optional ?key= query parameter, which is
+// either "primary" (the default) or "secondary".
+func secondaryKeyParam(r *http.Request) (bool, error) {
+	switch strings.ToLower(r.URL.Query().Get("key")) {
+	case "", "primary":
+		return false, nil
+	case "secondary":
+		return true, nil
+	}
+	return false, domain.ValidationError{
+		Field:   "key",
+		Message: "key must be primary or secondary",
+	}
+}
+
+// negotiatePublicKeyFormat picks the format from ?format= or the first supported Accept entry.
+func negotiatePublicKeyFormat(r *http.Request) (string, bool) {
+	if value := r.URL.Query().Get("format"); value != "" {
+		format, ok := publicKeyFormats[strings.ToLower(value)]
+		return format, ok
+	}
+
+	accept := r.Header.Get("Accept")
+	if strings.TrimSpace(accept) == "" {
+		return mediaTypePEM, true
+	}
+	for _, entry := range strings.Split(accept, ",") {
+		mediaType, _, err := mime.ParseMediaType(strings.TrimSpace(entry))
+		if err != nil {
+			continue
+		}
+		if mediaType == "*/*" || mediaType == "application/*" {
+			return mediaTypePEM, true
+		}
+		if format, ok := publicKeyFormats[mediaType]; ok {
+			return format, true
+		}
+	}
+	return "", false
+}
@@ -2,18 +2,30 @@ package devices
 
 import (
 	"encoding/json"
+	"mime"
 	"net/http"
 	"strconv"
+	"strings"
 
 	"github.com/fiskaly/coding-challenges/signing-service-challenge/domain"
 	appdevices "github.com/fiskaly/coding-challenges/signing-service-challenge/internal/devices"
 	"github.com/go-chi/chi/v5"
 )
 
+const (
+	// mediaTypeCOSESign1 is served for COSE_Sign1 messages (RFC 9052 section 2).
+	mediaTypeCOSESign1 = `application/cose; cose-type="cose-sign1"`
+	// mediaTypeCMSSignature is served for detached CMS SignedData (RFC 8551 section 3.5.3).
+	mediaTypeCMSSignature = "application/pkcs7-signature"
+)
+
+// signTransaction signs a payload. Clients accepting application/cose receive
+// the bare COSE_Sign1 message instead of the JSON envelope.
 func (h *Handler) signTransaction(w http.ResponseWriter, r *http.Request) {
 	id, err := h.deviceID(r)
 	if err != nil {
 		writeDomainError(w, err)
+		return
 	}
 	var request signRequest
 	decoder := json.NewDecoder(r.Body)
@@ -23,21 +35,80 @@ func (h *Handler) signTransaction(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	format := domain.ParseFormat(request.Format)
+	rawCOSE := accepts(r, "application/cose")
+	if rawCOSE {
+		if format != "" && format != domain.FormatCOSE {
+			writeErrorResponse(w, http.StatusNotAcceptable, []string{"application/cose responses require the COSE format"})
+			return
+		}
+		format = domain.FormatCOSE
+	}
+
 	result, err := h.service.SignTransaction(r.Context(), appdevices.SignTransactionInput{
 		DeviceID: id,
 		Data:     request.Data,
+		Encoding: domain.ParseEncoding(request.Encoding),
+		Format:   format,
 	})
 	if err != nil {
 		writeDomainError(w, err)
 		return
 	}
 
+	if rawCOSE {
+		writeRawResponse(w, http.StatusOK, mediaTypeCOSESign1, result.COSE)
+		return
+	}
 	writeAPIResponse(w, http.StatusOK, signResponse{
 		Signature:  result.Signature,
 		SignedData: result.SignedData,
+		Scheme:     string(result.Scheme),
+		Encoding:   string(result.Encoding),
+		KeyVersion: result.KeyVersion,
+		JWS:        result.JWS,
+		COSE:       result.COSE,
+		Timestamp:  result.Timestamp,
+
+		SecondarySignature: result.SecondarySignature,
 	})
 }
 
+func (h *Handler) verifySignature(w http.ResponseWriter, r *http.Request) {
+	id, err := h.deviceID(r)
+	if err != nil {
+		writeDomainError(w, err)
+		return
+	}
+	var request verifyRequest
+	decoder := json.NewDecoder(r.Body)
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&request); err != nil {
+		writeErrorResponse(w, http.StatusBadRequest, []string{"invalid request payload"})
+		return
+	}
+	errs := request.Validate()
+	if len(errs) > 0 {
+		writeErrorsResponse(w, http.StatusBadRequest, errs)
+		return
+	}
+
+	result, err := h.service.VerifySignature(r.Context(), appdevices.VerifySignatureInput{
+		DeviceID:   id,
+		SignedData: request.SignedData,
+		Signature:  request.Signature,
+		KeyVersion: request.KeyVersion,
+		Encoding:   domain.ParseEncoding(request.Encoding),
+		Secondary:  request.Secondary,
+	})
+	if err != nil {
+		writeDomainError(w, err)
+		return
+	}
+
+	writeAPIResponse(w, http.StatusOK, verifyResponse{Valid: result.Valid, KeyVersion: result.KeyVersion})
+}
+
 func (h *Handler) listSignatures(w http.ResponseWriter, r *http.Request) {
 	deviceID, err := h.deviceID(r)
 	if err != nil {
@@ -56,7 +127,15 @@ func (h *Handler) listSignatures(w http.ResponseWriter, r *http.Request) {
 			Counter:    record.Counter,
 			Signature:  record.Signature,
 			SignedData: record.SignedData,
+			Scheme:     string(record.Scheme),
+			Encoding:   string(record.Encoding),
+			KeyVersion: record.KeyVersion,
+			JWS:        record.JWS,
+			COSE:       record.COSE,
+			Timestamp:  record.Timestamp,
 			CreatedAt:  record.CreatedAt,
+
+			SecondarySignature: record.SecondarySignature,
 		})
 	}
 
@@ -78,6 +157,26 @@ func (h *Handler) getSignature(w http.ResponseWriter, r *http.Request) {
 		})
 		return
 	}
+	format := strings.ToLower(strings.TrimSpace(r.URL.Query().Get("format")))
+	switch {
+	case format == "cose" || accepts(r, "application/cose"):
+		message, err := h.service.GetSignatureCOSE(r.Context(), deviceID, cnt)
+		if err != nil {
+			writeDomainError(w, err)
+			return
+		}
+		writeRawResponse(w, http.StatusOK, mediaTypeCOSESign1, message)
+		return
+	case format == "cms" || accepts(r, mediaTypeCMSSignature):
+		signedData, err := h.service.GetSignatureCMS(r.Context(), deviceID, cnt)
+		if err != nil {
+			writeDomainError(w, err)
+			return
+		}
+		writeRawResponse(w, http.StatusOK, mediaTypeCMSSignature, signedData)
+		return
+	}
+
 	record, err := h.service.GetSignature(r.Context(), deviceID, cnt)
 	if err != nil {
 		writeDomainError(w, err)
@@ -88,8 +187,27 @@ func (h *Handler) getSignature(w http.ResponseWriter, r *http.Request) {
 		Counter:    record.Counter,
 		Signature:  record.Signature,
 		SignedData: record.SignedData,
+		Scheme:     string(record.Scheme),
+		Encoding:   string(record.Encoding),
+		KeyVersion: record.KeyVersion,
+		JWS:        record.JWS,
+		COSE:       record.COSE,
+		Timestamp:  record.Timestamp,
 		CreatedAt:  record.CreatedAt,
+
+		SecondarySignature: record.SecondarySignature,
 	}
 
 	writeAPIResponse(w, http.StatusOK, payload)
 }
+
+// accepts reports whether the Accept header lists mediaType.
+func accepts(r *http.Request, mediaType string) bool {
+	for _, entry := range strings.Split(r.Header.Get("Accept"), ",") {
+		accepted, _, err := mime.ParseMediaType(strings.TrimSpace(entry))
+		if err == nil && accepted == mediaType {
+			return true
+		}
+	}
+	return false
+}
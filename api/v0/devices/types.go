@@ -1,6 +1,7 @@
 package devices
 
 import (
+	"strings"
 	"time"
 
 	"github.com/fiskaly/coding-challenges/signing-service-challenge/domain"
@@ -8,16 +9,119 @@ import (
 )
 
 type createDeviceRequest struct {
-	ID        string `json:"id"`
-	Algorithm string `json:"algorithm"`
-	Label     string `json:"label"`
+	ID         string `json:"id"`
+	Algorithm  string `json:"algorithm"`
+	KeySpec    string `json:"key_spec"`
+	Scheme     string `json:"scheme"`
+	SaltLength int    `json:"salt_length"`
+	Digest     string `json:"digest"`
+	Encoding   string `json:"encoding"`
+	Label      string `json:"label"`
+
+	Secondary *secondaryKeyRequest `json:"secondary,omitempty"`
+}
+
+// secondaryKeyRequest selects the second key pair of a hybrid device.
+type secondaryKeyRequest struct {
+	Algorithm  string `json:"algorithm"`
+	KeySpec    string `json:"key_spec"`
+	Scheme     string `json:"scheme"`
+	SaltLength int    `json:"salt_length"`
+	Digest     string `json:"digest"`
+	Encoding   string `json:"encoding"`
 }
 
-func (c *createDeviceRequest) Validate() []error {
+func (c *createDeviceRequest) Validate(algorithms domain.Algorithms) []error {
+	errs := validateSigningParameters(algorithms, c.Algorithm, c.KeySpec, c.Scheme, c.SaltLength, c.Digest, c.Encoding)
+	if c.Secondary != nil {
+		for _, err := range validateSigningParameters(algorithms, c.Secondary.Algorithm, c.Secondary.KeySpec, c.Secondary.Scheme, c.Secondary.SaltLength, c.Secondary.Digest, c.Secondary.Encoding) {
+			if validation, ok := err.(domain.ValidationError); ok {
+				validation.Field = "secondary." + validation.Field
+				err = validation
+			}
+			errs = append(errs, err)
+		}
+	}
+
+	_, err := uuid.Parse(c.ID)
+	if err != nil {
+		errs = append(errs, domain.ErrInvalidDeviceID)
+	}
+	return errs
+}
+
+// validateSigningParameters checks the algorithm of a device key and the
+// parameters requested for it.
+func validateSigningParameters(algorithms domain.Algorithms, algorithmName, keySpec, schemeName string, saltLength int, digest, encoding string) []error {
 	errs := make([]error, 0)
-	_, err := domain.ParseAlgorithm(c.Algorithm)
+	algorithm, err := domain.ParseAlgorithm(algorithms, algorithmName)
+	if err != nil {
+		return append(errs, err)
+	}
+	spec, err := domain.ResolveKeySpec(algorithms, algorithm, domain.ParseKeySpec(keySpec))
 	if err != nil {
 		errs = append(errs, err)
+	} else if _, err := domain.ResolveDigest(algorithms, algorithm, spec, domain.ParseDigest(digest)); err != nil {
+		errs = append(errs, err)
+	}
+	scheme, err := domain.ResolveScheme(algorithms, algorithm, domain.ParseScheme(schemeName))
+	if err != nil {
+		errs = append(errs, err)
+	} else if err := domain.ValidateSaltLength(scheme, saltLength); err != nil {
+		errs = append(errs, err)
+	}
+	if _, err := domain.ResolveEncoding(algorithms, algorithm, domain.ParseEncoding(encoding)); err != nil {
+		errs = append(errs, err)
+	}
+	return errs
+}
+
+// secondaryKey returns the requested secondary key, or nil for single-key devices.
+func (c *secondaryKeyRequest) secondaryKey(algorithms domain.Algorithms) *domain.SecondaryKey {
+	if c == nil {
+		return nil
+	}
+	algorithm, _ := domain.ParseAlgorithm(algorithms, c.Algorithm)
+	return &domain.SecondaryKey{
+		Algorithm:  algorithm,
+		KeySpec:    domain.ParseKeySpec(c.KeySpec),
+		Scheme:     domain.ParseScheme(c.Scheme),
+		SaltLength: c.SaltLength,
+		Digest:     domain.ParseDigest(c.Digest),
+		Encoding:   domain.ParseEncoding(c.Encoding),
+	}
+}
+
+type importDeviceRequest struct {
+	ID         string `json:"id"`
+	Algorithm  string `json:"algorithm"`
+	PrivateKey string `json:"private_key"`
+	Scheme     string `json:"scheme"`
+	SaltLength int    `json:"salt_length"`
+	Digest     string `json:"digest"`
+	Encoding   string `json:"encoding"`
+	Label      string `json:"label"`
+}
+
+func (c *importDeviceRequest) Validate(algorithms domain.Algorithms) []error {
+	errs := make([]error, 0)
+	algorithm, err := domain.ParseAlgorithm(algorithms, c.Algorithm)
+	if err != nil {
+		errs = append(errs, err)
+	} else {
+		scheme, err := domain.ResolveScheme(algorithms, algorithm, domain.ParseScheme(c.Scheme))
+		if err != nil {
+			errs = append(errs, err)
+		} else if err := domain.ValidateSaltLength(scheme, c.SaltLength); err != nil {
+			errs = append(errs, err)
+		}
+		if _, err := domain.ResolveEncoding(algorithms, algorithm, domain.ParseEncoding(c.Encoding)); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	if strings.TrimSpace(c.PrivateKey) == "" {
+		errs = append(errs, domain.ValidationError{Field: "private_key", Message: "private key is required"})
 	}
 
 	_, err = uuid.Parse(c.ID)
@@ -28,10 +132,18 @@ func (c *createDeviceRequest) Validate() []error {
 }
 
 type devicePayload struct {
-	ID        string `json:"id"`
-	Algorithm string `json:"algorithm"`
-	Label     string `json:"label"`
-	Counter   uint64 `json:"counter"`
+	ID         string `json:"id"`
+	Algorithm  string `json:"algorithm"`
+	KeySpec    string `json:"key_spec"`
+	Scheme     string `json:"scheme"`
+	SaltLength int    `json:"salt_length,omitempty"`
+	Digest     string `json:"digest,omitempty"`
+	Encoding   string `json:"encoding,omitempty"`
+	KeyVersion int    `json:"key_version"`
+	Label      string `json:"label"`
+	Counter    uint64 `json:"counter"`
+
+	Secondary *domain.SecondaryKey `json:"secondary,omitempty"`
 }
 
 type updateDeviceRequest struct {
@@ -43,7 +155,9 @@ func (c *updateDeviceRequest) Validate() []error {
 }
 
 type signRequest struct {
-	Data string `json:"data"`
+	Data     string `json:"data"`
+	Encoding string `json:"encoding"`
+	Format   string `json:"format,omitempty"`
 }
 
 func (c *signRequest) Validate() []error {
@@ -55,11 +169,54 @@ func (c *signRequest) Validate() []error {
 type signResponse struct {
 	Signature  string `json:"signature"`
 	SignedData string `json:"signed_data"`
+	Scheme     string `json:"scheme"`
+	Encoding   string `json:"encoding,omitempty"`
+	KeyVersion int    `json:"key_version"`
+	JWS        string `json:"jws,omitempty"`
+	COSE       []byte `json:"cose,omitempty"`            // base64 encoded COSE_Sign1 message
+	Timestamp  []byte `json:"timestamp_token,omitempty"` // base64 encoded RFC 3161 TimeStampToken
+
+	SecondarySignature string `json:"secondary_signature,omitempty"` // signature of the secondary key of hybrid devices
+}
+
+type verifyRequest struct {
+	SignedData string `json:"signed_data"`
+	Signature  string `json:"signature"`
+	KeyVersion int    `json:"key_version,omitempty"`
+	Encoding   string `json:"encoding,omitempty"`
+	Secondary  bool   `json:"secondary,omitempty"` // verify against the secondary key of a hybrid device
+}
+
+func (c *verifyRequest) Validate() []error {
+	errs := make([]error, 0)
+	if c.SignedData == "" {
+		errs = append(errs, domain.ValidationError{Field: "signed_data", Message: "signed data is required"})
+	}
+	if c.Signature == "" {
+		errs = append(errs, domain.ValidationError{Field: "signature", Message: "signature is required"})
+	}
+	if c.KeyVersion < 0 {
+		errs = append(errs, domain.ValidationError{Field: "key_version", Message: "key version must be positive"})
+	}
+	return errs
+}
+
+type verifyResponse struct {
+	Valid      bool `json:"valid"`
+	KeyVersion int  `json:"key_version"`
 }
 
 type signaturePayload struct {
 	Counter    uint64    `json:"counter"`
 	Signature  string    `json:"signature"`
 	SignedData string    `json:"signed_data"`
+	Scheme     string    `json:"scheme"`
+	Encoding   string    `json:"encoding,omitempty"`
+	KeyVersion int       `json:"key_version"`
+	JWS        string    `json:"jws,omitempty"`
+	COSE       []byte    `json:"cose,omitempty"`            // base64 encoded COSE_Sign1 message
+	Timestamp  []byte    `json:"timestamp_token,omitempty"` // base64 encoded RFC 3161 TimeStampToken
 	CreatedAt  time.Time `json:"created_at"`
+
+	SecondarySignature string `json:"secondary_signature,omitempty"` // signature of the secondary key of hybrid devices
 }
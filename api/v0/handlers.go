@@ -2,24 +2,29 @@ package v0
 
 import (
 	"github.com/fiskaly/coding-challenges/signing-service-challenge/api/v0/devices"
+	"github.com/fiskaly/coding-challenges/signing-service-challenge/domain"
 	"github.com/go-chi/chi/v5"
 )
 
 // Handler wires version specific routes.
 type Handler struct {
-	service devices.Service
+	service    devices.Service
+	algorithms domain.Algorithms
 }
 
-// NewHandler creates an API v0 handler with the given service.
-func NewHandler(srv devices.Service) *Handler {
+// NewHandler creates an API v0 handler with the given service and the
+// algorithms it offers.
+func NewHandler(srv devices.Service, algorithms domain.Algorithms) *Handler {
 	return &Handler{
-		service: srv,
+		service:    srv,
+		algorithms: algorithms,
 	}
 }
 
-// Register mounts the versioned device routes and health endpoint.
+// Register mounts the versioned device routes, algorithm listing and health endpoint.
 func (h *Handler) Register(r chi.Router) {
-	handler := devices.New(h.service)
+	handler := devices.New(h.service, h.algorithms)
 	handler.Register(r)
+	r.Get("/algorithms", h.listAlgorithms)
 	r.Get("/health", health)
 }
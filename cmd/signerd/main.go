@@ -0,0 +1,47 @@
+// Command signerd owns all device private keys and signs on behalf of the API
+// server, which connects through the Unix socket named by SIGNER_SOCKET.
+package main
+
+import (
+	"context"
+	"log"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/fiskaly/coding-challenges/signing-service-challenge/internal/app"
+	"github.com/fiskaly/coding-challenges/signing-service-challenge/internal/config"
+	"github.com/fiskaly/coding-challenges/signing-service-challenge/internal/signerd"
+)
+
+func main() {
+	cfg := config.Load()
+	if cfg.SignerSocket == "" {
+		log.Fatal("SIGNER_SOCKET must name the Unix socket to listen on")
+	}
+
+	server, err := app.NewSignerDaemon(cfg)
+	if err != nil {
+		log.Fatalf("could not configure signer daemon: %v", err)
+	}
+
+	listener, err := signerd.Listen(cfg.SignerSocket)
+	if err != nil {
+		log.Fatalf("could not listen on %s: %v", cfg.SignerSocket, err)
+	}
+	defer listener.Close()
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	go func() {
+		<-ctx.Done()
+		listener.Close()
+	}()
+
+	log.Printf("signer daemon listening on %s", cfg.SignerSocket)
+	err = server.Serve(listener)
+	server.Close()
+	if err != nil {
+		log.Fatalf("signer daemon stopped: %v", err)
+	}
+}
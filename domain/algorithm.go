@@ -0,0 +1,382 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// Algorithm enumerates supported signing algorithms.
+type Algorithm string
+
+// Well-known signing algorithm names. An algorithm is only usable when the
+// Algorithms catalog passed to validation describes it.
+const (
+	AlgorithmRSA     Algorithm = "RSA"
+	AlgorithmECDSA   Algorithm = "ECDSA"
+	AlgorithmEd25519 Algorithm = "ED25519"
+	AlgorithmHMAC    Algorithm = "HMAC-SHA256"
+)
+
+// KeySpec identifies the key size or curve of a device key, e.g. "RSA-2048" or "P-256".
+type KeySpec string
+
+// Well-known key specs offered by the built-in providers.
+const (
+	KeySpecRSA2048 KeySpec = "RSA-2048"
+	KeySpecRSA3072 KeySpec = "RSA-3072"
+	KeySpecRSA4096 KeySpec = "RSA-4096"
+	KeySpecP256    KeySpec = "P-256"
+	KeySpecP384    KeySpec = "P-384"
+	KeySpecP521    KeySpec = "P-521"
+	KeySpecEd25519 KeySpec = "ED25519"
+	KeySpecHMAC256 KeySpec = "HMAC-256" // 256-bit secret key.
+)
+
+// SignatureScheme names the signature scheme a device applies, e.g. RSASSA-PSS.
+type SignatureScheme string
+
+// Well-known signature schemes offered by the built-in providers.
+const (
+	SchemeRSAPKCS1v15        SignatureScheme = "RSASSA-PKCS1-V1_5"
+	SchemeRSAPSS             SignatureScheme = "RSASSA-PSS"
+	SchemeECDSA              SignatureScheme = "ECDSA"
+	SchemeECDSADeterministic SignatureScheme = "ECDSA-RFC6979" // ECDSA with nonces derived from key and digest.
+	SchemeEd25519            SignatureScheme = "ED25519"
+	SchemeHMACSHA256         SignatureScheme = "HMAC-SHA256" // Message authentication code; verifiable by the service only.
+)
+
+// SignatureEncoding names the byte layout of a signature value.
+type SignatureEncoding string
+
+// Signature encodings offered by ECDSA. Other algorithms produce a single fixed layout.
+const (
+	EncodingDER   SignatureEncoding = "DER"   // ASN.1 DER SEQUENCE of r and s.
+	EncodingP1363 SignatureEncoding = "P1363" // Fixed-width r||s (IEEE P1363), as used by JWS and COSE.
+)
+
+// Digest names the hash function applied to payloads before signing.
+type Digest string
+
+// Supported digests. SHA3-256 is offered as an alternative to SHA-256 at the same strength.
+const (
+	DigestSHA256   Digest = "SHA-256"
+	DigestSHA384   Digest = "SHA-384"
+	DigestSHA512   Digest = "SHA-512"
+	DigestSHA3_256 Digest = "SHA3-256"
+)
+
+// digestStrengths holds the collision resistance of each digest in bits.
+var digestStrengths = map[Digest]int{
+	DigestSHA256:   128,
+	DigestSHA384:   192,
+	DigestSHA512:   256,
+	DigestSHA3_256: 128,
+}
+
+// DigestStrength returns the security strength of a digest in bits.
+func DigestStrength(digest Digest) (int, bool) {
+	strength, ok := digestStrengths[digest]
+	return strength, ok
+}
+
+// MaxSaltLength caps the RSASSA-PSS salt length (in bytes) so that it fits every offered RSA key spec.
+const MaxSaltLength = 64
+
+// KeySpecDescriptor pairs a key spec with its security strength in bits
+// (as classified by NIST SP 800-57).
+type KeySpecDescriptor struct {
+	Spec     KeySpec
+	Strength int
+}
+
+// AlgorithmDescriptor describes a registered algorithm with the key specs and
+// signature schemes it offers.
+type AlgorithmDescriptor struct {
+	Algorithm      Algorithm
+	KeySpecs       []KeySpecDescriptor
+	DefaultKeySpec KeySpec
+	Schemes        []SignatureScheme
+	DefaultScheme  SignatureScheme
+	// Digests lists the selectable digests, weakest first. Algorithms that
+	// hash internally (Ed25519) offer none.
+	Digests []Digest
+	// Encodings lists the selectable signature encodings, default first.
+	// Algorithms with a single signature layout (RSA, Ed25519) offer none.
+	Encodings []SignatureEncoding
+	// Symmetric marks MAC algorithms whose devices hold a single secret key:
+	// they have no public key and their tags are verified by the service only.
+	Symmetric bool
+}
+
+// Clone returns a deep copy of the descriptor.
+func (d AlgorithmDescriptor) Clone() AlgorithmDescriptor {
+	clone := d
+	clone.KeySpecs = append([]KeySpecDescriptor(nil), d.KeySpecs...)
+	clone.Schemes = append([]SignatureScheme(nil), d.Schemes...)
+	clone.Digests = append([]Digest(nil), d.Digests...)
+	clone.Encodings = append([]SignatureEncoding(nil), d.Encodings...)
+	return clone
+}
+
+func (d AlgorithmDescriptor) hasScheme(scheme SignatureScheme) bool {
+	for _, candidate := range d.Schemes {
+		if candidate == scheme {
+			return true
+		}
+	}
+	return false
+}
+
+func (d AlgorithmDescriptor) hasDigest(digest Digest) bool {
+	for _, candidate := range d.Digests {
+		if candidate == digest {
+			return true
+		}
+	}
+	return false
+}
+
+func (d AlgorithmDescriptor) hasEncoding(encoding SignatureEncoding) bool {
+	for _, candidate := range d.Encodings {
+		if candidate == encoding {
+			return true
+		}
+	}
+	return false
+}
+
+func (d AlgorithmDescriptor) keySpec(spec KeySpec) (KeySpecDescriptor, bool) {
+	for _, candidate := range d.KeySpecs {
+		if candidate.Spec == spec {
+			return candidate, true
+		}
+	}
+	return KeySpecDescriptor{}, false
+}
+
+// Algorithms describes the algorithms a service offers. The validation helpers
+// below take it as a dependency; pkg/crypto.Registry implements it from its
+// registered providers.
+type Algorithms interface {
+	// DescribeAlgorithm returns the descriptor registered for an algorithm.
+	DescribeAlgorithm(algorithm Algorithm) (AlgorithmDescriptor, bool)
+	// SupportedAlgorithms lists the registered algorithms in lexical order.
+	SupportedAlgorithms() []Algorithm
+}
+
+// ValidateDescriptor checks a descriptor before it is registered and returns
+// it with the default key spec and scheme filled in.
+func ValidateDescriptor(descriptor AlgorithmDescriptor) (AlgorithmDescriptor, error) {
+	if descriptor.Algorithm == "" {
+		return AlgorithmDescriptor{}, errors.New("algorithm name is required")
+	}
+	if normalizeAlgorithm(string(descriptor.Algorithm)) != descriptor.Algorithm {
+		return AlgorithmDescriptor{}, errors.New("algorithm name must be upper case without surrounding spaces")
+	}
+	if len(descriptor.KeySpecs) == 0 {
+		return AlgorithmDescriptor{}, errors.New("algorithm must offer at least one key spec")
+	}
+	for _, spec := range descriptor.KeySpecs {
+		if ParseKeySpec(string(spec.Spec)) != spec.Spec || spec.Spec == "" {
+			return AlgorithmDescriptor{}, errors.New("key spec names must be upper case without surrounding spaces")
+		}
+	}
+	if descriptor.DefaultKeySpec == "" {
+		descriptor.DefaultKeySpec = descriptor.KeySpecs[0].Spec
+	}
+	if _, ok := descriptor.keySpec(descriptor.DefaultKeySpec); !ok {
+		return AlgorithmDescriptor{}, errors.New("default key spec must be one of the offered key specs")
+	}
+	if len(descriptor.Schemes) == 0 {
+		return AlgorithmDescriptor{}, errors.New("algorithm must offer at least one signature scheme")
+	}
+	if descriptor.DefaultScheme == "" {
+		descriptor.DefaultScheme = descriptor.Schemes[0]
+	}
+	if !descriptor.hasScheme(descriptor.DefaultScheme) {
+		return AlgorithmDescriptor{}, errors.New("default scheme must be one of the offered schemes")
+	}
+	for _, digest := range descriptor.Digests {
+		if _, ok := digestStrengths[digest]; !ok {
+			return AlgorithmDescriptor{}, fmt.Errorf("unknown digest %s", digest)
+		}
+	}
+	for _, encoding := range descriptor.Encodings {
+		if ParseEncoding(string(encoding)) != encoding || encoding == "" {
+			return AlgorithmDescriptor{}, errors.New("signature encoding names must be upper case without surrounding spaces")
+		}
+	}
+	return descriptor.Clone(), nil
+}
+
+// IsSymmetric reports whether algorithm is a registered MAC algorithm whose
+// devices have no public key.
+func IsSymmetric(algorithms Algorithms, algorithm Algorithm) bool {
+	descriptor, ok := algorithms.DescribeAlgorithm(algorithm)
+	return ok && descriptor.Symmetric
+}
+
+// RequirePublicKey rejects operations that need a device public key, such as
+// key export or certification, for devices of a symmetric algorithm.
+func RequirePublicKey(algorithms Algorithms, algorithm Algorithm) error {
+	if IsSymmetric(algorithms, algorithm) {
+		return NoPublicKeyError(algorithm)
+	}
+	return nil
+}
+
+// NoPublicKeyError reports that devices of a symmetric algorithm have no public key.
+func NoPublicKeyError(algorithm Algorithm) error {
+	return ValidationError{Field: "algorithm", Message: fmt.Sprintf("%s devices have no public key", algorithm)}
+}
+
+// ParseAlgorithm converts an external string into a supported Algorithm.
+func ParseAlgorithm(algorithms Algorithms, value string) (Algorithm, error) {
+	algorithm := normalizeAlgorithm(value)
+	if err := ValidateAlgorithm(algorithms, algorithm); err != nil {
+		return "", err
+	}
+	return algorithm, nil
+}
+
+// ValidateAlgorithm ensures the provided algorithm has been registered.
+func ValidateAlgorithm(algorithms Algorithms, algorithm Algorithm) error {
+	if _, ok := algorithms.DescribeAlgorithm(algorithm); !ok {
+		return ErrInvalidAlgorithm
+	}
+	return nil
+}
+
+// ParseKeySpec normalises an external key spec name. An empty value selects
+// the algorithm default when passed to ResolveKeySpec.
+func ParseKeySpec(value string) KeySpec {
+	return KeySpec(strings.ToUpper(strings.TrimSpace(value)))
+}
+
+// ResolveKeySpec validates spec against the algorithm and returns its descriptor.
+// An empty spec resolves to the algorithm default.
+func ResolveKeySpec(algorithms Algorithms, algorithm Algorithm, spec KeySpec) (KeySpecDescriptor, error) {
+	descriptor, ok := algorithms.DescribeAlgorithm(algorithm)
+	if !ok {
+		return KeySpecDescriptor{}, ErrInvalidAlgorithm
+	}
+	if spec == "" {
+		spec = descriptor.DefaultKeySpec
+	}
+	resolved, ok := descriptor.keySpec(spec)
+	if !ok {
+		return KeySpecDescriptor{}, ErrInvalidKeySpec
+	}
+	return resolved, nil
+}
+
+// ParseScheme normalises an external signature scheme name. An empty value
+// selects the algorithm default when passed to ResolveScheme.
+func ParseScheme(value string) SignatureScheme {
+	return SignatureScheme(strings.ToUpper(strings.TrimSpace(value)))
+}
+
+// ResolveScheme validates scheme against the algorithm. An empty scheme
+// resolves to the algorithm default.
+func ResolveScheme(algorithms Algorithms, algorithm Algorithm, scheme SignatureScheme) (SignatureScheme, error) {
+	descriptor, ok := algorithms.DescribeAlgorithm(algorithm)
+	if !ok {
+		return "", ErrInvalidAlgorithm
+	}
+	if scheme == "" {
+		return descriptor.DefaultScheme, nil
+	}
+	if !descriptor.hasScheme(scheme) {
+		return "", ErrInvalidScheme
+	}
+	return scheme, nil
+}
+
+// ParseDigest normalises an external digest name. An empty value selects the
+// digest matching the key strength when passed to ResolveDigest.
+func ParseDigest(value string) Digest {
+	return Digest(strings.ToUpper(strings.TrimSpace(value)))
+}
+
+// ResolveDigest validates digest against the algorithm and key spec. A digest
+// weaker than the key is rejected; an empty digest resolves to the weakest
+// offered digest that matches the key strength.
+func ResolveDigest(algorithms Algorithms, algorithm Algorithm, spec KeySpecDescriptor, digest Digest) (Digest, error) {
+	descriptor, ok := algorithms.DescribeAlgorithm(algorithm)
+	if !ok {
+		return "", ErrInvalidAlgorithm
+	}
+	if len(descriptor.Digests) == 0 {
+		if digest != "" {
+			return "", ValidationError{Field: "digest", Message: fmt.Sprintf("digest is not configurable for %s", algorithm)}
+		}
+		return "", nil
+	}
+	if digest == "" {
+		for _, candidate := range descriptor.Digests {
+			if digestStrengths[candidate] >= spec.Strength {
+				return candidate, nil
+			}
+		}
+		return descriptor.Digests[len(descriptor.Digests)-1], nil
+	}
+	if !descriptor.hasDigest(digest) {
+		return "", ErrInvalidDigest
+	}
+	if strength := digestStrengths[digest]; strength < spec.Strength {
+		return "", ValidationError{
+			Field:   "digest",
+			Message: fmt.Sprintf("%s provides %d bits of strength, key spec %s needs %d", digest, strength, spec.Spec, spec.Strength),
+		}
+	}
+	return digest, nil
+}
+
+// ParseEncoding normalises an external signature encoding name. An empty
+// value selects the device or algorithm default.
+func ParseEncoding(value string) SignatureEncoding {
+	return SignatureEncoding(strings.ToUpper(strings.TrimSpace(value)))
+}
+
+// ResolveEncoding validates encoding against the algorithm. An empty encoding
+// resolves to the algorithm default, which is empty for algorithms offering no
+// choice of encoding.
+func ResolveEncoding(algorithms Algorithms, algorithm Algorithm, encoding SignatureEncoding) (SignatureEncoding, error) {
+	descriptor, ok := algorithms.DescribeAlgorithm(algorithm)
+	if !ok {
+		return "", ErrInvalidAlgorithm
+	}
+	if len(descriptor.Encodings) == 0 {
+		if encoding != "" {
+			return "", ValidationError{Field: "encoding", Message: fmt.Sprintf("signature encoding is not configurable for %s", algorithm)}
+		}
+		return "", nil
+	}
+	if encoding == "" {
+		return descriptor.Encodings[0], nil
+	}
+	if !descriptor.hasEncoding(encoding) {
+		return "", ErrInvalidEncoding
+	}
+	return encoding, nil
+}
+
+// ValidateSaltLength checks the RSASSA-PSS salt length; other schemes take no salt.
+// A zero length selects the digest size.
+func ValidateSaltLength(scheme SignatureScheme, saltLength int) error {
+	if saltLength == 0 {
+		return nil
+	}
+	if scheme != SchemeRSAPSS {
+		return ValidationError{Field: "salt_length", Message: "salt length is only supported by " + string(SchemeRSAPSS)}
+	}
+	if saltLength < 0 || saltLength > MaxSaltLength {
+		return ValidationError{Field: "salt_length", Message: fmt.Sprintf("salt length must be between 0 and %d bytes", MaxSaltLength)}
+	}
+	return nil
+}
+
+func normalizeAlgorithm(value string) Algorithm {
+	return Algorithm(strings.ToUpper(strings.TrimSpace(value)))
+}
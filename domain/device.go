@@ -3,37 +3,71 @@ package domain
 import (
 	"encoding/base64"
 	"fmt"
-	"strings"
 	"time"
 
 	"github.com/google/uuid"
 )
 
-// Algorithm enumerates supported signing algorithms.
-type Algorithm string
-
-// Supported signing algorithms.
-const (
-	AlgorithmRSA   Algorithm = "RSA"
-	AlgorithmECDSA Algorithm = "ECDSA"
-)
-
 // Device represents a signature device managed by the service.
 type Device struct {
-	ID        uuid.UUID `json:"id"`
-	Algorithm Algorithm `json:"algorithm"`
-	Label     string    `json:"label"`
-	CreatedAt time.Time `json:"created_at"`
-	UpdatedAt time.Time `json:"updated_at"`
+	ID         uuid.UUID         `json:"id"`
+	Algorithm  Algorithm         `json:"algorithm"`
+	KeySpec    KeySpec           `json:"key_spec"`
+	Scheme     SignatureScheme   `json:"scheme"`
+	SaltLength int               `json:"salt_length,omitempty"` // RSASSA-PSS salt in bytes; zero means digest size.
+	Digest     Digest            `json:"digest,omitempty"`      // Empty for algorithms that hash internally.
+	Encoding   SignatureEncoding `json:"encoding,omitempty"`    // Default signature encoding; empty when the algorithm offers no choice.
+	KeyVersion int               `json:"key_version"`           // Version of the key currently used for signing.
+	Secondary  *SecondaryKey     `json:"secondary,omitempty"`   // Second key of hybrid devices; nil for single-key devices.
+	Label      string            `json:"label"`
+	CreatedAt  time.Time         `json:"created_at"`
+	UpdatedAt  time.Time         `json:"updated_at"`
 }
 
+// SecondaryKey holds the signing parameters of the second key pair of a hybrid
+// device. It signs the same secured payloads as the primary key and shares its
+// key version, so a device can move to another algorithm without breaking the chain.
+type SecondaryKey struct {
+	Algorithm  Algorithm         `json:"algorithm"`
+	KeySpec    KeySpec           `json:"key_spec"`
+	Scheme     SignatureScheme   `json:"scheme"`
+	SaltLength int               `json:"salt_length,omitempty"`
+	Digest     Digest            `json:"digest,omitempty"`
+	Encoding   SignatureEncoding `json:"encoding,omitempty"`
+}
+
+// InitialKeyVersion is the key version assigned to newly created devices.
+const InitialKeyVersion = 1
+
 // Clone provides a deep copy to avoid leaking internal state.
-// Clone returns a shallow copy of the device to avoid exposing internal references.
 func (d Device) Clone() Device {
 	clone := d
+	if d.Secondary != nil {
+		secondary := *d.Secondary
+		clone.Secondary = &secondary
+	}
 	return clone
 }
 
+// SecondaryDevice returns the device as seen by its secondary key: the same
+// ID and key version with the secondary signing parameters, so signers and
+// verifiers can be resolved for it like for any device. It reports false for
+// single-key devices.
+func (d Device) SecondaryDevice() (Device, bool) {
+	if d.Secondary == nil {
+		return Device{}, false
+	}
+	view := d.Clone()
+	view.Algorithm = d.Secondary.Algorithm
+	view.KeySpec = d.Secondary.KeySpec
+	view.Scheme = d.Secondary.Scheme
+	view.SaltLength = d.Secondary.SaltLength
+	view.Digest = d.Secondary.Digest
+	view.Encoding = d.Secondary.Encoding
+	view.Secondary = nil
+	return view, true
+}
+
 // WithLabel returns a new copy of the device with an updated label and timestamp.
 func (d Device) WithLabel(label string, at time.Time) Device {
 	clone := d.Clone()
@@ -42,10 +76,32 @@ func (d Device) WithLabel(label string, at time.Time) Device {
 	return clone
 }
 
+// WithKeyVersion returns a new copy of the device signing with the given key version.
+func (d Device) WithKeyVersion(version int, at time.Time) Device {
+	clone := d.Clone()
+	clone.KeyVersion = version
+	clone.UpdatedAt = at
+	return clone
+}
+
 // KeyMaterial holds the serialized public and private keys for a device.
 type KeyMaterial struct {
-	Public  []byte // PEM encoded public key material.
-	Private []byte // PEM encoded private key material.
+	Public    []byte       // PEM encoded public key material.
+	Private   []byte       // PEM encoded private key material.
+	Secondary *KeyMaterial // Key pair of the secondary key of hybrid devices; nil otherwise.
+}
+
+// Clone returns a deep copy of the key material.
+func (m KeyMaterial) Clone() KeyMaterial {
+	clone := KeyMaterial{
+		Public:  append([]byte(nil), m.Public...),
+		Private: append([]byte(nil), m.Private...),
+	}
+	if m.Secondary != nil {
+		secondary := m.Secondary.Clone()
+		clone.Secondary = &secondary
+	}
+	return clone
 }
 
 // BuildSecuredPayload composes the string to be signed following domain rules.
@@ -53,23 +109,3 @@ func BuildSecuredPayload(counter uint64, data string, reference []byte) string {
 	encoded := base64.StdEncoding.EncodeToString(reference)
 	return fmt.Sprintf("%d_%s_%s", counter, data, encoded)
 }
-
-// ParseAlgorithm converts an external string into a supported Algorithm.
-func ParseAlgorithm(value string) (Algorithm, error) {
-	normalized := strings.ToUpper(strings.TrimSpace(value))
-	algorithm := Algorithm(normalized)
-	if err := ValidateAlgorithm(algorithm); err != nil {
-		return "", err
-	}
-	return algorithm, nil
-}
-
-// ValidateAlgorithm ensures the provided algorithm is supported.
-func ValidateAlgorithm(algorithm Algorithm) error {
-	switch algorithm {
-	case AlgorithmRSA, AlgorithmECDSA:
-		return nil
-	default:
-		return ErrInvalidAlgorithm
-	}
-}
@@ -5,6 +5,7 @@ import (
 	"time"
 
 	"github.com/fiskaly/coding-challenges/signing-service-challenge/domain"
+	"github.com/fiskaly/coding-challenges/signing-service-challenge/pkg/crypto"
 	"github.com/google/uuid"
 )
 
@@ -18,7 +19,7 @@ func TestBuildSecuredPayload(t *testing.T) {
 }
 
 func TestParseAlgorithm_Success(t *testing.T) {
-	algorithm, err := domain.ParseAlgorithm("  rsa ")
+	algorithm, err := domain.ParseAlgorithm(crypto.DefaultRegistry(), "  rsa ")
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
@@ -27,8 +28,18 @@ func TestParseAlgorithm_Success(t *testing.T) {
 	}
 }
 
+func TestParseAlgorithm_Ed25519(t *testing.T) {
+	algorithm, err := domain.ParseAlgorithm(crypto.DefaultRegistry(), "ed25519")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if algorithm != domain.AlgorithmEd25519 {
+		t.Fatalf("expected ED25519, got %s", algorithm)
+	}
+}
+
 func TestParseAlgorithm_Invalid(t *testing.T) {
-	_, err := domain.ParseAlgorithm("sha256")
+	_, err := domain.ParseAlgorithm(crypto.DefaultRegistry(), "sha256")
 	if err == nil {
 		t.Fatal("expected error for unsupported algorithm")
 	}
@@ -38,15 +49,183 @@ func TestParseAlgorithm_Invalid(t *testing.T) {
 }
 
 func TestValidateAlgorithm(t *testing.T) {
-	if err := domain.ValidateAlgorithm(domain.AlgorithmECDSA); err != nil {
+	if err := domain.ValidateAlgorithm(crypto.DefaultRegistry(), domain.AlgorithmECDSA); err != nil {
 		t.Fatalf("unexpected error for ECDSA: %v", err)
 	}
-	err := domain.ValidateAlgorithm(domain.Algorithm("HMAC"))
+	err := domain.ValidateAlgorithm(crypto.DefaultRegistry(), domain.Algorithm("HMAC"))
 	if err != domain.ErrInvalidAlgorithm {
 		t.Fatalf("expected ErrInvalidAlgorithm, got %v", err)
 	}
 }
 
+// catalog is a minimal domain.Algorithms backed by a map.
+type catalog map[domain.Algorithm]domain.AlgorithmDescriptor
+
+func (c catalog) DescribeAlgorithm(algorithm domain.Algorithm) (domain.AlgorithmDescriptor, bool) {
+	descriptor, ok := c[algorithm]
+	return descriptor, ok
+}
+
+func (c catalog) SupportedAlgorithms() []domain.Algorithm {
+	algorithms := make([]domain.Algorithm, 0, len(c))
+	for algorithm := range c {
+		algorithms = append(algorithms, algorithm)
+	}
+	return algorithms
+}
+
+func TestValidateDescriptor(t *testing.T) {
+	custom := domain.Algorithm("TEST-ALGORITHM")
+	algorithms := catalog{}
+	if err := domain.ValidateAlgorithm(algorithms, custom); err != domain.ErrInvalidAlgorithm {
+		t.Fatalf("expected unknown algorithm to be rejected, got %v", err)
+	}
+	descriptor, err := domain.ValidateDescriptor(domain.AlgorithmDescriptor{
+		Algorithm: custom,
+		KeySpecs:  []domain.KeySpecDescriptor{{Spec: "TEST-256", Strength: 128}},
+		Schemes:   []domain.SignatureScheme{"TEST-SCHEME"},
+	})
+	if err != nil {
+		t.Fatalf("validate failed: %v", err)
+	}
+	algorithms[custom] = descriptor
+	parsed, err := domain.ParseAlgorithm(algorithms, " test-algorithm ")
+	if err != nil || parsed != custom {
+		t.Fatalf("expected %s, got %s (%v)", custom, parsed, err)
+	}
+
+	spec, err := domain.ResolveKeySpec(algorithms, custom, "")
+	if err != nil || spec.Spec != "TEST-256" {
+		t.Fatalf("expected first key spec as default, got %v (%v)", spec, err)
+	}
+
+	if _, err := domain.ValidateDescriptor(domain.AlgorithmDescriptor{Algorithm: "lower", KeySpecs: descriptor.KeySpecs}); err == nil {
+		t.Fatal("expected non-canonical name to be rejected")
+	}
+	if _, err := domain.ValidateDescriptor(domain.AlgorithmDescriptor{Algorithm: "NO-SPECS"}); err == nil {
+		t.Fatal("expected descriptor without key specs to be rejected")
+	}
+}
+
+func TestResolveKeySpec(t *testing.T) {
+	spec, err := domain.ResolveKeySpec(crypto.DefaultRegistry(), domain.AlgorithmRSA, "")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if spec.Spec != domain.KeySpecRSA2048 || spec.Strength != 112 {
+		t.Fatalf("unexpected default RSA key spec: %+v", spec)
+	}
+
+	spec, err = domain.ResolveKeySpec(crypto.DefaultRegistry(), domain.AlgorithmECDSA, domain.ParseKeySpec(" p-521 "))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if spec.Spec != domain.KeySpecP521 {
+		t.Fatalf("expected P-521, got %s", spec.Spec)
+	}
+
+	if _, err := domain.ResolveKeySpec(crypto.DefaultRegistry(), domain.AlgorithmECDSA, domain.KeySpecRSA4096); err != domain.ErrInvalidKeySpec {
+		t.Fatalf("expected ErrInvalidKeySpec, got %v", err)
+	}
+	if _, err := domain.ResolveKeySpec(crypto.DefaultRegistry(), domain.Algorithm("UNKNOWN"), ""); err != domain.ErrInvalidAlgorithm {
+		t.Fatalf("expected ErrInvalidAlgorithm, got %v", err)
+	}
+}
+
+func TestResolveScheme(t *testing.T) {
+	scheme, err := domain.ResolveScheme(crypto.DefaultRegistry(), domain.AlgorithmRSA, "")
+	if err != nil || scheme != domain.SchemeRSAPKCS1v15 {
+		t.Fatalf("expected PKCS#1 v1.5 default, got %s (%v)", scheme, err)
+	}
+	scheme, err = domain.ResolveScheme(crypto.DefaultRegistry(), domain.AlgorithmRSA, domain.ParseScheme("rsassa-pss"))
+	if err != nil || scheme != domain.SchemeRSAPSS {
+		t.Fatalf("expected PSS, got %s (%v)", scheme, err)
+	}
+	if _, err := domain.ResolveScheme(crypto.DefaultRegistry(), domain.AlgorithmECDSA, domain.SchemeRSAPSS); err != domain.ErrInvalidScheme {
+		t.Fatalf("expected ErrInvalidScheme, got %v", err)
+	}
+}
+
+func TestResolveDigest(t *testing.T) {
+	p384, err := domain.ResolveKeySpec(crypto.DefaultRegistry(), domain.AlgorithmECDSA, domain.KeySpecP384)
+	if err != nil {
+		t.Fatalf("resolve key spec: %v", err)
+	}
+	digest, err := domain.ResolveDigest(crypto.DefaultRegistry(), domain.AlgorithmECDSA, p384, "")
+	if err != nil || digest != domain.DigestSHA384 {
+		t.Fatalf("expected SHA-384 default for P-384, got %s (%v)", digest, err)
+	}
+	if _, err := domain.ResolveDigest(crypto.DefaultRegistry(), domain.AlgorithmECDSA, p384, domain.DigestSHA256); err == nil {
+		t.Fatal("expected SHA-256 to be rejected for P-384")
+	}
+	digest, err = domain.ResolveDigest(crypto.DefaultRegistry(), domain.AlgorithmECDSA, p384, domain.ParseDigest("sha-512"))
+	if err != nil || digest != domain.DigestSHA512 {
+		t.Fatalf("expected SHA-512, got %s (%v)", digest, err)
+	}
+
+	rsa2048, _ := domain.ResolveKeySpec(crypto.DefaultRegistry(), domain.AlgorithmRSA, domain.KeySpecRSA2048)
+	digest, err = domain.ResolveDigest(crypto.DefaultRegistry(), domain.AlgorithmRSA, rsa2048, "")
+	if err != nil || digest != domain.DigestSHA256 {
+		t.Fatalf("expected SHA-256 default for RSA-2048, got %s (%v)", digest, err)
+	}
+	if _, err := domain.ResolveDigest(crypto.DefaultRegistry(), domain.AlgorithmRSA, rsa2048, "MD5"); err != domain.ErrInvalidDigest {
+		t.Fatalf("expected ErrInvalidDigest, got %v", err)
+	}
+
+	ed25519, _ := domain.ResolveKeySpec(crypto.DefaultRegistry(), domain.AlgorithmEd25519, "")
+	if digest, err := domain.ResolveDigest(crypto.DefaultRegistry(), domain.AlgorithmEd25519, ed25519, ""); err != nil || digest != "" {
+		t.Fatalf("expected no digest for Ed25519, got %s (%v)", digest, err)
+	}
+	if _, err := domain.ResolveDigest(crypto.DefaultRegistry(), domain.AlgorithmEd25519, ed25519, domain.DigestSHA512); err == nil {
+		t.Fatal("expected digest to be rejected for Ed25519")
+	}
+}
+
+func TestResolveEncoding(t *testing.T) {
+	encoding, err := domain.ResolveEncoding(crypto.DefaultRegistry(), domain.AlgorithmECDSA, "")
+	if err != nil || encoding != domain.EncodingDER {
+		t.Fatalf("expected DER default for ECDSA, got %s (%v)", encoding, err)
+	}
+	encoding, err = domain.ResolveEncoding(crypto.DefaultRegistry(), domain.AlgorithmECDSA, domain.ParseEncoding(" p1363 "))
+	if err != nil || encoding != domain.EncodingP1363 {
+		t.Fatalf("expected P1363, got %s (%v)", encoding, err)
+	}
+	if _, err := domain.ResolveEncoding(crypto.DefaultRegistry(), domain.AlgorithmECDSA, "PEM"); err != domain.ErrInvalidEncoding {
+		t.Fatalf("expected ErrInvalidEncoding, got %v", err)
+	}
+
+	if encoding, err := domain.ResolveEncoding(crypto.DefaultRegistry(), domain.AlgorithmRSA, ""); err != nil || encoding != "" {
+		t.Fatalf("expected no encoding for RSA, got %s (%v)", encoding, err)
+	}
+	if _, err := domain.ResolveEncoding(crypto.DefaultRegistry(), domain.AlgorithmEd25519, domain.EncodingP1363); err == nil {
+		t.Fatal("expected encoding to be rejected for Ed25519")
+	}
+}
+
+func TestValidateFormat(t *testing.T) {
+	if err := domain.ValidateFormat(domain.ParseFormat(" jws ")); err != nil {
+		t.Fatalf("expected jws to be accepted, got %v", err)
+	}
+	if err := domain.ValidateFormat("XML"); err != domain.ErrInvalidFormat {
+		t.Fatalf("expected ErrInvalidFormat, got %v", err)
+	}
+}
+
+func TestValidateSaltLength(t *testing.T) {
+	if err := domain.ValidateSaltLength(domain.SchemeRSAPSS, 32); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := domain.ValidateSaltLength(domain.SchemeRSAPKCS1v15, 0); err != nil {
+		t.Fatalf("unexpected error for default salt: %v", err)
+	}
+	if err := domain.ValidateSaltLength(domain.SchemeRSAPKCS1v15, 16); err == nil {
+		t.Fatal("expected salt length to be rejected for PKCS#1 v1.5")
+	}
+	if err := domain.ValidateSaltLength(domain.SchemeRSAPSS, domain.MaxSaltLength+1); err == nil {
+		t.Fatal("expected oversized salt length to be rejected")
+	}
+}
+
 func TestDeviceClone(t *testing.T) {
 	now := time.Now().UTC()
 	device := domain.Device{
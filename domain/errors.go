@@ -62,7 +62,13 @@ func (e InternalError) Error() string {
 // Predefined domain errors reused across services.
 var (
 	ErrInvalidAlgorithm   = ValidationError{Field: "algorithm", Message: "unsupported algorithm"}
+	ErrInvalidKeySpec     = ValidationError{Field: "key_spec", Message: "unsupported key spec for algorithm"}
+	ErrInvalidScheme      = ValidationError{Field: "scheme", Message: "unsupported signature scheme for algorithm"}
+	ErrInvalidDigest      = ValidationError{Field: "digest", Message: "unsupported digest for algorithm"}
+	ErrInvalidEncoding    = ValidationError{Field: "encoding", Message: "unsupported signature encoding for algorithm"}
+	ErrInvalidFormat      = ValidationError{Field: "format", Message: "unsupported signature format"}
 	ErrInvalidDeviceID    = ValidationError{Field: "id", Message: "device ID must be a valid UUID"}
 	ErrDeviceExists       = ConflictError{Reason: "device already exists"}
+	ErrKeyInUse           = ConflictError{Reason: "key already belongs to another device"}
 	ErrKeyMaterialMissing = InternalError{Reason: "key material missing"}
 )
@@ -0,0 +1,29 @@
+package domain
+
+import "strings"
+
+// SignatureFormat names an additional serialisation of a signature, produced
+// and stored next to the plain signature over the secured payload.
+type SignatureFormat string
+
+// Supported signature formats.
+const (
+	FormatJWS  SignatureFormat = "JWS"  // RFC 7515 compact serialization.
+	FormatCOSE SignatureFormat = "COSE" // RFC 9052 COSE_Sign1 message.
+)
+
+// ParseFormat normalises an external signature format name. An empty value
+// requests the plain signature only.
+func ParseFormat(value string) SignatureFormat {
+	return SignatureFormat(strings.ToUpper(strings.TrimSpace(value)))
+}
+
+// ValidateFormat rejects unknown signature formats; an empty format is valid.
+func ValidateFormat(format SignatureFormat) error {
+	switch format {
+	case "", FormatJWS, FormatCOSE:
+		return nil
+	default:
+		return ErrInvalidFormat
+	}
+}
@@ -1,6 +1,8 @@
 module github.com/fiskaly/coding-challenges/signing-service-challenge
 
-go 1.20
+// Go 1.24 provides crypto/sha3 (SHA3-256 digests) and RFC 6979 ECDSA signing
+// with a nil random source.
+go 1.24
 
 require github.com/google/uuid v1.6.0
 
@@ -0,0 +1,38 @@
+package app
+
+import (
+	"net/http"
+
+	"github.com/fiskaly/coding-challenges/signing-service-challenge/api/v0/utils"
+	"github.com/fiskaly/coding-challenges/signing-service-challenge/pkg/crypto"
+	"github.com/go-chi/chi/v5"
+)
+
+// newAdminHandler serves the operational endpoints of the admin listener.
+// Only the key pool counters are published, read through keyPoolStats from
+// the local pool or from the signer daemon.
+func newAdminHandler(keyPoolStats func() ([]crypto.KeyPoolStats, error)) http.Handler {
+	r := chi.NewRouter()
+	r.Get("/metrics/key-pool", func(w http.ResponseWriter, _ *http.Request) {
+		stats, err := keyPoolStats()
+		if err != nil {
+			utils.WriteInternalError(w)
+			return
+		}
+		if stats == nil {
+			stats = []crypto.KeyPoolStats{}
+		}
+		utils.WriteAPIResponse(w, http.StatusOK, stats)
+	})
+	return r
+}
+
+// localKeyPoolStats reports the counters of an in-process pool; nil has none.
+func localKeyPoolStats(pool *crypto.KeyPool) func() ([]crypto.KeyPoolStats, error) {
+	return func() ([]crypto.KeyPoolStats, error) {
+		if pool == nil {
+			return nil, nil
+		}
+		return pool.Stats(), nil
+	}
+}
@@ -1,31 +1,91 @@
 package app
 
 import (
+	"fmt"
 	"log"
 
 	"github.com/fiskaly/coding-challenges/signing-service-challenge/api"
 	v0 "github.com/fiskaly/coding-challenges/signing-service-challenge/api/v0"
+	"github.com/fiskaly/coding-challenges/signing-service-challenge/internal/config"
 	"github.com/fiskaly/coding-challenges/signing-service-challenge/internal/devices"
 	"github.com/fiskaly/coding-challenges/signing-service-challenge/internal/persistence/inmemory"
+	"github.com/fiskaly/coding-challenges/signing-service-challenge/internal/signerd"
 	"github.com/fiskaly/coding-challenges/signing-service-challenge/pkg/crypto"
 )
 
 // NewServer wires together application dependencies and returns a configured HTTP server.
-func NewServer(listenAddress string) *api.Server {
+func NewServer(cfg config.Config) (*api.Server, error) {
+	registry := crypto.DefaultRegistry()
 	repository := inmemory.NewDeviceRepository()
-	keyStore := inmemory.NewKeyStore()
-	keyGenerator := crypto.NewDefaultKeyGenerator()
-	signerFactory := crypto.NewSignerFactory()
+	keyStore, err := newKeyStore(cfg, inmemory.NewKeyStore(), repository)
+	if err != nil {
+		return nil, fmt.Errorf("configure key store: %w", err)
+	}
+	var (
+		keyGenerator  devices.KeyGenerator
+		signerFactory devices.SignerFactory = crypto.NewSignerFactory()
+		keyImporter   devices.KeyImporter   = crypto.NewKeyImporter()
+		keyReleaser   devices.KeyReleaser
+		issuer        devices.CertificateIssuer
+		timestamper   devices.Timestamper
+		keyPoolStats  func() ([]crypto.KeyPoolStats, error)
+		onShutdown    func()
+	)
+	if cfg.SignerSocket == "" {
+		generator, pool, err := newKeyGenerator(cfg)
+		if err != nil {
+			return nil, fmt.Errorf("configure key pool: %w", err)
+		}
+		keyGenerator, keyPoolStats = generator, localKeyPoolStats(pool)
+		if pool != nil {
+			onShutdown = pool.Close
+		}
+		if issuer, timestamper, err = newAuthorities(cfg); err != nil {
+			if pool != nil {
+				pool.Close()
+			}
+			return nil, err
+		}
+	} else {
+		// The daemon also holds the CA and TSA keys; they are never loaded here.
+		client, err := signerd.Dial(cfg.SignerSocket, registry, crypto.NewSignerFactory())
+		if err != nil {
+			return nil, err
+		}
+		keyGenerator, signerFactory, keyImporter, keyReleaser = client, client, client, client
+		keyStore = client.KeyStore(keyStore)
+		keyPoolStats, onShutdown = client.KeyPoolStats, func() { client.Close() }
+		if issuer, err = client.CertificateAuthority(); err != nil {
+			client.Close()
+			return nil, fmt.Errorf("configure certificate authority: %w", err)
+		}
+		if cfg.TSAEnabled {
+			timestamper = client.Timestamper()
+		}
+	}
 	signatureStore := inmemory.NewSignatureStore()
 
-	coreService := devices.NewService(repository, keyStore, keyGenerator, signerFactory, signatureStore)
+	coreService := devices.NewService(registry, repository, keyStore, keyGenerator, signerFactory, signatureStore)
+	coreService.WithMinKeyStrength(cfg.MinKeyStrength)
+	coreService.WithSignerCacheSize(cfg.SignerCacheSize)
+	coreService.WithPublicKeyExporter(crypto.NewKeyExporter())
+	coreService.WithKeyImporter(keyImporter)
+	coreService.WithKeyReleaser(keyReleaser)
+	certificates := inmemory.NewCertificateStore()
+	coreService.WithCertificateAuthority(issuer, certificates)
+	coreService.WithCertificateRequester(crypto.NewCertificateRequester(registry), certificates)
+	coreService.WithSignedDataEncoder(crypto.NewCMSEncoder(registry))
+	coreService.WithTimestamper(timestamper)
 	loggingService := devices.NewLoggingService(coreService, func(event string, fields map[string]interface{}) {
 		log.Printf("event=%s fields=%v", event, fields)
 	})
 
-	apiV0Handler := v0.NewHandler(loggingService)
+	apiV0Handler := v0.NewHandler(loggingService, registry)
 
-	return api.NewServer(listenAddress, map[string]api.DeviceHandler{
+	server := api.NewServer(cfg.ListenAddress, map[string]api.DeviceHandler{
 		"/api/v0": apiV0Handler,
 	})
+	server.WithAdmin(cfg.AdminAddress, newAdminHandler(keyPoolStats))
+	server.OnShutdown(onShutdown)
+	return server, nil
 }
@@ -0,0 +1,90 @@
+package app
+
+import (
+	"encoding/asn1"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/fiskaly/coding-challenges/signing-service-challenge/internal/config"
+	"github.com/fiskaly/coding-challenges/signing-service-challenge/internal/devices"
+	"github.com/fiskaly/coding-challenges/signing-service-challenge/pkg/crypto"
+)
+
+// newAuthorities sets up the certificate authority and, when enabled, the
+// time-stamping authority of the process that holds their keys: the API
+// process, or the signer daemon when one is used. The timestamper is nil when
+// time-stamping is disabled.
+func newAuthorities(cfg config.Config) (devices.CertificateIssuer, devices.Timestamper, error) {
+	authority, err := newCertificateAuthority(cfg)
+	if err != nil {
+		return nil, nil, fmt.Errorf("configure certificate authority: %w", err)
+	}
+	timestamps, err := newTimestampAuthority(cfg, authority)
+	if err != nil {
+		return nil, nil, fmt.Errorf("configure time-stamping authority: %w", err)
+	}
+	if timestamps == nil {
+		return authority, nil, nil
+	}
+	return authority, timestamps, nil
+}
+
+// newCertificateAuthority sets up the service root CA from the configured key
+// file, or with a key generated for the lifetime of the process.
+func newCertificateAuthority(cfg config.Config) (*crypto.CertificateAuthority, error) {
+	var key []byte
+	if cfg.CAKeyFile != "" {
+		var err error
+		if key, err = os.ReadFile(cfg.CAKeyFile); err != nil {
+			return nil, fmt.Errorf("read CA key: %w", err)
+		}
+	}
+	return crypto.NewCertificateAuthority(crypto.CertificateAuthorityConfig{
+		CommonName:     cfg.CACommonName,
+		Key:            key,
+		Validity:       cfg.CAValidity,
+		DeviceValidity: cfg.DeviceCertValidity,
+	})
+}
+
+// newTimestampAuthority sets up the built-in TSA under authority, or returns
+// nil when time-stamping is disabled.
+func newTimestampAuthority(cfg config.Config, authority *crypto.CertificateAuthority) (*crypto.TimestampAuthority, error) {
+	if !cfg.TSAEnabled {
+		return nil, nil
+	}
+	var key []byte
+	if cfg.TSAKeyFile != "" {
+		var err error
+		if key, err = os.ReadFile(cfg.TSAKeyFile); err != nil {
+			return nil, fmt.Errorf("read TSA key: %w", err)
+		}
+	}
+	policy, err := parseOID(cfg.TSAPolicy)
+	if err != nil {
+		return nil, fmt.Errorf("parse TSA policy: %w", err)
+	}
+	return crypto.NewTimestampAuthority(authority, crypto.TimestampAuthorityConfig{Key: key, Policy: policy})
+}
+
+// parseOID parses a dotted object identifier; empty yields nil.
+func parseOID(dotted string) (asn1.ObjectIdentifier, error) {
+	if dotted == "" {
+		return nil, nil
+	}
+	parts := strings.Split(dotted, ".")
+	if len(parts) < 2 {
+		return nil, fmt.Errorf("%q is not a dotted object identifier", dotted)
+	}
+	oid := make(asn1.ObjectIdentifier, len(parts))
+	for i, part := range parts {
+		component, err := strconv.Atoi(part)
+		if err != nil || component < 0 {
+			return nil, fmt.Errorf("%q is not a dotted object identifier", dotted)
+		}
+		oid[i] = component
+	}
+	return oid, nil
+}
@@ -0,0 +1,44 @@
+package app
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/fiskaly/coding-challenges/signing-service-challenge/domain"
+	"github.com/fiskaly/coding-challenges/signing-service-challenge/internal/config"
+	"github.com/fiskaly/coding-challenges/signing-service-challenge/pkg/crypto"
+)
+
+// newKeyGenerator returns the default key generator, backed by a background
+// key pool when KEY_POOL_HIGH is set. The pool is nil otherwise; the caller
+// reports its stats and closes it on shutdown.
+func newKeyGenerator(cfg config.Config) (*crypto.DefaultKeyGenerator, *crypto.KeyPool, error) {
+	generator := crypto.NewDefaultKeyGenerator()
+	if cfg.KeyPoolHigh == 0 {
+		return generator, nil, nil
+	}
+
+	registry := crypto.DefaultRegistry()
+	pool, err := crypto.NewKeyPool(registry, crypto.KeyPoolConfig{
+		High:    cfg.KeyPoolHigh,
+		Low:     cfg.KeyPoolLow,
+		Workers: cfg.KeyPoolWorkers,
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	for _, entry := range strings.FieldsFunc(cfg.KeyPoolWarm, func(r rune) bool { return r == ',' || r == ' ' }) {
+		algorithm, spec, _ := strings.Cut(entry, ":")
+		parsed, err := domain.ParseAlgorithm(registry, algorithm)
+		if err != nil {
+			pool.Close()
+			return nil, nil, fmt.Errorf("warm key pool %q: %w", entry, err)
+		}
+		if err := pool.Warm(parsed, domain.ParseKeySpec(spec)); err != nil {
+			pool.Close()
+			return nil, nil, fmt.Errorf("warm key pool %q: %w", entry, err)
+		}
+	}
+	generator.WithKeyPool(pool)
+	return generator, pool, nil
+}
@@ -0,0 +1,105 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"log"
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+
+	"github.com/fiskaly/coding-challenges/signing-service-challenge/internal/config"
+	"github.com/fiskaly/coding-challenges/signing-service-challenge/internal/devices"
+	"github.com/fiskaly/coding-challenges/signing-service-challenge/internal/persistence/envelope"
+	"github.com/google/uuid"
+)
+
+// loadKeyring returns the configured KEKs or nil when encryption at rest is disabled.
+func loadKeyring(cfg config.Config) (*envelope.Keyring, error) {
+	switch {
+	case cfg.KeyEncryptionKeys != "" && cfg.KeyEncryptionKeysFile != "":
+		return nil, errors.New("configure either KEY_ENCRYPTION_KEYS or KEY_ENCRYPTION_KEYS_FILE, not both")
+	case cfg.KeyEncryptionKeysFile != "":
+		return envelope.LoadKeyringFile(cfg.KeyEncryptionKeysFile)
+	case cfg.KeyEncryptionKeys != "":
+		return envelope.ParseKeyring(cfg.KeyEncryptionKeys)
+	default:
+		return nil, nil
+	}
+}
+
+// newKeyStore wraps inner with envelope encryption when KEKs are configured.
+func newKeyStore(cfg config.Config, inner devices.KeyStore, repository devices.Repository) (devices.KeyStore, error) {
+	keyring, err := loadKeyring(cfg)
+	if err != nil || keyring == nil {
+		return inner, err
+	}
+
+	store := envelope.NewKeyStore(inner, keyring)
+	rewrapper := &keyRewrapper{keyring: keyring, store: store, repository: repository}
+	go rewrapper.rewrap()
+	if cfg.KeyEncryptionKeysFile != "" {
+		go rewrapper.reloadOnHangup(cfg.KeyEncryptionKeysFile)
+	}
+	return store, nil
+}
+
+// keyRewrapper re-wraps stored data keys under the primary KEK. Reloads and
+// rewraps run one at a time, so the primary cannot change under a running
+// rewrap and KEKs are only pruned once nothing references them.
+type keyRewrapper struct {
+	mu         sync.Mutex
+	keyring    *envelope.Keyring
+	store      *envelope.KeyStore
+	repository devices.Repository
+}
+
+// reloadOnHangup re-reads the KEK file on SIGHUP and re-wraps stored data keys
+// under the new primary KEK while requests keep being served. KEKs dropped
+// from the file keep unwrapping until that rewrap has succeeded.
+func (r *keyRewrapper) reloadOnHangup(path string) {
+	hangup := make(chan os.Signal, 1)
+	signal.Notify(hangup, syscall.SIGHUP)
+	for range hangup {
+		next, err := envelope.LoadKeyringFile(path)
+		if err != nil {
+			log.Printf("event=keyring.reload.error error=%v", err)
+			continue
+		}
+		r.mu.Lock()
+		r.keyring.Replace(next)
+		r.rewrapLocked()
+		r.mu.Unlock()
+	}
+}
+
+func (r *keyRewrapper) rewrap() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.rewrapLocked()
+}
+
+// rewrapLocked moves every stored data key to the primary KEK and, once that
+// succeeded, forgets the KEKs retired by earlier reloads.
+func (r *keyRewrapper) rewrapLocked() {
+	ctx := context.Background()
+	list, err := r.repository.List(ctx)
+	if err != nil {
+		log.Printf("event=keyring.rewrap.error error=%v", err)
+		return
+	}
+	ids := make([]uuid.UUID, 0, len(list))
+	for _, device := range list {
+		ids = append(ids, device.ID)
+	}
+	updated, err := r.store.Rewrap(ctx, ids)
+	if err != nil {
+		log.Printf("event=keyring.rewrap.error updated=%d error=%v", updated, err)
+		return
+	}
+	// Every stored key is sealed now; devices created meanwhile were sealed on Store.
+	r.store.EnableStrict()
+	r.keyring.Prune()
+	log.Printf("event=keyring.rewrap updated=%d", updated)
+}
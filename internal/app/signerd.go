@@ -0,0 +1,50 @@
+package app
+
+import (
+	"fmt"
+
+	"github.com/fiskaly/coding-challenges/signing-service-challenge/internal/config"
+	"github.com/fiskaly/coding-challenges/signing-service-challenge/internal/devices"
+	"github.com/fiskaly/coding-challenges/signing-service-challenge/internal/persistence/envelope"
+	"github.com/fiskaly/coding-challenges/signing-service-challenge/internal/persistence/inmemory"
+	"github.com/fiskaly/coding-challenges/signing-service-challenge/internal/signerd"
+	"github.com/fiskaly/coding-challenges/signing-service-challenge/pkg/crypto"
+)
+
+// NewSignerDaemon wires the signer daemon: the built-in crypto providers, the
+// key pool when configured, a key store that is envelope encrypted when KEKs
+// are configured, and the certificate and time-stamping authorities.
+func NewSignerDaemon(cfg config.Config) (*signerd.Server, error) {
+	var keys devices.KeyStore = inmemory.NewKeyStore()
+	keyring, err := loadKeyring(cfg)
+	if err != nil {
+		return nil, err
+	}
+	if keyring != nil {
+		// The daemon's store starts empty, so it never holds plaintext keys.
+		sealed := envelope.NewKeyStore(keys, keyring)
+		sealed.EnableStrict()
+		keys = sealed
+	}
+
+	issuer, timestamper, err := newAuthorities(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	generator, pool, err := newKeyGenerator(cfg)
+	if err != nil {
+		return nil, fmt.Errorf("configure key pool: %w", err)
+	}
+	server, err := signerd.NewServer(crypto.DefaultRegistry(), keys, generator, crypto.NewKeyImporter(), crypto.NewSignerFactory())
+	if err != nil {
+		if pool != nil {
+			pool.Close()
+		}
+		return nil, err
+	}
+	server.WithKeyPool(pool)
+	server.WithCertificateAuthority(issuer)
+	server.WithTimestamper(timestamper)
+	return server, nil
+}
@@ -2,24 +2,119 @@ package config
 
 import (
 	"os"
+	"strconv"
+	"time"
 )
 
 const (
 	listenAddressEnv     = "LISTEN_ADDRESS"
 	defaultListenAddress = ":8080"
+	adminAddressEnv      = "ADMIN_ADDRESS"
+
+	minKeyStrengthEnv     = "MIN_KEY_STRENGTH"
+	defaultMinKeyStrength = 112
+
+	keyEncryptionKeysEnv     = "KEY_ENCRYPTION_KEYS"
+	keyEncryptionKeysFileEnv = "KEY_ENCRYPTION_KEYS_FILE"
+
+	signerSocketEnv = "SIGNER_SOCKET"
+
+	signerCacheSizeEnv     = "SIGNER_CACHE_SIZE"
+	defaultSignerCacheSize = 1024
+
+	keyPoolHighEnv    = "KEY_POOL_HIGH"
+	keyPoolLowEnv     = "KEY_POOL_LOW"
+	keyPoolWorkersEnv = "KEY_POOL_WORKERS"
+	keyPoolWarmEnv    = "KEY_POOL_WARM"
+
+	caKeyFileEnv              = "CA_KEY_FILE"
+	caCommonNameEnv           = "CA_COMMON_NAME"
+	caValidityEnv             = "CA_VALIDITY"
+	deviceCertValidityEnv     = "DEVICE_CERT_VALIDITY"
+	defaultCAValidity         = 10 * 365 * 24 * time.Hour
+	defaultDeviceCertValidity = 365 * 24 * time.Hour
+
+	tsaEnabledEnv = "TSA_ENABLED"
+	tsaKeyFileEnv = "TSA_KEY_FILE"
+	tsaPolicyEnv  = "TSA_POLICY"
 )
 
 // Config captures runtime configuration knobs for the application.
 type Config struct {
 	ListenAddress string
+	// AdminAddress is the listen address of the admin endpoints (metrics);
+	// empty disables them. Bind it to a private interface.
+	AdminAddress string
+	// MinKeyStrength is the minimum security strength (in bits) accepted for device keys.
+	MinKeyStrength int
+	// KeyEncryptionKeys lists "id:base64key" KEKs for encrypting private keys at
+	// rest; the first is primary. Empty disables encryption.
+	KeyEncryptionKeys string
+	// KeyEncryptionKeysFile points at a file holding the KEK list instead. The
+	// file is re-read on SIGHUP to rotate KEKs without a restart.
+	KeyEncryptionKeysFile string
+	// SignerSocket is the Unix socket of the signer daemon. When set, private
+	// keys live only in the daemon; empty keeps signing in-process.
+	SignerSocket string
+	// SignerCacheSize bounds the number of decoded signers kept in memory; zero
+	// disables the cache.
+	SignerCacheSize int
+	// KeyPoolHigh is the number of pre-generated keys kept per algorithm and
+	// key spec; zero disables the key pool.
+	KeyPoolHigh int
+	// KeyPoolLow is the depth that triggers a refill; defaults to half of KeyPoolHigh.
+	KeyPoolLow int
+	// KeyPoolWorkers is the number of generator goroutines per pool.
+	KeyPoolWorkers int
+	// KeyPoolWarm lists "ALGORITHM[:KEY_SPEC]" pools to fill at startup;
+	// other pools start on first use.
+	KeyPoolWarm string
+	// CAKeyFile points at the PEM private key of the service root CA; empty
+	// generates a fresh root key at startup.
+	CAKeyFile string
+	// CACommonName names the root certificate subject; empty uses the default.
+	CACommonName string
+	// CAValidity is the lifetime of the self-signed root certificate.
+	CAValidity time.Duration
+	// DeviceCertValidity is the lifetime of issued device certificates.
+	DeviceCertValidity time.Duration
+	// TSAEnabled makes the built-in time-stamping authority issue an RFC 3161
+	// token for every signature.
+	TSAEnabled bool
+	// TSAKeyFile points at the PEM private key of the TSA; empty generates a
+	// fresh key at startup.
+	TSAKeyFile string
+	// TSAPolicy is the dotted OID of the TSA policy; empty uses the default.
+	TSAPolicy string
 }
 
 // Load resolves configuration from environment variables, falling back to defaults.
 func Load() Config {
 	listenAddr := lookupEnvDefault(listenAddressEnv, defaultListenAddress)
+	minKeyStrength := lookupEnvIntDefault(minKeyStrengthEnv, defaultMinKeyStrength)
+	signerCacheSize := lookupEnvIntDefault(signerCacheSizeEnv, defaultSignerCacheSize)
+	keyPoolHigh := lookupEnvIntDefault(keyPoolHighEnv, 0)
+	keyPoolLow := lookupEnvIntDefault(keyPoolLowEnv, keyPoolHigh/2)
 
 	return Config{
-		ListenAddress: listenAddr,
+		ListenAddress:         listenAddr,
+		AdminAddress:          os.Getenv(adminAddressEnv),
+		MinKeyStrength:        minKeyStrength,
+		KeyEncryptionKeys:     os.Getenv(keyEncryptionKeysEnv),
+		KeyEncryptionKeysFile: os.Getenv(keyEncryptionKeysFileEnv),
+		SignerSocket:          os.Getenv(signerSocketEnv),
+		SignerCacheSize:       signerCacheSize,
+		KeyPoolHigh:           keyPoolHigh,
+		KeyPoolLow:            keyPoolLow,
+		KeyPoolWorkers:        lookupEnvIntDefault(keyPoolWorkersEnv, 1),
+		KeyPoolWarm:           os.Getenv(keyPoolWarmEnv),
+		CAKeyFile:             os.Getenv(caKeyFileEnv),
+		CACommonName:          os.Getenv(caCommonNameEnv),
+		CAValidity:            lookupEnvDurationDefault(caValidityEnv, defaultCAValidity),
+		DeviceCertValidity:    lookupEnvDurationDefault(deviceCertValidityEnv, defaultDeviceCertValidity),
+		TSAEnabled:            lookupEnvBool(tsaEnabledEnv),
+		TSAKeyFile:            os.Getenv(tsaKeyFileEnv),
+		TSAPolicy:             os.Getenv(tsaPolicyEnv),
 	}
 }
 
@@ -29,3 +124,37 @@ func lookupEnvDefault(key, fallback string) string {
 	}
 	return fallback
 }
+
+// lookupEnvBool reports whether key is set to a true value as understood by
+// strconv.ParseBool; anything else, including unset, is false.
+func lookupEnvBool(key string) bool {
+	enabled, err := strconv.ParseBool(os.Getenv(key))
+	return err == nil && enabled
+}
+
+// lookupEnvIntDefault parses key as a non-negative integer. Zero is kept, as it
+// disables features such as the signer cache; unset, malformed or negative
+// values yield fallback.
+func lookupEnvIntDefault(key string, fallback int) int {
+	value, ok := os.LookupEnv(key)
+	if !ok || value == "" {
+		return fallback
+	}
+	parsed, err := strconv.Atoi(value)
+	if err != nil || parsed < 0 {
+		return fallback
+	}
+	return parsed
+}
+
+func lookupEnvDurationDefault(key string, fallback time.Duration) time.Duration {
+	value, ok := os.LookupEnv(key)
+	if !ok || value == "" {
+		return fallback
+	}
+	parsed, err := time.ParseDuration(value)
+	if err != nil || parsed <= 0 {
+		return fallback
+	}
+	return parsed
+}
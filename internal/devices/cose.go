@@ -0,0 +1,88 @@
+package devices
+
+import (
+	"fmt"
+
+	"github.com/fiskaly/coding-challenges/signing-service-challenge/domain"
+	"github.com/fiskaly/coding-challenges/signing-service-challenge/pkg/cbor"
+)
+
+// coseSign1Tag is the CBOR tag of a COSE_Sign1 message (RFC 9052 section 4.2).
+const coseSign1Tag = 18
+
+// Header labels of the COSE_Sign1 protected header. The counter and chain
+// reference use text labels, which RFC 9052 section 3.1 allows for
+// application-defined parameters.
+const (
+	coseLabelAlgorithm = 1
+	coseLabelKeyID     = 4
+	coseLabelCounter   = "counter"
+	coseLabelReference = "reference"
+)
+
+// coseAlgorithms maps JWA names onto COSE algorithm identifiers (RFC 9053, RFC 8812).
+var coseAlgorithms = map[string]int64{
+	"ES256": -7,
+	"ES384": -35,
+	"ES512": -36,
+	"EdDSA": -8,
+	"PS256": -37,
+	"PS384": -38,
+	"PS512": -39,
+	"RS256": -257,
+	"RS384": -258,
+	"RS512": -259,
+}
+
+// COSEHeader is the protected header of the COSE_Sign1 issued for a signature.
+// Reference holds the previous signature (or the device ID for the first one),
+// i.e. the value the secured payload chains to.
+type COSEHeader struct {
+	Algorithm int64
+	KeyID     string
+	Counter   uint64
+	Reference []byte
+}
+
+// COSEAlgorithm returns the COSE algorithm identifier matching the device
+// parameters. COSE and JWS register the same algorithms, so devices JWS cannot
+// express are rejected alike.
+func COSEAlgorithm(algorithms domain.Algorithms, device domain.Device) (int64, error) {
+	name, err := joseAlgorithm(algorithms, device, "COSE")
+	if err != nil {
+		return 0, err
+	}
+	return coseAlgorithms[name], nil
+}
+
+// COSESigStructure returns the encoded protected header and the
+// Sig_structure (RFC 9052 section 4.4) to sign for payload, without external
+// additional authenticated data.
+func COSESigStructure(header COSEHeader, payload []byte) (protected, toBeSigned []byte, err error) {
+	protected, err = cbor.Marshal(map[any]any{
+		coseLabelAlgorithm: header.Algorithm,
+		coseLabelKeyID:     []byte(header.KeyID),
+		coseLabelCounter:   header.Counter,
+		coseLabelReference: header.Reference,
+	})
+	if err != nil {
+		return nil, nil, fmt.Errorf("encode cose header: %w", err)
+	}
+	toBeSigned, err = cbor.Marshal([]any{"Signature1", protected, []byte{}, payload})
+	if err != nil {
+		return nil, nil, fmt.Errorf("encode cose sig structure: %w", err)
+	}
+	return protected, toBeSigned, nil
+}
+
+// COSESign1 assembles the tagged COSE_Sign1 message with an empty unprotected header.
+func COSESign1(protected, payload, signature []byte) ([]byte, error) {
+	message, err := cbor.Marshal(cbor.Tag{
+		Number:  coseSign1Tag,
+		Content: []any{protected, map[any]any{}, payload, signature},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("encode cose_sign1: %w", err)
+	}
+	return message, nil
+}
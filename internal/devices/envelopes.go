@@ -0,0 +1,137 @@
+package devices
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+
+	"github.com/fiskaly/coding-challenges/signing-service-challenge/domain"
+)
+
+// rawSignerFor returns the key ID and a signer producing the signature layout
+// JOSE and COSE expect for the device key version: the fixed-width r||s
+// concatenation for ECDSA. The key ID is the RFC 7638 thumbprint served with
+// the JWK export, so clients can pick the key from GET /public-key?format=jwk.
+// Like the chain signature, every envelope signature is verified before it is
+// returned, so a faulty one is never stored.
+func (s *Service) rawSignerFor(ctx context.Context, device domain.Device) (string, Signer, error) {
+	if s.keyExporter == nil {
+		return "", nil, domain.InternalError{Reason: "public key export not configured"}
+	}
+	material, err := s.loadKey(ctx, device, device.KeyVersion)
+	if err != nil {
+		return "", nil, err
+	}
+	exported, err := s.keyExporter.ExportPublicKey(device, material)
+	if err != nil {
+		return "", nil, fmt.Errorf("export public key: %w", err)
+	}
+
+	if encoding, err := domain.ResolveEncoding(s.algorithms, device.Algorithm, domain.EncodingP1363); err == nil {
+		device.Encoding = encoding
+	}
+	key, err := s.resolveSigner(ctx, device, false, true)
+	if err != nil {
+		return "", nil, err
+	}
+	return exported.KeyID, verifyingSigner{service: s, deviceID: device.ID, key: key}, nil
+}
+
+// verifyingSigner checks each signature against the device public key through
+// Service.signAndVerify.
+type verifyingSigner struct {
+	service  *Service
+	deviceID uuid.UUID
+	key      signingKey
+}
+
+// Sign signs dataToBeSigned and returns errFaultySignature if the signature
+// does not verify.
+func (v verifyingSigner) Sign(dataToBeSigned []byte) ([]byte, error) {
+	return v.service.signAndVerify(v.deviceID, v.key, dataToBeSigned)
+}
+
+// jwsSigner issues the JWS of one signature operation. The header is fixed
+// before the counter is known; sign completes it once the signature lock is held.
+type jwsSigner struct {
+	header JWSHeader
+	signer Signer
+}
+
+// jwsSignerFor prepares a JWS for the current device key.
+func (s *Service) jwsSignerFor(ctx context.Context, device domain.Device) (*jwsSigner, error) {
+	algorithm, err := JWSAlgorithm(s.algorithms, device)
+	if err != nil {
+		return nil, err
+	}
+	keyID, signer, err := s.rawSignerFor(ctx, device)
+	if err != nil {
+		return nil, err
+	}
+	return &jwsSigner{
+		header: JWSHeader{Algorithm: algorithm, KeyID: keyID, DeviceID: device.ID.String()},
+		signer: signer,
+	}, nil
+}
+
+// sign returns the compact JWS over the secured payload of signature counter.
+func (j *jwsSigner) sign(counter uint64, payload string) (string, error) {
+	header := j.header
+	header.Counter = counter
+	signingInput, err := JWSSigningInput(header, []byte(payload))
+	if err != nil {
+		return "", err
+	}
+	signature, err := j.signer.Sign(signingInput)
+	if errors.Is(err, errFaultySignature) {
+		return "", err
+	}
+	if err != nil {
+		return "", fmt.Errorf("sign jws: %w", err)
+	}
+	return CompactJWS(signingInput, signature), nil
+}
+
+// coseSigner issues COSE_Sign1 messages for one device key version.
+type coseSigner struct {
+	header COSEHeader
+	signer Signer
+}
+
+// coseSignerFor prepares COSE_Sign1 messages for the key version set on device.
+func (s *Service) coseSignerFor(ctx context.Context, device domain.Device) (*coseSigner, error) {
+	algorithm, err := COSEAlgorithm(s.algorithms, device)
+	if err != nil {
+		return nil, err
+	}
+	keyID, signer, err := s.rawSignerFor(ctx, device)
+	if err != nil {
+		return nil, err
+	}
+	return &coseSigner{
+		header: COSEHeader{Algorithm: algorithm, KeyID: keyID},
+		signer: signer,
+	}, nil
+}
+
+// sign returns the COSE_Sign1 message over the secured payload of signature
+// counter, whose chain reference is carried as a protected header.
+func (c *coseSigner) sign(counter uint64, reference []byte, payload string) ([]byte, error) {
+	header := c.header
+	header.Counter = counter
+	header.Reference = reference
+	protected, toBeSigned, err := COSESigStructure(header, []byte(payload))
+	if err != nil {
+		return nil, err
+	}
+	signature, err := c.signer.Sign(toBeSigned)
+	if errors.Is(err, errFaultySignature) {
+		return nil, err
+	}
+	if err != nil {
+		return nil, fmt.Errorf("sign cose: %w", err)
+	}
+	return COSESign1(protected, []byte(payload), signature)
+}
@@ -0,0 +1,71 @@
+package devices_test
+
+import (
+	"encoding/hex"
+	"testing"
+
+	"github.com/fiskaly/coding-challenges/signing-service-challenge/domain"
+	"github.com/fiskaly/coding-challenges/signing-service-challenge/internal/devices"
+	"github.com/fiskaly/coding-challenges/signing-service-challenge/pkg/crypto"
+)
+
+func TestJWSAlgorithm(t *testing.T) {
+	for _, tc := range []struct {
+		device   domain.Device
+		expected string
+	}{
+		{domain.Device{Algorithm: domain.AlgorithmRSA, KeySpec: domain.KeySpecRSA2048}, "RS256"},
+		{domain.Device{Algorithm: domain.AlgorithmRSA, KeySpec: domain.KeySpecRSA3072, Scheme: domain.SchemeRSAPSS, Digest: domain.DigestSHA384, SaltLength: 48}, "PS384"},
+		{domain.Device{Algorithm: domain.AlgorithmECDSA, KeySpec: domain.KeySpecP384, Scheme: domain.SchemeECDSA, Digest: domain.DigestSHA384}, "ES384"},
+		{domain.Device{Algorithm: domain.AlgorithmECDSA, KeySpec: domain.KeySpecP256, Scheme: domain.SchemeECDSADeterministic, Digest: domain.DigestSHA256}, "ES256"},
+		{domain.Device{Algorithm: domain.AlgorithmEd25519, KeySpec: domain.KeySpecEd25519}, "EdDSA"},
+		{domain.Device{Algorithm: domain.AlgorithmRSA, KeySpec: domain.KeySpecRSA2048, Digest: domain.DigestSHA3_256}, ""},
+		{domain.Device{Algorithm: domain.AlgorithmRSA, KeySpec: domain.KeySpecRSA2048, Scheme: domain.SchemeRSAPSS, Digest: domain.DigestSHA256, SaltLength: 20}, ""},
+		{domain.Device{Algorithm: domain.AlgorithmECDSA, KeySpec: domain.KeySpecP384, Scheme: domain.SchemeECDSA, Digest: domain.DigestSHA512}, ""},
+	} {
+		algorithm, err := devices.JWSAlgorithm(crypto.DefaultRegistry(), tc.device)
+		if tc.expected == "" {
+			if _, ok := err.(domain.ValidationError); !ok {
+				t.Fatalf("expected %+v to be rejected, got %s (%v)", tc.device, algorithm, err)
+			}
+			continue
+		}
+		if err != nil || algorithm != tc.expected {
+			t.Fatalf("expected %s for %+v, got %s (%v)", tc.expected, tc.device, algorithm, err)
+		}
+	}
+}
+
+func TestCOSESigStructure(t *testing.T) {
+	device := domain.Device{Algorithm: domain.AlgorithmECDSA, KeySpec: domain.KeySpecP256, Scheme: domain.SchemeECDSA, Digest: domain.DigestSHA256}
+	algorithm, err := devices.COSEAlgorithm(crypto.DefaultRegistry(), device)
+	if err != nil || algorithm != -7 {
+		t.Fatalf("expected ES256 (-7), got %d (%v)", algorithm, err)
+	}
+	if _, err := devices.COSEAlgorithm(crypto.DefaultRegistry(), domain.Device{Algorithm: domain.AlgorithmRSA, KeySpec: domain.KeySpecRSA2048, Digest: domain.DigestSHA3_256}); err == nil {
+		t.Fatal("expected SHA3 digest to be rejected")
+	}
+
+	protected, toBeSigned, err := devices.COSESigStructure(devices.COSEHeader{Algorithm: -7, KeyID: "k", Counter: 2, Reference: []byte{0xaa}}, []byte("p"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	// {1: -7, 4: h'6b', "counter": 2, "reference": h'aa'}
+	if got := hex.EncodeToString(protected); got != "a4012604416b67636f756e74657202697265666572656e636541aa" {
+		t.Fatalf("unexpected protected header %s", got)
+	}
+	// ["Signature1", << protected >>, h'', h'70']
+	expected := "846a5369676e61747572653158" + "1b" + hex.EncodeToString(protected) + "404170"
+	if got := hex.EncodeToString(toBeSigned); got != expected {
+		t.Fatalf("unexpected Sig_structure %s", got)
+	}
+
+	message, err := devices.COSESign1(protected, []byte("p"), []byte{0x01})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	// 18([<< protected >>, {}, h'70', h'01'])
+	if got := hex.EncodeToString(message); got != "d28458"+"1b"+hex.EncodeToString(protected)+"a041704101" {
+		t.Fatalf("unexpected COSE_Sign1 %s", got)
+	}
+}
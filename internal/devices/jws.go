@@ -0,0 +1,99 @@
+package devices
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+
+	"github.com/fiskaly/coding-challenges/signing-service-challenge/domain"
+)
+
+// JWSHeader is the protected header of the JWS issued for a signature. Next to
+// the RFC 7515 members it carries the device ID and signature counter so
+// verifiers can relate the JWS to the device chain without parsing the payload.
+type JWSHeader struct {
+	Algorithm string `json:"alg"`
+	KeyID     string `json:"kid"`
+	DeviceID  string `json:"device_id"`
+	Counter   uint64 `json:"counter"`
+}
+
+// jwsAlgorithms maps scheme and digest onto RFC 7518 "alg" values.
+var jwsAlgorithms = map[domain.SignatureScheme]map[domain.Digest]string{
+	domain.SchemeRSAPKCS1v15: {domain.DigestSHA256: "RS256", domain.DigestSHA384: "RS384", domain.DigestSHA512: "RS512"},
+	domain.SchemeRSAPSS:      {domain.DigestSHA256: "PS256", domain.DigestSHA384: "PS384", domain.DigestSHA512: "PS512"},
+	domain.SchemeECDSA:       {domain.DigestSHA256: "ES256", domain.DigestSHA384: "ES384", domain.DigestSHA512: "ES512"},
+}
+
+// jwsCurves pins each ES* algorithm to its curve, as RFC 7518 section 3.4 requires.
+var jwsCurves = map[string]domain.KeySpec{
+	"ES256": domain.KeySpecP256,
+	"ES384": domain.KeySpecP384,
+	"ES512": domain.KeySpecP521,
+}
+
+// jwsSaltLengths holds the PSS salt length RFC 7518 section 3.5 fixes to the digest size.
+var jwsSaltLengths = map[domain.Digest]int{
+	domain.DigestSHA256: 32,
+	domain.DigestSHA384: 48,
+	domain.DigestSHA512: 64,
+}
+
+// JWSAlgorithm returns the RFC 7518 "alg" value matching the device
+// parameters. Devices whose parameters JWA cannot express, such as SHA3
+// digests, ECDSA curves paired with another digest size or PSS salts other
+// than the digest size, are rejected with a validation error.
+func JWSAlgorithm(algorithms domain.Algorithms, device domain.Device) (string, error) {
+	return joseAlgorithm(algorithms, device, "JWS")
+}
+
+// joseAlgorithm resolves the JWA name shared by JWS and COSE; format names the
+// output in validation errors.
+func joseAlgorithm(algorithms domain.Algorithms, device domain.Device, format string) (string, error) {
+	scheme, err := domain.ResolveScheme(algorithms, device.Algorithm, device.Scheme)
+	if err != nil {
+		return "", err
+	}
+	if domain.IsSymmetric(algorithms, device.Algorithm) {
+		return "", domain.ValidationError{Field: "format", Message: fmt.Sprintf("%s carries public-key signatures, %s devices produce MACs", format, device.Algorithm)}
+	}
+	if scheme == domain.SchemeEd25519 {
+		return "EdDSA", nil
+	}
+	digest := device.Digest
+	if digest == "" {
+		digest = domain.DigestSHA256
+	}
+	if scheme == domain.SchemeECDSADeterministic {
+		// Deterministic nonces do not change the signature format.
+		scheme = domain.SchemeECDSA
+	}
+
+	algorithm, ok := jwsAlgorithms[scheme][digest]
+	switch {
+	case !ok:
+	case scheme == domain.SchemeECDSA && jwsCurves[algorithm] != device.KeySpec:
+		ok = false
+	case scheme == domain.SchemeRSAPSS && device.SaltLength != 0 && device.SaltLength != jwsSaltLengths[digest]:
+		ok = false
+	}
+	if !ok {
+		return "", domain.ValidationError{Field: "format", Message: fmt.Sprintf("%s defines no algorithm for %s with %s on %s", format, scheme, digest, device.KeySpec)}
+	}
+	return algorithm, nil
+}
+
+// JWSSigningInput returns the RFC 7515 signing input
+// BASE64URL(header) || '.' || BASE64URL(payload).
+func JWSSigningInput(header JWSHeader, payload []byte) ([]byte, error) {
+	encodedHeader, err := json.Marshal(header)
+	if err != nil {
+		return nil, fmt.Errorf("marshal jws header: %w", err)
+	}
+	return []byte(base64.RawURLEncoding.EncodeToString(encodedHeader) + "." + base64.RawURLEncoding.EncodeToString(payload)), nil
+}
+
+// CompactJWS appends the signature over signingInput to form the compact serialization.
+func CompactJWS(signingInput, signature []byte) string {
+	return string(signingInput) + "." + base64.RawURLEncoding.EncodeToString(signature)
+}
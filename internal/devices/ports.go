@@ -2,6 +2,7 @@ package devices
 
 import (
 	"context"
+	"time"
 
 	"github.com/fiskaly/coding-challenges/signing-service-challenge/domain"
 	"github.com/google/uuid"
@@ -17,25 +18,109 @@ type Repository interface {
 }
 
 // KeyStore manages storing and retrieving key material independently of devices.
+// Each device may hold several key versions; rotated keys stay loadable.
 type KeyStore interface {
-	Store(ctx context.Context, deviceID uuid.UUID, material domain.KeyMaterial) error
-	Load(ctx context.Context, deviceID uuid.UUID) (domain.KeyMaterial, error)
+	// Store fails with domain.ErrKeyInUse when another device already holds the key.
+	Store(ctx context.Context, deviceID uuid.UUID, version int, material domain.KeyMaterial) error
+	Load(ctx context.Context, deviceID uuid.UUID, version int) (domain.KeyMaterial, error)
+	// Versions lists the stored key versions of a device in ascending order.
+	Versions(ctx context.Context, deviceID uuid.UUID) ([]int, error)
+	// Delete removes every key version of the device.
 	Delete(ctx context.Context, deviceID uuid.UUID) error
+	// DeleteVersion removes one key version, e.g. to roll back a failed rotation.
+	DeleteVersion(ctx context.Context, deviceID uuid.UUID, version int) error
 }
 
 // Signer describes the minimal behaviour required from crypto signers.
+// Signers are cached and reused across requests, so they must be safe for
+// concurrent use.
 type Signer interface {
 	Sign(dataToBeSigned []byte) ([]byte, error)
 }
 
-// SignerFactory resolves a signer implementation for a given device and key material.
+// Verifier checks signatures against a device public key.
+type Verifier interface {
+	// Verify reports whether signature is valid for data. An error is only
+	// returned when verification could not be performed at all.
+	Verify(data, signature []byte) (bool, error)
+}
+
+// SignerFactory resolves signer and verifier implementations for a given device and key material.
 type SignerFactory interface {
 	SignerFor(device domain.Device, material domain.KeyMaterial) (Signer, error)
+	VerifierFor(device domain.Device, material domain.KeyMaterial) (Verifier, error)
 }
 
-// KeyGenerator can produce key pairs for the configured algorithms.
+// PublicKeyExporter converts stored public key material into interchange formats.
+type PublicKeyExporter interface {
+	ExportPublicKey(device domain.Device, material domain.KeyMaterial) (PublicKeyExport, error)
+}
+
+// KeyGenerator can produce key pairs for the configured algorithms and key specs.
 type KeyGenerator interface {
-	Generate(algorithm domain.Algorithm) (domain.KeyMaterial, error)
+	Generate(algorithm domain.Algorithm, spec domain.KeySpec) (domain.KeyMaterial, error)
+}
+
+// KeyReleaser frees resources that generated or imported key material holds
+// outside the key store, such as keys kept by the signer daemon. The service
+// calls it for material it abandons before the key store has taken it over.
+type KeyReleaser interface {
+	Release(material domain.KeyMaterial) error
+}
+
+// KeyImporter parses caller-supplied private keys into stored key material.
+type KeyImporter interface {
+	// Import checks the key against the algorithm and reports the key spec it provides.
+	Import(algorithm domain.Algorithm, privateKeyPEM []byte) (ImportedKey, error)
+}
+
+// CertificateIssuer certifies device public keys under the service root CA.
+type CertificateIssuer interface {
+	// Issue returns a DER certificate for the PEM public key of a device, valid from notBefore.
+	Issue(device domain.Device, publicKeyPEM []byte, notBefore time.Time) ([]byte, error)
+	// Root returns the DER encoded root certificate verifiers anchor trust in.
+	Root() []byte
+}
+
+// CertificateStore keeps the certificate chain of each device key version,
+// leaf first. Get reports missing chains as domain.NotFoundError.
+type CertificateStore interface {
+	Put(ctx context.Context, deviceID uuid.UUID, version int, chain [][]byte) error
+	Get(ctx context.Context, deviceID uuid.UUID, version int) ([][]byte, error)
+	// Delete removes the chains of every key version of the device.
+	Delete(ctx context.Context, deviceID uuid.UUID) error
+	// DeleteVersion removes the chain of one key version.
+	DeleteVersion(ctx context.Context, deviceID uuid.UUID, version int) error
+}
+
+// CertificateRequester builds PKCS#10 requests so device keys can be certified
+// by an external CA, and checks the chains such a CA returns.
+type CertificateRequester interface {
+	// CreateRequest returns a DER certificate request for the PEM public key,
+	// signed through signer.
+	CreateRequest(device domain.Device, publicKeyPEM []byte, signer Signer) ([]byte, error)
+	// CheckChain parses a PEM chain, leaf first, verifies that the leaf
+	// certifies the PEM public key and returns the DER certificates. Chain
+	// problems are reported as domain.ValidationError.
+	CheckChain(publicKeyPEM []byte, chainPEM []byte) ([][]byte, error)
+}
+
+// SignedDataEncoder wraps stored signatures into detached CMS SignedData
+// (RFC 5652) for archival.
+type SignedDataEncoder interface {
+	// EncodeSignedData returns a DER ContentInfo for signature, made with the
+	// scheme, digest and encoding set on device. The leaf of chain identifies
+	// the signer when present; otherwise the PEM public key does.
+	EncodeSignedData(device domain.Device, publicKeyPEM []byte, chain [][]byte, signature []byte) ([]byte, error)
+}
+
+// Timestamper obtains RFC 3161 time-stamp tokens proving that a signature
+// existed at a point in time. The service ships a built-in authority; an
+// external TSA can be plugged in by implementing this port.
+type Timestamper interface {
+	// Timestamp returns a DER TimeStampToken whose message imprint is digest,
+	// the SHA-256 hash of the timestamped data.
+	Timestamp(ctx context.Context, digest []byte) ([]byte, error)
 }
 
 // SignatureStore persists signature records per device.
@@ -2,9 +2,11 @@ package devices
 
 import (
 	"context"
+	"crypto/sha256"
 	"encoding/base64"
 	"errors"
 	"fmt"
+	"strconv"
 	"strings"
 	"sync"
 	"time"
@@ -13,26 +15,53 @@ import (
 	"github.com/google/uuid"
 )
 
+// DefaultMinKeyStrength is the minimum security strength (in bits) a device key
+// must provide unless overridden via WithMinKeyStrength.
+const DefaultMinKeyStrength = 112
+
+// errNoSecondaryKey rejects secondary key operations on single-key devices.
+var errNoSecondaryKey = domain.ValidationError{Field: "secondary", Message: "device has no secondary key"}
+
+// errFaultySignature reports a fresh signature that failed verification
+// against the device public key; it is never stored.
+var errFaultySignature = domain.InternalError{Reason: "signature failed verification"}
+
 // Service encapsulates domain rules for managing devices and signatures.
 type Service struct {
+	algorithms     domain.Algorithms
 	repo           Repository
 	keyStore       KeyStore
 	keyGenerator   KeyGenerator
 	signerFactory  SignerFactory
 	signatureStore SignatureStore
+	keyExporter    PublicKeyExporter
+	keyImporter    KeyImporter
+	keyReleaser    KeyReleaser
+	issuer         CertificateIssuer
+	requester      CertificateRequester
+	certificates   CertificateStore
+	cms            SignedDataEncoder
+	timestamper    Timestamper
+	signers        *signerCache
 	clock          func() time.Time
+	minKeyStrength int
 	signMX         sync.RWMutex // guards Append operations to keep signature counters monotonic
+	updateMX       sync.Mutex   // serialises read-modify-write updates of devices
 }
 
 // NewService constructs a Service with injectable dependencies for testing.
-func NewService(repo Repository, keyStore KeyStore, generator KeyGenerator, signerFactory SignerFactory, signatureStore SignatureStore) *Service {
+// algorithms describes the signing algorithms devices may use.
+func NewService(algorithms domain.Algorithms, repo Repository, keyStore KeyStore, generator KeyGenerator, signerFactory SignerFactory, signatureStore SignatureStore) *Service {
 	return &Service{
+		algorithms:     algorithms,
 		repo:           repo,
 		keyStore:       keyStore,
 		keyGenerator:   generator,
 		signerFactory:  signerFactory,
 		signatureStore: signatureStore,
+		signers:        newSignerCache(DefaultSignerCacheSize),
 		clock:          time.Now,
+		minKeyStrength: DefaultMinKeyStrength,
 	}
 }
 
@@ -43,10 +72,96 @@ func (s *Service) WithClock(clock func() time.Time) {
 	}
 }
 
+// WithMinKeyStrength sets the minimum security strength (in bits) accepted for new device keys.
+func (s *Service) WithMinKeyStrength(bits int) {
+	if bits > 0 {
+		s.minKeyStrength = bits
+	}
+}
+
+// WithSignerCacheSize bounds the number of decoded signers kept in memory.
+// Zero or a negative size disables caching.
+func (s *Service) WithSignerCacheSize(size int) {
+	if size <= 0 {
+		s.signers = nil
+		return
+	}
+	s.signers = newSignerCache(size)
+}
+
+// WithPublicKeyExporter enables public key export through the given exporter.
+func (s *Service) WithPublicKeyExporter(exporter PublicKeyExporter) {
+	if exporter != nil {
+		s.keyExporter = exporter
+	}
+}
+
+// WithKeyImporter enables creating devices from existing private keys.
+func (s *Service) WithKeyImporter(importer KeyImporter) {
+	if importer != nil {
+		s.keyImporter = importer
+	}
+}
+
+// WithKeyReleaser frees key material that is abandoned when provisioning or
+// rotation fails, e.g. daemon-held keys behind handles.
+func (s *Service) WithKeyReleaser(releaser KeyReleaser) {
+	if releaser != nil {
+		s.keyReleaser = releaser
+	}
+}
+
+// WithCertificateAuthority makes the service certify every device key version
+// with issuer and keep the chains in store.
+func (s *Service) WithCertificateAuthority(issuer CertificateIssuer, store CertificateStore) {
+	if issuer != nil && store != nil {
+		s.issuer = issuer
+		s.certificates = store
+	}
+}
+
+// WithCertificateRequester enables certificate requests for external CAs and
+// keeps uploaded chains in store, which may be shared with the built-in CA.
+func (s *Service) WithCertificateRequester(requester CertificateRequester, store CertificateStore) {
+	if requester != nil && store != nil {
+		s.requester = requester
+		s.certificates = store
+	}
+}
+
+// WithSignedDataEncoder enables CMS export of stored signatures. The signer
+// certificate is taken from the certificate store when one is configured.
+func (s *Service) WithSignedDataEncoder(encoder SignedDataEncoder) {
+	if encoder != nil {
+		s.cms = encoder
+	}
+}
+
+// WithTimestamper makes the service obtain an RFC 3161 time-stamp token for
+// every new signature and store it with the record.
+func (s *Service) WithTimestamper(timestamper Timestamper) {
+	if timestamper != nil {
+		s.timestamper = timestamper
+	}
+}
+
 // CreateDeviceInput captures user-provided data to create a new device.
 type CreateDeviceInput struct {
 	ID        uuid.UUID
 	Algorithm domain.Algorithm
+	// KeySpec selects the key size or curve; empty picks the algorithm default.
+	KeySpec domain.KeySpec
+	// Scheme selects the signature scheme; empty picks the algorithm default.
+	Scheme domain.SignatureScheme
+	// SaltLength configures RSASSA-PSS; zero uses the digest size.
+	SaltLength int
+	// Digest selects the payload hash; empty picks the digest matching the key strength.
+	Digest domain.Digest
+	// Encoding selects the default signature encoding; empty picks the algorithm default.
+	Encoding domain.SignatureEncoding
+	// Secondary requests a second key pair of another algorithm that signs
+	// every payload as well; empty fields pick the defaults of its algorithm.
+	Secondary *domain.SecondaryKey
 	Label     string
 }
 
@@ -61,40 +176,237 @@ func (s *Service) CreateDevice(ctx context.Context, input CreateDeviceInput) (*C
 		return nil, errors.New("device service is nil")
 	}
 
-	label := strings.TrimSpace(input.Label)
+	spec, err := s.resolveKeySpec(input.Algorithm, input.KeySpec)
+	if err != nil {
+		return nil, err
+	}
+
+	device, err := s.newDevice(input.ID, input.Algorithm, spec, input.Scheme, input.SaltLength, input.Digest, input.Encoding, input.Label)
+	if err != nil {
+		return nil, err
+	}
+	if input.Secondary != nil {
+		if device.Secondary, err = s.newSecondaryKey(device, *input.Secondary); err != nil {
+			return nil, err
+		}
+	}
+
+	keys, err := s.generateKeys(device)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.provision(ctx, device, keys)
+}
+
+// ImportDeviceInput captures the data required to create a device around an existing private key.
+type ImportDeviceInput struct {
+	ID        uuid.UUID
+	Algorithm domain.Algorithm
+	// PrivateKey holds a PEM encoded PKCS#8, PKCS#1 or SEC1 private key.
+	PrivateKey []byte
+	Scheme     domain.SignatureScheme
+	SaltLength int
+	Digest     domain.Digest
+	Encoding   domain.SignatureEncoding
+	Label      string
+}
+
+// ImportDevice provisions a device that signs with a caller-supplied key. The
+// key spec is derived from the key and must satisfy the minimum strength.
+func (s *Service) ImportDevice(ctx context.Context, input ImportDeviceInput) (*CreateDeviceResult, error) {
+	if s == nil {
+		return nil, errors.New("device service is nil")
+	}
+	if s.keyImporter == nil {
+		return nil, domain.InternalError{Reason: "key import not configured"}
+	}
+
+	imported, err := s.keyImporter.Import(input.Algorithm, input.PrivateKey)
+	if err != nil {
+		return nil, err
+	}
+
+	spec, err := s.resolveKeySpec(input.Algorithm, imported.KeySpec)
+	if err != nil {
+		s.release(imported.Material)
+		return nil, err
+	}
 
-	keys, err := s.keyGenerator.Generate(input.Algorithm)
+	device, err := s.newDevice(input.ID, input.Algorithm, spec, input.Scheme, input.SaltLength, input.Digest, input.Encoding, input.Label)
 	if err != nil {
-		return nil, fmt.Errorf("generate key pair: %w", err)
+		s.release(imported.Material)
+		return nil, err
+	}
+
+	return s.provision(ctx, device, imported.Material)
+}
+
+// newDevice resolves the signing parameters of a device that is about to be created.
+func (s *Service) newDevice(id uuid.UUID, algorithm domain.Algorithm, spec domain.KeySpecDescriptor, scheme domain.SignatureScheme, saltLength int, digest domain.Digest, encoding domain.SignatureEncoding, label string) (domain.Device, error) {
+	scheme, err := domain.ResolveScheme(s.algorithms, algorithm, scheme)
+	if err != nil {
+		return domain.Device{}, err
+	}
+	if err := domain.ValidateSaltLength(scheme, saltLength); err != nil {
+		return domain.Device{}, err
+	}
+
+	digest, err = domain.ResolveDigest(s.algorithms, algorithm, spec, digest)
+	if err != nil {
+		return domain.Device{}, err
+	}
+
+	encoding, err = domain.ResolveEncoding(s.algorithms, algorithm, encoding)
+	if err != nil {
+		return domain.Device{}, err
 	}
 
 	now := s.clock().UTC()
-	device := domain.Device{
-		ID:        input.ID,
-		Algorithm: input.Algorithm,
-		Label:     label,
-		CreatedAt: now,
-		UpdatedAt: now,
+	return domain.Device{
+		ID:         id,
+		Algorithm:  algorithm,
+		KeySpec:    spec.Spec,
+		Scheme:     scheme,
+		SaltLength: saltLength,
+		Digest:     digest,
+		Encoding:   encoding,
+		KeyVersion: domain.InitialKeyVersion,
+		Label:      strings.TrimSpace(label),
+		CreatedAt:  now,
+		UpdatedAt:  now,
+	}, nil
+}
+
+// newSecondaryKey resolves the secondary key of a hybrid device. Both keys must
+// be public-key algorithms, they must differ in algorithm, and the secondary key
+// is held to the same strength policy as the primary one.
+func (s *Service) newSecondaryKey(device domain.Device, requested domain.SecondaryKey) (*domain.SecondaryKey, error) {
+	if err := domain.RequirePublicKey(s.algorithms, device.Algorithm); err != nil {
+		return nil, err
+	}
+	if err := domain.RequirePublicKey(s.algorithms, requested.Algorithm); err != nil {
+		return nil, err
+	}
+	if requested.Algorithm == device.Algorithm {
+		return nil, domain.ValidationError{Field: "secondary.algorithm", Message: "secondary algorithm must differ from the device algorithm"}
+	}
+
+	spec, err := s.resolveKeySpec(requested.Algorithm, requested.KeySpec)
+	if err != nil {
+		return nil, err
 	}
+	resolved, err := s.newDevice(device.ID, requested.Algorithm, spec, requested.Scheme, requested.SaltLength, requested.Digest, requested.Encoding, "")
+	if err != nil {
+		return nil, err
+	}
+	return &domain.SecondaryKey{
+		Algorithm:  resolved.Algorithm,
+		KeySpec:    resolved.KeySpec,
+		Scheme:     resolved.Scheme,
+		SaltLength: resolved.SaltLength,
+		Digest:     resolved.Digest,
+		Encoding:   resolved.Encoding,
+	}, nil
+}
 
+// generateKeys creates the key material of a device, including the key pair
+// of the secondary algorithm on hybrid devices.
+func (s *Service) generateKeys(device domain.Device) (domain.KeyMaterial, error) {
+	keys, err := s.keyGenerator.Generate(device.Algorithm, device.KeySpec)
+	if err != nil {
+		return domain.KeyMaterial{}, fmt.Errorf("generate key pair: %w", err)
+	}
+	if device.Secondary != nil {
+		secondary, err := s.keyGenerator.Generate(device.Secondary.Algorithm, device.Secondary.KeySpec)
+		if err != nil {
+			s.release(keys)
+			return domain.KeyMaterial{}, fmt.Errorf("generate secondary key pair: %w", err)
+		}
+		keys.Secondary = &secondary
+	}
+	return keys, nil
+}
+
+// provision persists a device together with its key material and certificate,
+// rolling back the device when either cannot be stored. Key material that never
+// reached the key store is released; stored material goes with the key store
+// entry.
+func (s *Service) provision(ctx context.Context, device domain.Device, keys domain.KeyMaterial) (*CreateDeviceResult, error) {
 	if err := s.repo.Create(ctx, device); err != nil {
+		s.release(keys)
 		return nil, err
 	}
 
-	if err := s.keyStore.Store(ctx, device.ID, keys); err != nil {
+	if err := s.keyStore.Store(ctx, device.ID, device.KeyVersion, keys); err != nil {
 		_ = s.repo.Delete(ctx, device.ID)
+		s.release(keys)
+		if errors.Is(err, domain.ErrKeyInUse) {
+			return nil, err
+		}
 		return nil, fmt.Errorf("store key material: %w", err)
 	}
 
+	if err := s.certify(ctx, device, device.KeyVersion, keys); err != nil {
+		_ = s.keyStore.Delete(ctx, device.ID)
+		_ = s.repo.Delete(ctx, device.ID)
+		return nil, err
+	}
+
 	return &CreateDeviceResult{
 		Device: device,
 	}, nil
 }
 
+// release hands abandoned key material to the key releaser, if any. Failures
+// are ignored: the caller is already reporting the error that caused the
+// rollback.
+func (s *Service) release(keys domain.KeyMaterial) {
+	if s.keyReleaser != nil {
+		_ = s.keyReleaser.Release(keys)
+	}
+}
+
+// certify issues and stores the certificate of one device key version. It is a
+// no-op unless a certificate authority is configured, and for devices of a
+// symmetric algorithm, which have no public key to certify.
+func (s *Service) certify(ctx context.Context, device domain.Device, version int, keys domain.KeyMaterial) error {
+	if s.issuer == nil || domain.IsSymmetric(s.algorithms, device.Algorithm) {
+		return nil
+	}
+	certificate, err := s.issuer.Issue(device, keys.Public, s.clock().UTC())
+	if err != nil {
+		return fmt.Errorf("issue certificate: %w", err)
+	}
+	if err := s.certificates.Put(ctx, device.ID, version, [][]byte{certificate}); err != nil {
+		return fmt.Errorf("store certificate: %w", err)
+	}
+	return nil
+}
+
+// resolveKeySpec validates the requested key spec and enforces the minimum-strength policy.
+func (s *Service) resolveKeySpec(algorithm domain.Algorithm, spec domain.KeySpec) (domain.KeySpecDescriptor, error) {
+	resolved, err := domain.ResolveKeySpec(s.algorithms, algorithm, spec)
+	if err != nil {
+		return domain.KeySpecDescriptor{}, err
+	}
+	if resolved.Strength < s.minKeyStrength {
+		return domain.KeySpecDescriptor{}, domain.ValidationError{
+			Field:   "key_spec",
+			Message: fmt.Sprintf("%s provides %d bits of security, at least %d required", resolved.Spec, resolved.Strength, s.minKeyStrength),
+		}
+	}
+	return resolved, nil
+}
+
 // SignTransactionInput carries the parameters required to sign a payload.
 type SignTransactionInput struct {
 	DeviceID uuid.UUID
 	Data     string
+	// Encoding overrides the device signature encoding for this signature.
+	Encoding domain.SignatureEncoding
+	// Format requests an additional serialisation of the signature, e.g. JWS.
+	Format domain.SignatureFormat
 }
 
 // SignatureResult represents the outcome of a signing operation.
@@ -102,6 +414,16 @@ type SignatureResult struct {
 	Signature    string
 	SignedData   string
 	CounterValue uint64
+	Scheme       domain.SignatureScheme
+	Encoding     domain.SignatureEncoding
+	KeyVersion   int
+	JWS          string // Compact JWS over SignedData when requested.
+	COSE         []byte // COSE_Sign1 message over SignedData when requested.
+	Timestamp    []byte // RFC 3161 TimeStampToken over the signature when a Timestamper is configured.
+
+	// SecondarySignature is the signature of the secondary key of hybrid
+	// devices over the same SignedData.
+	SecondarySignature string
 }
 
 // SignTransaction creates a signature for the given payload while keeping counters consistent.
@@ -113,20 +435,46 @@ func (s *Service) SignTransaction(ctx context.Context, input SignTransactionInpu
 	if strings.TrimSpace(input.Data) == "" {
 		return nil, domain.ValidationError{Field: "data", Message: "data is required"}
 	}
+	if err := domain.ValidateFormat(input.Format); err != nil {
+		return nil, err
+	}
 
 	device, err := s.repo.Get(ctx, input.DeviceID)
 	if err != nil {
 		return nil, err
 	}
 
-	material, err := s.keyStore.Load(ctx, device.ID)
+	scheme, err := domain.ResolveScheme(s.algorithms, device.Algorithm, device.Scheme)
 	if err != nil {
-		return nil, fmt.Errorf("load key material: %w", err)
+		return nil, err
+	}
+	device.Encoding, err = s.resolveEncoding(device, input.Encoding)
+	if err != nil {
+		return nil, err
 	}
 
-	signer, err := s.signerFactory.SignerFor(device, material)
+	signer, err := s.resolveSigner(ctx, device, false, true)
+	if err != nil {
+		return nil, err
+	}
+	var secondary *signingKey
+	if device.Secondary != nil {
+		resolved, err := s.resolveSigner(ctx, device, true, true)
+		if err != nil {
+			return nil, err
+		}
+		secondary = &resolved
+	}
+	var jws *jwsSigner
+	var cose *coseSigner
+	switch input.Format {
+	case domain.FormatJWS:
+		jws, err = s.jwsSignerFor(ctx, device)
+	case domain.FormatCOSE:
+		cose, err = s.coseSignerFor(ctx, device)
+	}
 	if err != nil {
-		return nil, fmt.Errorf("resolve signer: %w", err)
+		return nil, err
 	}
 	s.signMX.Lock()
 	defer s.signMX.Unlock()
@@ -141,6 +489,8 @@ func (s *Service) SignTransaction(ctx context.Context, input SignTransactionInpu
 		reference = device.ID[:]
 		counter = 0
 	} else {
+		// The chain references the previous signature exactly as stored, in
+		// the encoding recorded with it.
 		decoded, decodeErr := base64.StdEncoding.DecodeString(prevRecord.Signature)
 		if decodeErr != nil {
 			return nil, fmt.Errorf("decode previous signature: %w", decodeErr)
@@ -151,7 +501,10 @@ func (s *Service) SignTransaction(ctx context.Context, input SignTransactionInpu
 
 	signedData := domain.BuildSecuredPayload(counter+1, input.Data, reference)
 
-	signatureBytes, err := signer.Sign([]byte(signedData))
+	signatureBytes, err := s.signAndVerify(device.ID, signer, []byte(signedData))
+	if errors.Is(err, errFaultySignature) {
+		return nil, err
+	}
 	if err != nil {
 		return nil, fmt.Errorf("sign payload: %w", err)
 	}
@@ -160,8 +513,39 @@ func (s *Service) SignTransaction(ctx context.Context, input SignTransactionInpu
 	record := SignatureRecord{
 		Signature:  encodedSignature,
 		SignedData: signedData,
+		Scheme:     scheme,
+		Encoding:   device.Encoding,
+		KeyVersion: device.KeyVersion,
 		CreatedAt:  s.clock().UTC(),
 	}
+	if secondary != nil {
+		// Both keys sign the same payload; the chain continues from the primary signature.
+		secondaryBytes, err := s.signAndVerify(device.ID, *secondary, []byte(signedData))
+		if errors.Is(err, errFaultySignature) {
+			return nil, err
+		}
+		if err != nil {
+			return nil, fmt.Errorf("sign payload with secondary key: %w", err)
+		}
+		record.SecondarySignature = base64.StdEncoding.EncodeToString(secondaryBytes)
+	}
+	if jws != nil {
+		if record.JWS, err = jws.sign(counter+1, signedData); err != nil {
+			return nil, err
+		}
+	}
+	if cose != nil {
+		if record.COSE, err = cose.sign(counter+1, reference, signedData); err != nil {
+			return nil, err
+		}
+	}
+	if s.timestamper != nil {
+		// The token is obtained before Append, so a failing TSA leaves the counter untouched.
+		digest := sha256.Sum256(signatureBytes)
+		if record.Timestamp, err = s.timestamper.Timestamp(ctx, digest[:]); err != nil {
+			return nil, fmt.Errorf("timestamp signature: %w", err)
+		}
+	}
 
 	storedRecord, err := s.signatureStore.Append(ctx, device.ID, record)
 	if err != nil {
@@ -172,14 +556,437 @@ func (s *Service) SignTransaction(ctx context.Context, input SignTransactionInpu
 		Signature:    storedRecord.Signature,
 		SignedData:   storedRecord.SignedData,
 		CounterValue: storedRecord.Counter,
+		Scheme:       storedRecord.Scheme,
+		Encoding:     storedRecord.Encoding,
+		KeyVersion:   storedRecord.KeyVersion,
+		JWS:          storedRecord.JWS,
+		COSE:         storedRecord.COSE,
+		Timestamp:    storedRecord.Timestamp,
+
+		SecondarySignature: storedRecord.SecondarySignature,
 	}, nil
 }
 
+// resolveEncoding picks the signature encoding for one operation: the
+// requested one if set, otherwise the device default.
+func (s *Service) resolveEncoding(device domain.Device, requested domain.SignatureEncoding) (domain.SignatureEncoding, error) {
+	if requested == "" {
+		requested = device.Encoding
+	}
+	return domain.ResolveEncoding(s.algorithms, device.Algorithm, requested)
+}
+
+// signerFor returns the signer for the current key version and the signature
+// encoding of a device, decoding the private key only on a cache miss.
+func (s *Service) signerFor(ctx context.Context, device domain.Device) (Signer, error) {
+	key, err := s.resolveSigner(ctx, device, false, false)
+	return key.signer, err
+}
+
+// resolveSigner returns the signer of the current key version of a device, or
+// of its secondary key, decoding the key material only on a cache miss. With
+// verified set the matching verifier is resolved and cached as well.
+func (s *Service) resolveSigner(ctx context.Context, device domain.Device, secondary, verified bool) (signingKey, error) {
+	signing := device
+	if secondary {
+		view, ok := device.SecondaryDevice()
+		if !ok {
+			return signingKey{}, errNoSecondaryKey
+		}
+		signing = view
+	}
+	key := signerKey{deviceID: device.ID, version: device.KeyVersion, encoding: signing.Encoding, secondary: secondary}
+	cached, epoch, ok := s.signers.get(key)
+	if ok && (cached.verifier != nil || !verified) {
+		return cached, nil
+	}
+
+	material, err := s.loadKey(ctx, device, device.KeyVersion)
+	if err != nil {
+		return signingKey{}, err
+	}
+	if secondary {
+		if material.Secondary == nil {
+			return signingKey{}, domain.ErrKeyMaterialMissing
+		}
+		material = *material.Secondary
+	}
+	signer, err := s.signerFactory.SignerFor(signing, material)
+	if err != nil {
+		return signingKey{}, fmt.Errorf("resolve signer: %w", err)
+	}
+	resolved := signingKey{signer: signer}
+	if verified {
+		if resolved.verifier, err = s.signerFactory.VerifierFor(signing, material); err != nil {
+			return signingKey{}, fmt.Errorf("resolve verifier: %w", err)
+		}
+	}
+	s.signers.put(key, resolved, epoch)
+	return resolved, nil
+}
+
+// signAndVerify signs payload and checks the signature against the public key
+// before it is used, so a faulty signature (e.g. an RSA-CRT fault or corrupted
+// key material) never enters the chain. A failed check drops the cached
+// signers of the device, forcing the key material to be decoded again.
+func (s *Service) signAndVerify(deviceID uuid.UUID, key signingKey, payload []byte) ([]byte, error) {
+	signature, err := key.signer.Sign(payload)
+	if err != nil {
+		return nil, err
+	}
+	valid, err := key.verifier.Verify(payload, signature)
+	if err != nil || !valid {
+		s.signers.invalidate(deviceID)
+		return nil, errFaultySignature
+	}
+	return signature, nil
+}
+
+// VerifySignatureInput carries a signed payload and its base64 encoded signature.
+type VerifySignatureInput struct {
+	DeviceID   uuid.UUID
+	SignedData string
+	Signature  string
+	// KeyVersion selects the device key to verify with; zero uses the current key.
+	KeyVersion int
+	// Encoding names the signature encoding; empty uses the device default.
+	Encoding domain.SignatureEncoding
+	// Secondary checks the signature against the secondary key of a hybrid device.
+	Secondary bool
+}
+
+// VerificationResult reports the outcome of a signature check.
+type VerificationResult struct {
+	Valid      bool
+	KeyVersion int
+}
+
+// VerifySignature checks a signature against one version of the device public key.
+func (s *Service) VerifySignature(ctx context.Context, input VerifySignatureInput) (*VerificationResult, error) {
+	if s == nil {
+		return nil, errors.New("device service is nil")
+	}
+
+	if input.SignedData == "" {
+		return nil, domain.ValidationError{Field: "signed_data", Message: "signed data is required"}
+	}
+	signature, err := base64.StdEncoding.DecodeString(input.Signature)
+	if err != nil || len(signature) == 0 {
+		return nil, domain.ValidationError{Field: "signature", Message: "signature must be non-empty base64"}
+	}
+
+	device, err := s.repo.Get(ctx, input.DeviceID)
+	if err != nil {
+		return nil, err
+	}
+	verifying := device
+	if input.Secondary {
+		view, ok := device.SecondaryDevice()
+		if !ok {
+			return nil, errNoSecondaryKey
+		}
+		verifying = view
+	}
+
+	verifying.Encoding, err = s.resolveEncoding(verifying, input.Encoding)
+	if err != nil {
+		return nil, err
+	}
+
+	version := input.KeyVersion
+	if version == 0 {
+		version = device.KeyVersion
+	}
+	material, err := s.loadKey(ctx, device, version)
+	if err != nil {
+		return nil, err
+	}
+	if input.Secondary {
+		if material.Secondary == nil {
+			return nil, domain.ErrKeyMaterialMissing
+		}
+		material = *material.Secondary
+	}
+
+	verifier, err := s.signerFactory.VerifierFor(verifying, material)
+	if err != nil {
+		return nil, fmt.Errorf("resolve verifier: %w", err)
+	}
+
+	valid, err := verifier.Verify([]byte(input.SignedData), signature)
+	if err != nil {
+		return nil, fmt.Errorf("verify signature: %w", err)
+	}
+
+	return &VerificationResult{Valid: valid, KeyVersion: version}, nil
+}
+
+// PublicKeyResult bundles a device with one of its exported public keys.
+type PublicKeyResult struct {
+	Device     domain.Device
+	KeyVersion int
+	Key        PublicKeyExport
+	Secondary  *PublicKeyExport // Public key of the secondary key of hybrid devices.
+}
+
+// GetPublicKey exports a public key of a device. Version zero selects the
+// current key; earlier versions stay available after rotation.
+func (s *Service) GetPublicKey(ctx context.Context, id uuid.UUID, version int) (*PublicKeyResult, error) {
+	if s == nil {
+		return nil, errors.New("device service is nil")
+	}
+	if s.keyExporter == nil {
+		return nil, domain.InternalError{Reason: "public key export not configured"}
+	}
+
+	device, err := s.repo.Get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if err := domain.RequirePublicKey(s.algorithms, device.Algorithm); err != nil {
+		return nil, err
+	}
+
+	if version == 0 {
+		version = device.KeyVersion
+	}
+	material, err := s.loadKey(ctx, device, version)
+	if err != nil {
+		return nil, err
+	}
+
+	exported, err := s.keyExporter.ExportPublicKey(device, material)
+	if err != nil {
+		return nil, fmt.Errorf("export public key: %w", err)
+	}
+	result := &PublicKeyResult{Device: device, KeyVersion: version, Key: exported}
+
+	if view, ok := device.SecondaryDevice(); ok {
+		if material.Secondary == nil {
+			return nil, domain.ErrKeyMaterialMissing
+		}
+		secondary, err := s.keyExporter.ExportPublicKey(view, *material.Secondary)
+		if err != nil {
+			return nil, fmt.Errorf("export secondary public key: %w", err)
+		}
+		result.Secondary = &secondary
+	}
+	return result, nil
+}
+
+// RotateKey replaces the signing key of a device with a freshly generated one
+// of the same algorithm and key spec, together with the secondary key of hybrid
+// devices. The device ID, signature counter and chain are kept; earlier key
+// versions remain available for verification.
+func (s *Service) RotateKey(ctx context.Context, id uuid.UUID) (domain.Device, error) {
+	if s == nil {
+		return domain.Device{}, errors.New("device service is nil")
+	}
+	s.updateMX.Lock()
+	defer s.updateMX.Unlock()
+
+	device, err := s.repo.Get(ctx, id)
+	if err != nil {
+		return domain.Device{}, err
+	}
+
+	keys, err := s.generateKeys(device)
+	if err != nil {
+		return domain.Device{}, err
+	}
+
+	next := device.KeyVersion + 1
+	if err := s.certify(ctx, device, next, keys); err != nil {
+		s.release(keys)
+		return domain.Device{}, err
+	}
+	if err := s.keyStore.Store(ctx, device.ID, next, keys); err != nil {
+		s.rollbackRotation(ctx, device.ID, next, keys, false)
+		return domain.Device{}, fmt.Errorf("store key material: %w", err)
+	}
+
+	rotated := device.WithKeyVersion(next, s.clock().UTC())
+	if err := s.repo.Update(ctx, rotated); err != nil {
+		s.rollbackRotation(ctx, device.ID, next, keys, true)
+		return domain.Device{}, err
+	}
+	s.signers.invalidate(device.ID)
+
+	return rotated, nil
+}
+
+// rollbackRotation undoes a rotation that failed after certify: it drops the
+// certificate of version and, if stored, the key version itself, so the device
+// never shows a key it did not switch to and the next rotation does not reuse
+// the slot. Cleanup errors are ignored in favour of the original failure.
+func (s *Service) rollbackRotation(ctx context.Context, deviceID uuid.UUID, version int, keys domain.KeyMaterial, stored bool) {
+	if stored {
+		_ = s.keyStore.DeleteVersion(ctx, deviceID, version)
+	}
+	if s.certificates != nil {
+		_ = s.certificates.DeleteVersion(ctx, deviceID, version)
+	}
+	s.release(keys)
+}
+
+// CertificateResult bundles a device with the certificate chain of one key version.
+type CertificateResult struct {
+	Device     domain.Device
+	KeyVersion int
+	Chain      [][]byte // DER certificates, leaf first.
+}
+
+// GetCertificate returns the certificate chain of a device key version. Version
+// zero selects the current key.
+func (s *Service) GetCertificate(ctx context.Context, id uuid.UUID, version int) (*CertificateResult, error) {
+	if s == nil {
+		return nil, errors.New("device service is nil")
+	}
+	if s.certificates == nil {
+		return nil, domain.InternalError{Reason: "certificate store not configured"}
+	}
+
+	device, err := s.repo.Get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if version == 0 {
+		version = device.KeyVersion
+	}
+	if err := checkKeyVersion(device, version); err != nil {
+		return nil, err
+	}
+	chain, err := s.certificates.Get(ctx, device.ID, version)
+	if err != nil {
+		return nil, err
+	}
+
+	return &CertificateResult{Device: device, KeyVersion: version, Chain: chain}, nil
+}
+
+// CertificateRequestResult bundles a device with a certificate request for one key version.
+type CertificateRequestResult struct {
+	Device     domain.Device
+	KeyVersion int
+	Request    []byte // DER encoded PKCS#10 request.
+}
+
+// CreateCertificateRequest returns a PKCS#10 request for the current device
+// key, signed by that key, so an external CA can certify it.
+func (s *Service) CreateCertificateRequest(ctx context.Context, id uuid.UUID) (*CertificateRequestResult, error) {
+	if s == nil {
+		return nil, errors.New("device service is nil")
+	}
+	if s.requester == nil {
+		return nil, domain.InternalError{Reason: "certificate requests not configured"}
+	}
+
+	device, err := s.repo.Get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if err := domain.RequirePublicKey(s.algorithms, device.Algorithm); err != nil {
+		return nil, err
+	}
+	material, err := s.loadKey(ctx, device, device.KeyVersion)
+	if err != nil {
+		return nil, err
+	}
+
+	// PKCS#10 carries DER signatures whatever the device default encoding is.
+	signing := device
+	if signing.Encoding != "" {
+		signing.Encoding = domain.EncodingDER
+	}
+	signer, err := s.signerFor(ctx, signing)
+	if err != nil {
+		return nil, err
+	}
+	request, err := s.requester.CreateRequest(signing, material.Public, signer)
+	if err != nil {
+		return nil, fmt.Errorf("create certificate request: %w", err)
+	}
+
+	return &CertificateRequestResult{Device: device, KeyVersion: device.KeyVersion, Request: request}, nil
+}
+
+// UploadCertificate stores a PEM chain issued by an external CA for the
+// current device key, replacing any chain held for that key version. The leaf
+// must certify the device public key.
+func (s *Service) UploadCertificate(ctx context.Context, id uuid.UUID, chainPEM []byte) (*CertificateResult, error) {
+	if s == nil {
+		return nil, errors.New("device service is nil")
+	}
+	if s.requester == nil {
+		return nil, domain.InternalError{Reason: "certificate requests not configured"}
+	}
+	// Hold off key rotation so the chain is stored for the key it was checked against.
+	s.updateMX.Lock()
+	defer s.updateMX.Unlock()
+
+	device, err := s.repo.Get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if err := domain.RequirePublicKey(s.algorithms, device.Algorithm); err != nil {
+		return nil, err
+	}
+	material, err := s.loadKey(ctx, device, device.KeyVersion)
+	if err != nil {
+		return nil, err
+	}
+
+	chain, err := s.requester.CheckChain(material.Public, chainPEM)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.certificates.Put(ctx, device.ID, device.KeyVersion, chain); err != nil {
+		return nil, fmt.Errorf("store certificate: %w", err)
+	}
+
+	return &CertificateResult{Device: device, KeyVersion: device.KeyVersion, Chain: chain}, nil
+}
+
+// GetRootCertificate returns the DER encoded root certificate of the service CA.
+func (s *Service) GetRootCertificate(_ context.Context) ([]byte, error) {
+	if s == nil {
+		return nil, errors.New("device service is nil")
+	}
+	if s.issuer == nil {
+		return nil, domain.InternalError{Reason: "certificate authority not configured"}
+	}
+	return s.issuer.Root(), nil
+}
+
+// checkKeyVersion reports versions the device never had as not found.
+func checkKeyVersion(device domain.Device, version int) error {
+	if version < domain.InitialKeyVersion || version > device.KeyVersion {
+		return domain.NotFoundError{Resource: "key version", ID: strconv.Itoa(version)}
+	}
+	return nil
+}
+
+// loadKey fetches one key version of a device, reporting versions the device
+// never had as not found.
+func (s *Service) loadKey(ctx context.Context, device domain.Device, version int) (domain.KeyMaterial, error) {
+	if err := checkKeyVersion(device, version); err != nil {
+		return domain.KeyMaterial{}, err
+	}
+	material, err := s.keyStore.Load(ctx, device.ID, version)
+	if err != nil {
+		return domain.KeyMaterial{}, fmt.Errorf("load key material: %w", err)
+	}
+	return material, nil
+}
+
 // UpdateDeviceLabel updates the display label of an existing device.
 func (s *Service) UpdateDeviceLabel(ctx context.Context, id uuid.UUID, label string) (domain.Device, error) {
 	if s == nil {
 		return domain.Device{}, errors.New("device service is nil")
 	}
+	s.updateMX.Lock()
+	defer s.updateMX.Unlock()
 
 	device, err := s.repo.Get(ctx, id)
 	if err != nil {
@@ -245,6 +1052,12 @@ func (s *Service) DeleteDevice(ctx context.Context, id uuid.UUID) error {
 	if err := s.keyStore.Delete(ctx, id); err != nil {
 		return err
 	}
+	s.signers.invalidate(id)
+	if s.certificates != nil {
+		if err := s.certificates.Delete(ctx, id); err != nil {
+			return err
+		}
+	}
 	s.signMX.Lock()
 	defer s.signMX.Unlock()
 	if err := s.signatureStore.Delete(ctx, id); err != nil {
@@ -271,6 +1084,73 @@ func (s *Service) GetSignature(ctx context.Context, deviceID uuid.UUID, counter
 	return s.signatureStore.Get(ctx, deviceID, counter)
 }
 
+// GetSignatureCOSE returns the COSE_Sign1 message stored with a signature.
+// Messages are only produced at sign time; records signed without COSE output
+// yield a NotFoundError.
+func (s *Service) GetSignatureCOSE(ctx context.Context, deviceID uuid.UUID, counter uint64) ([]byte, error) {
+	if s == nil {
+		return nil, errors.New("device service is nil")
+	}
+
+	record, err := s.signatureStore.Get(ctx, deviceID, counter)
+	if err != nil {
+		return nil, err
+	}
+	if len(record.COSE) == 0 {
+		return nil, domain.NotFoundError{Resource: "COSE_Sign1 message", ID: fmt.Sprintf("%s#%d", deviceID.String(), counter)}
+	}
+	return record.COSE, nil
+}
+
+// GetSignatureCMS returns a stored signature as detached CMS SignedData whose
+// content is the secured payload of the record. The signature is embedded as
+// stored, together with the certificate chain of the key version that made it.
+func (s *Service) GetSignatureCMS(ctx context.Context, deviceID uuid.UUID, counter uint64) ([]byte, error) {
+	if s == nil {
+		return nil, errors.New("device service is nil")
+	}
+	if s.cms == nil {
+		return nil, domain.InternalError{Reason: "CMS export not configured"}
+	}
+
+	record, err := s.signatureStore.Get(ctx, deviceID, counter)
+	if err != nil {
+		return nil, err
+	}
+	device, err := s.repo.Get(ctx, deviceID)
+	if err != nil {
+		return nil, err
+	}
+	if err := domain.RequirePublicKey(s.algorithms, device.Algorithm); err != nil {
+		return nil, err
+	}
+	if record.KeyVersion != 0 {
+		device.KeyVersion = record.KeyVersion
+	}
+	if record.Scheme != "" {
+		device.Scheme = record.Scheme
+	}
+	device.Encoding = record.Encoding
+
+	material, err := s.loadKey(ctx, device, device.KeyVersion)
+	if err != nil {
+		return nil, err
+	}
+	var chain [][]byte
+	if s.certificates != nil {
+		chain, err = s.certificates.Get(ctx, deviceID, device.KeyVersion)
+		var notFound domain.NotFoundError
+		if err != nil && !errors.As(err, &notFound) {
+			return nil, err
+		}
+	}
+	signature, err := base64.StdEncoding.DecodeString(record.Signature)
+	if err != nil {
+		return nil, fmt.Errorf("decode signature: %w", err)
+	}
+	return s.cms.EncodeSignedData(device, material.Public, chain, signature)
+}
+
 // LoggingService decorates a Service with structured logging callbacks.
 type LoggingService struct {
 	inner  *Service
@@ -290,7 +1170,7 @@ func (l *LoggingService) log(event string, fields map[string]interface{}) {
 
 // CreateDevice proxies to the wrapped service while emitting log hooks.
 func (l *LoggingService) CreateDevice(ctx context.Context, input CreateDeviceInput) (*CreateDeviceResult, error) {
-	l.log("device.create", map[string]interface{}{"id": input.ID, "algorithm": input.Algorithm})
+	l.log("device.create", map[string]interface{}{"id": input.ID, "algorithm": input.Algorithm, "key_spec": input.KeySpec, "scheme": input.Scheme, "digest": input.Digest, "encoding": input.Encoding})
 	result, err := l.inner.CreateDevice(ctx, input)
 	if err != nil {
 		l.log("device.create.error", map[string]interface{}{"id": input.ID, "error": err.Error()})
@@ -298,6 +1178,16 @@ func (l *LoggingService) CreateDevice(ctx context.Context, input CreateDeviceInp
 	return result, err
 }
 
+// ImportDevice proxies key imports and adds log events. The private key is never logged.
+func (l *LoggingService) ImportDevice(ctx context.Context, input ImportDeviceInput) (*CreateDeviceResult, error) {
+	l.log("device.import", map[string]interface{}{"id": input.ID, "algorithm": input.Algorithm, "scheme": input.Scheme, "digest": input.Digest, "encoding": input.Encoding})
+	result, err := l.inner.ImportDevice(ctx, input)
+	if err != nil {
+		l.log("device.import.error", map[string]interface{}{"id": input.ID, "error": err.Error()})
+	}
+	return result, err
+}
+
 // SignTransaction proxies signing calls and adds log events.
 func (l *LoggingService) SignTransaction(ctx context.Context, input SignTransactionInput) (*SignatureResult, error) {
 	l.log("device.sign", map[string]interface{}{"id": input.DeviceID})
@@ -308,6 +1198,71 @@ func (l *LoggingService) SignTransaction(ctx context.Context, input SignTransact
 	return result, err
 }
 
+// VerifySignature proxies verification calls and logs failures.
+func (l *LoggingService) VerifySignature(ctx context.Context, input VerifySignatureInput) (*VerificationResult, error) {
+	result, err := l.inner.VerifySignature(ctx, input)
+	if err != nil {
+		l.log("device.verify.error", map[string]interface{}{"id": input.DeviceID, "error": err.Error()})
+	}
+	return result, err
+}
+
+// GetPublicKey logs public key export failures.
+func (l *LoggingService) GetPublicKey(ctx context.Context, id uuid.UUID, version int) (*PublicKeyResult, error) {
+	result, err := l.inner.GetPublicKey(ctx, id, version)
+	if err != nil {
+		l.log("device.public_key.error", map[string]interface{}{"id": id, "key_version": version, "error": err.Error()})
+	}
+	return result, err
+}
+
+// GetCertificate logs certificate retrieval failures.
+func (l *LoggingService) GetCertificate(ctx context.Context, id uuid.UUID, version int) (*CertificateResult, error) {
+	result, err := l.inner.GetCertificate(ctx, id, version)
+	if err != nil {
+		l.log("device.certificate.error", map[string]interface{}{"id": id, "key_version": version, "error": err.Error()})
+	}
+	return result, err
+}
+
+// CreateCertificateRequest logs certificate request failures.
+func (l *LoggingService) CreateCertificateRequest(ctx context.Context, id uuid.UUID) (*CertificateRequestResult, error) {
+	result, err := l.inner.CreateCertificateRequest(ctx, id)
+	if err != nil {
+		l.log("device.csr.error", map[string]interface{}{"id": id, "error": err.Error()})
+	}
+	return result, err
+}
+
+// UploadCertificate proxies to the wrapped service while emitting log hooks.
+func (l *LoggingService) UploadCertificate(ctx context.Context, id uuid.UUID, chainPEM []byte) (*CertificateResult, error) {
+	l.log("device.certificate.upload", map[string]interface{}{"id": id})
+	result, err := l.inner.UploadCertificate(ctx, id, chainPEM)
+	if err != nil {
+		l.log("device.certificate.upload.error", map[string]interface{}{"id": id, "error": err.Error()})
+	}
+	return result, err
+}
+
+// GetRootCertificate logs failures to serve the root certificate.
+func (l *LoggingService) GetRootCertificate(ctx context.Context) ([]byte, error) {
+	root, err := l.inner.GetRootCertificate(ctx)
+	if err != nil {
+		l.log("ca.root.error", map[string]interface{}{"error": err.Error()})
+	}
+	return root, err
+}
+
+// RotateKey logs key rotations and their failures.
+func (l *LoggingService) RotateKey(ctx context.Context, id uuid.UUID) (domain.Device, error) {
+	l.log("device.rotate_key", map[string]interface{}{"id": id})
+	device, err := l.inner.RotateKey(ctx, id)
+	if err != nil {
+		l.log("device.rotate_key.error", map[string]interface{}{"id": id, "error": err.Error()})
+	}
+	return device, err
+}
+
 // UpdateDeviceLabel wraps the service update call with logging.
 func (l *LoggingService) UpdateDeviceLabel(ctx context.Context, id uuid.UUID, label string) (domain.Device, error) {
 	l.log("device.update", map[string]interface{}{"id": id})
@@ -372,3 +1327,21 @@ func (l *LoggingService) GetSignature(ctx context.Context, deviceID uuid.UUID, c
 	}
 	return record, err
 }
+
+// GetSignatureCMS logs failures to export a signature as CMS.
+func (l *LoggingService) GetSignatureCMS(ctx context.Context, deviceID uuid.UUID, counter uint64) ([]byte, error) {
+	signedData, err := l.inner.GetSignatureCMS(ctx, deviceID, counter)
+	if err != nil {
+		l.log("signature.cms.error", map[string]interface{}{"device_id": deviceID, "counter": counter, "error": err.Error()})
+	}
+	return signedData, err
+}
+
+// GetSignatureCOSE logs failures to serve a signature as COSE_Sign1.
+func (l *LoggingService) GetSignatureCOSE(ctx context.Context, deviceID uuid.UUID, counter uint64) ([]byte, error) {
+	message, err := l.inner.GetSignatureCOSE(ctx, deviceID, counter)
+	if err != nil {
+		l.log("signature.cose.error", map[string]interface{}{"device_id": deviceID, "counter": counter, "error": err.Error()})
+	}
+	return message, err
+}
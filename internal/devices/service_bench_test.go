@@ -0,0 +1,100 @@
+package devices_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/fiskaly/coding-challenges/signing-service-challenge/domain"
+	"github.com/fiskaly/coding-challenges/signing-service-challenge/internal/devices"
+	"github.com/fiskaly/coding-challenges/signing-service-challenge/internal/persistence/inmemory"
+	"github.com/fiskaly/coding-challenges/signing-service-challenge/pkg/crypto"
+	"github.com/google/uuid"
+)
+
+// BenchmarkSignTransaction compares signing with and without the signer cache
+// against the in-memory stores and real crypto providers. Run with
+// `go test -bench SignTransaction ./internal/devices`.
+func BenchmarkSignTransaction(b *testing.B) {
+	specs := []struct {
+		algorithm domain.Algorithm
+		spec      domain.KeySpec
+	}{
+		{domain.AlgorithmRSA, domain.KeySpecRSA2048},
+		{domain.AlgorithmECDSA, domain.KeySpecP256},
+		{domain.AlgorithmEd25519, domain.KeySpecEd25519},
+	}
+	caches := []struct {
+		name string
+		size int
+	}{
+		{"uncached", 0},
+		{"cached", devices.DefaultSignerCacheSize},
+	}
+
+	for _, spec := range specs {
+		for _, cache := range caches {
+			b.Run(string(spec.spec)+"/"+cache.name, func(b *testing.B) {
+				service := devices.NewService(
+					crypto.DefaultRegistry(),
+					inmemory.NewDeviceRepository(),
+					inmemory.NewKeyStore(),
+					crypto.NewDefaultKeyGenerator(),
+					crypto.NewSignerFactory(),
+					inmemory.NewSignatureStore(),
+				)
+				service.WithSignerCacheSize(cache.size)
+
+				id := uuid.New()
+				_, err := service.CreateDevice(context.Background(), devices.CreateDeviceInput{ID: id, Algorithm: spec.algorithm, KeySpec: spec.spec})
+				if err != nil {
+					b.Fatalf("create device: %v", err)
+				}
+				input := devices.SignTransactionInput{DeviceID: id, Data: "benchmark payload"}
+
+				b.ReportAllocs()
+				b.ResetTimer()
+				for i := 0; i < b.N; i++ {
+					if _, err := service.SignTransaction(context.Background(), input); err != nil {
+						b.Fatalf("sign: %v", err)
+					}
+				}
+			})
+		}
+	}
+}
+
+// BenchmarkSignTransactionParallel measures cache contention with many
+// devices signing concurrently.
+func BenchmarkSignTransactionParallel(b *testing.B) {
+	service := devices.NewService(
+		crypto.DefaultRegistry(),
+		inmemory.NewDeviceRepository(),
+		inmemory.NewKeyStore(),
+		crypto.NewDefaultKeyGenerator(),
+		crypto.NewSignerFactory(),
+		inmemory.NewSignatureStore(),
+	)
+
+	ids := make([]uuid.UUID, 64)
+	for i := range ids {
+		ids[i] = uuid.New()
+		_, err := service.CreateDevice(context.Background(), devices.CreateDeviceInput{ID: ids[i], Algorithm: domain.AlgorithmEd25519})
+		if err != nil {
+			b.Fatalf("create device: %v", err)
+		}
+	}
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	b.RunParallel(func(pb *testing.PB) {
+		i := 0
+		for pb.Next() {
+			input := devices.SignTransactionInput{DeviceID: ids[i%len(ids)], Data: "benchmark payload"}
+			if _, err := service.SignTransaction(context.Background(), input); err != nil {
+				b.Error(err)
+				return
+			}
+			i++
+		}
+	})
+}
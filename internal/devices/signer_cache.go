@@ -0,0 +1,110 @@
+package devices
+
+import (
+	"container/list"
+	"sync"
+
+	"github.com/fiskaly/coding-challenges/signing-service-challenge/domain"
+	"github.com/google/uuid"
+)
+
+// DefaultSignerCacheSize is the number of decoded signers kept per service
+// unless overridden via WithSignerCacheSize.
+const DefaultSignerCacheSize = 1024
+
+type signerKey struct {
+	deviceID  uuid.UUID
+	version   int
+	encoding  domain.SignatureEncoding
+	secondary bool // signer of the secondary key of a hybrid device
+}
+
+// signingKey pairs the signer of a key version with the verifier fresh
+// signatures are checked against before they are stored.
+type signingKey struct {
+	signer   Signer
+	verifier Verifier
+}
+
+type signerEntry struct {
+	key    signerKey
+	signer signingKey
+}
+
+// signerCache is a bounded LRU of signers and their verifiers keyed by device,
+// key version and signature encoding, so hot devices skip loading and parsing
+// their key material on every signature.
+// A nil cache is valid and caches nothing.
+type signerCache struct {
+	mu       sync.Mutex
+	capacity int
+	entries  map[signerKey]*list.Element
+	order    *list.List // front is most recently used
+	epoch    uint64     // bumped on invalidation to drop signers resolved before it
+}
+
+func newSignerCache(capacity int) *signerCache {
+	return &signerCache{
+		capacity: capacity,
+		entries:  make(map[signerKey]*list.Element),
+		order:    list.New(),
+	}
+}
+
+// get returns the cached signer, or the current epoch to pass to put on a miss.
+func (c *signerCache) get(key signerKey) (signingKey, uint64, bool) {
+	if c == nil {
+		return signingKey{}, 0, false
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	element, ok := c.entries[key]
+	if !ok {
+		return signingKey{}, c.epoch, false
+	}
+	c.order.MoveToFront(element)
+	return element.Value.(*signerEntry).signer, c.epoch, true
+}
+
+// put caches a signer resolved during epoch. Signers resolved before an
+// invalidation are discarded, as their device may have been deleted meanwhile.
+func (c *signerCache) put(key signerKey, signer signingKey, epoch uint64) {
+	if c == nil {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if epoch != c.epoch {
+		return
+	}
+	if element, ok := c.entries[key]; ok {
+		element.Value.(*signerEntry).signer = signer
+		c.order.MoveToFront(element)
+		return
+	}
+	c.entries[key] = c.order.PushFront(&signerEntry{key: key, signer: signer})
+	for c.order.Len() > c.capacity {
+		oldest := c.order.Back()
+		c.order.Remove(oldest)
+		delete(c.entries, oldest.Value.(*signerEntry).key)
+	}
+}
+
+// invalidate drops every cached key version and encoding of a device.
+func (c *signerCache) invalidate(deviceID uuid.UUID) {
+	if c == nil {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.epoch++
+	for key, element := range c.entries {
+		if key.deviceID == deviceID {
+			c.order.Remove(element)
+			delete(c.entries, key)
+		}
+	}
+}
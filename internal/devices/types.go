@@ -1,16 +1,46 @@
 package devices
 
-import "time"
+import (
+	"time"
+
+	"github.com/fiskaly/coding-challenges/signing-service-challenge/domain"
+)
 
 // SignatureRecord represents a stored signature for a device.
 type SignatureRecord struct {
 	Counter    uint64
 	Signature  string
 	SignedData string
+	Scheme     domain.SignatureScheme
+	Encoding   domain.SignatureEncoding // Layout of Signature; empty for algorithms with a single layout.
+	KeyVersion int                      // Device key version that produced the signature.
+	JWS        string                   // Compact JWS over SignedData; empty unless requested.
+	COSE       []byte                   // COSE_Sign1 message over SignedData; empty unless requested.
+	Timestamp  []byte                   // DER RFC 3161 TimeStampToken over the SHA-256 of the signature bytes.
 	CreatedAt  time.Time
+
+	// SecondarySignature is the base64 signature of the secondary key of
+	// hybrid devices over SignedData; empty for single-key devices.
+	SecondarySignature string
 }
 
 // Clone returns a copy to avoid leaking pointers.
 func (r SignatureRecord) Clone() SignatureRecord {
-	return r
+	clone := r
+	clone.COSE = append([]byte(nil), r.COSE...)
+	clone.Timestamp = append([]byte(nil), r.Timestamp...)
+	return clone
+}
+
+// PublicKeyExport carries a device public key in interchange formats.
+type PublicKeyExport struct {
+	SPKI  []byte            // DER encoded SubjectPublicKeyInfo.
+	JWK   map[string]string // JSON Web Key members including "kid".
+	KeyID string            // RFC 7638 JWK thumbprint.
+}
+
+// ImportedKey is key material parsed from a caller-supplied private key.
+type ImportedKey struct {
+	KeySpec  domain.KeySpec
+	Material domain.KeyMaterial
 }
@@ -0,0 +1,3 @@
+// Package envelope decorates key stores so private keys are encrypted at rest
+// with per-device data keys wrapped by a rotating key-encryption key (KEK).
+package envelope
@@ -0,0 +1,167 @@
+package envelope
+
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+)
+
+// KEKSize is the required key-encryption key length (AES-256).
+const KEKSize = 32
+
+// KEK is a key-encryption key identified by a stable ID recorded in every envelope it wraps.
+type KEK struct {
+	ID  string
+	Key []byte
+}
+
+// Keyring holds the primary KEK used for wrapping plus previous KEKs that are
+// still accepted for unwrapping until every envelope has been re-wrapped.
+type Keyring struct {
+	mu      sync.RWMutex
+	primary string
+	keys    map[string][]byte
+	// retired holds KEKs dropped by Replace; they still unwrap until Prune.
+	retired map[string][]byte
+}
+
+// NewKeyring builds a keyring with primary as the wrapping key.
+func NewKeyring(primary KEK, previous ...KEK) (*Keyring, error) {
+	keyring := &Keyring{}
+	if err := keyring.Set(append([]KEK{primary}, previous...)...); err != nil {
+		return nil, err
+	}
+	return keyring, nil
+}
+
+// ParseKeyring reads "id:base64key" entries separated by commas or newlines.
+// The first entry becomes the primary KEK. Blank lines and lines starting with
+// '#' are ignored.
+func ParseKeyring(spec string) (*Keyring, error) {
+	keys, err := parseKEKs(spec)
+	if err != nil {
+		return nil, err
+	}
+	keyring := &Keyring{}
+	if err := keyring.Set(keys...); err != nil {
+		return nil, err
+	}
+	return keyring, nil
+}
+
+// LoadKeyringFile reads a keyring in ParseKeyring format from path.
+func LoadKeyringFile(path string) (*Keyring, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read keyring: %w", err)
+	}
+	return ParseKeyring(string(content))
+}
+
+// Set replaces the keyring contents; the first KEK becomes primary.
+func (k *Keyring) Set(keks ...KEK) error {
+	if len(keks) == 0 {
+		return errors.New("keyring needs at least one KEK")
+	}
+	keys := make(map[string][]byte, len(keks))
+	for _, kek := range keks {
+		if kek.ID == "" || strings.ContainsAny(kek.ID, ":,\r\n") {
+			return fmt.Errorf("invalid KEK id %q", kek.ID)
+		}
+		if len(kek.Key) != KEKSize {
+			return fmt.Errorf("KEK %s must be %d bytes, got %d", kek.ID, KEKSize, len(kek.Key))
+		}
+		if _, exists := keys[kek.ID]; exists {
+			return fmt.Errorf("duplicate KEK id %s", kek.ID)
+		}
+		keys[kek.ID] = append([]byte(nil), kek.Key...)
+	}
+
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	k.primary = keks[0].ID
+	k.keys = keys
+	return nil
+}
+
+// Rotate makes kek the primary KEK while keeping the current KEKs for unwrapping.
+func (k *Keyring) Rotate(kek KEK) error {
+	k.mu.RLock()
+	keks := []KEK{kek}
+	for id, key := range k.keys {
+		keks = append(keks, KEK{ID: id, Key: key})
+	}
+	k.mu.RUnlock()
+	return k.Set(keks...)
+}
+
+// Replace swaps in the contents of next, e.g. after re-reading a keyring file.
+// KEKs missing from next are retired rather than dropped: they keep unwrapping
+// existing envelopes until Prune is called once those have been re-wrapped.
+func (k *Keyring) Replace(next *Keyring) {
+	next.mu.RLock()
+	primary, keys := next.primary, next.keys
+	next.mu.RUnlock()
+
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	retired := make(map[string][]byte)
+	for _, previous := range []map[string][]byte{k.retired, k.keys} {
+		for id, key := range previous {
+			if _, kept := keys[id]; !kept {
+				retired[id] = key
+			}
+		}
+	}
+	k.primary = primary
+	k.keys = keys
+	k.retired = retired
+}
+
+// Prune forgets the KEKs retired by Replace. Call it only after every stored
+// envelope has been re-wrapped under the primary KEK.
+func (k *Keyring) Prune() {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	k.retired = nil
+}
+
+// Primary returns the KEK used to wrap new data keys.
+func (k *Keyring) Primary() KEK {
+	k.mu.RLock()
+	defer k.mu.RUnlock()
+	return KEK{ID: k.primary, Key: k.keys[k.primary]}
+}
+
+func (k *Keyring) lookup(id string) ([]byte, bool) {
+	k.mu.RLock()
+	defer k.mu.RUnlock()
+	if key, ok := k.keys[id]; ok {
+		return key, true
+	}
+	key, ok := k.retired[id]
+	return key, ok
+}
+
+func parseKEKs(spec string) ([]KEK, error) {
+	var keks []KEK
+	for _, entry := range strings.FieldsFunc(spec, func(r rune) bool { return r == ',' || r == '\n' }) {
+		entry = strings.TrimSpace(entry)
+		if entry == "" || strings.HasPrefix(entry, "#") {
+			continue
+		}
+		id, encoded, ok := strings.Cut(entry, ":")
+		if !ok {
+			return nil, errors.New("KEK entries must be formatted as id:base64key")
+		}
+		key, err := base64.StdEncoding.DecodeString(strings.TrimSpace(encoded))
+		if err != nil {
+			return nil, fmt.Errorf("decode KEK %s: %w", id, err)
+		}
+		keks = append(keks, KEK{ID: strings.TrimSpace(id), Key: key})
+	}
+	return keks, nil
+}
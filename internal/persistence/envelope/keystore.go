@@ -0,0 +1,343 @@
+package envelope
+
+import (
+	"bytes"
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+
+	"github.com/fiskaly/coding-challenges/signing-service-challenge/domain"
+	"github.com/fiskaly/coding-challenges/signing-service-challenge/internal/devices"
+	"github.com/google/uuid"
+)
+
+const (
+	envelopeBlockType = "ENVELOPE ENCRYPTED PRIVATE KEY"
+	kekIDHeader       = "KEK-Id"
+	wrappedKeyHeader  = "Wrapped-Key"
+	dataKeySize       = 32
+)
+
+// ErrUnsealedKey is returned by Load in strict mode for private keys that were
+// never envelope encrypted.
+var ErrUnsealedKey = domain.InternalError{Reason: "private key is not envelope encrypted"}
+
+// KeyStore encrypts private keys before handing them to the wrapped store.
+// Each device key version gets a fresh AES-256-GCM data key, which is itself
+// sealed with the primary KEK. Public keys pass through unchanged. Plaintext material
+// written before encryption was enabled is still returned by Load until
+// Rewrap migrates it and EnableStrict is called.
+type KeyStore struct {
+	inner   devices.KeyStore
+	keyring *Keyring
+	writeMu sync.Mutex // serialises writes so Rewrap never overwrites fresher material
+	strict  atomic.Bool
+}
+
+var _ devices.KeyStore = (*KeyStore)(nil)
+
+// NewKeyStore wraps inner with envelope encryption using keys from keyring.
+func NewKeyStore(inner devices.KeyStore, keyring *Keyring) *KeyStore {
+	return &KeyStore{inner: inner, keyring: keyring}
+}
+
+// Store seals the private keys and persists them in the wrapped store.
+func (k *KeyStore) Store(ctx context.Context, deviceID uuid.UUID, version int, material domain.KeyMaterial) error {
+	sealed, err := k.seal(keyAAD(deviceID, version), material.Private)
+	if err != nil {
+		return err
+	}
+	stored := domain.KeyMaterial{Public: material.Public, Private: sealed}
+	if material.Secondary != nil {
+		secondary, err := k.seal(secondaryKeyAAD(deviceID, version), material.Secondary.Private)
+		if err != nil {
+			return err
+		}
+		stored.Secondary = &domain.KeyMaterial{Public: material.Secondary.Public, Private: secondary}
+	}
+
+	k.writeMu.Lock()
+	defer k.writeMu.Unlock()
+	return k.inner.Store(ctx, deviceID, version, stored)
+}
+
+// Load fetches and decrypts one version of the key material of a device.
+func (k *KeyStore) Load(ctx context.Context, deviceID uuid.UUID, version int) (domain.KeyMaterial, error) {
+	material, err := k.inner.Load(ctx, deviceID, version)
+	if err != nil {
+		return domain.KeyMaterial{}, err
+	}
+
+	private, err := k.openPrivate(keyAAD(deviceID, version), material.Private)
+	if err != nil {
+		return domain.KeyMaterial{}, err
+	}
+	opened := domain.KeyMaterial{Public: material.Public, Private: private}
+	if material.Secondary != nil {
+		secondary, err := k.openPrivate(secondaryKeyAAD(deviceID, version), material.Secondary.Private)
+		if err != nil {
+			return domain.KeyMaterial{}, err
+		}
+		opened.Secondary = &domain.KeyMaterial{Public: material.Secondary.Public, Private: secondary}
+	}
+	return opened, nil
+}
+
+// EnableStrict makes Load reject plaintext material with ErrUnsealedKey. Call it
+// once Rewrap has migrated every device, or right away for stores that never
+// held plaintext. Strict mode cannot be turned off again.
+func (k *KeyStore) EnableStrict() {
+	k.strict.Store(true)
+}
+
+// openPrivate decrypts an envelope; plaintext material is returned unchanged
+// unless the store is strict.
+func (k *KeyStore) openPrivate(aad []byte, private []byte) ([]byte, error) {
+	block := decodeEnvelope(private)
+	if block == nil {
+		if k.strict.Load() {
+			return nil, ErrUnsealedKey
+		}
+		return private, nil
+	}
+	return k.open(aad, block)
+}
+
+// Versions lists the key versions held by the wrapped store.
+func (k *KeyStore) Versions(ctx context.Context, deviceID uuid.UUID) ([]int, error) {
+	return k.inner.Versions(ctx, deviceID)
+}
+
+// Delete removes the device key material from the wrapped store.
+func (k *KeyStore) Delete(ctx context.Context, deviceID uuid.UUID) error {
+	k.writeMu.Lock()
+	defer k.writeMu.Unlock()
+	return k.inner.Delete(ctx, deviceID)
+}
+
+// DeleteVersion removes one key version from the underlying store.
+func (k *KeyStore) DeleteVersion(ctx context.Context, deviceID uuid.UUID, version int) error {
+	k.writeMu.Lock()
+	defer k.writeMu.Unlock()
+	return k.inner.DeleteVersion(ctx, deviceID, version)
+}
+
+// Rewrap re-seals the data keys of the given devices under the primary KEK and
+// encrypts any plaintext material left from before encryption was enabled.
+// Private key ciphertexts are kept, so only the small wrapped key changes.
+// Devices without key material are skipped. It returns the number of key
+// versions updated and is safe to run while the store serves traffic.
+func (k *KeyStore) Rewrap(ctx context.Context, deviceIDs []uuid.UUID) (int, error) {
+	primary := k.keyring.Primary()
+	updated := 0
+	for _, deviceID := range deviceIDs {
+		versions, err := k.inner.Versions(ctx, deviceID)
+		if err != nil {
+			return updated, fmt.Errorf("rewrap device %s: %w", deviceID, err)
+		}
+		for _, version := range versions {
+			if err := ctx.Err(); err != nil {
+				return updated, err
+			}
+			changed, err := k.rewrapOne(ctx, deviceID, version, primary)
+			if err != nil {
+				return updated, fmt.Errorf("rewrap device %s key version %d: %w", deviceID, version, err)
+			}
+			if changed {
+				updated++
+			}
+		}
+	}
+	return updated, nil
+}
+
+func (k *KeyStore) rewrapOne(ctx context.Context, deviceID uuid.UUID, version int, primary KEK) (bool, error) {
+	k.writeMu.Lock()
+	defer k.writeMu.Unlock()
+
+	material, err := k.inner.Load(ctx, deviceID, version)
+	if errors.Is(err, domain.ErrKeyMaterialMissing) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+
+	private, changed, err := k.rewrapPrivate(keyAAD(deviceID, version), material.Private, primary)
+	if err != nil {
+		return false, err
+	}
+	rewrapped := domain.KeyMaterial{Public: material.Public, Private: private}
+	if material.Secondary != nil {
+		secondary, secondaryChanged, err := k.rewrapPrivate(secondaryKeyAAD(deviceID, version), material.Secondary.Private, primary)
+		if err != nil {
+			return false, err
+		}
+		rewrapped.Secondary = &domain.KeyMaterial{Public: material.Secondary.Public, Private: secondary}
+		changed = changed || secondaryChanged
+	}
+	if !changed {
+		return false, nil
+	}
+
+	if err := k.inner.Store(ctx, deviceID, version, rewrapped); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// rewrapPrivate returns private sealed under the primary KEK and whether it
+// had to change: plaintext is sealed, envelopes get their data key re-wrapped.
+func (k *KeyStore) rewrapPrivate(aad []byte, private []byte, primary KEK) ([]byte, bool, error) {
+	block := decodeEnvelope(private)
+	switch {
+	case block == nil:
+		sealed, err := k.seal(aad, private)
+		if err != nil {
+			return nil, false, err
+		}
+		return sealed, true, nil
+	case block.Headers[kekIDHeader] == primary.ID:
+		return private, false, nil
+	default:
+		dataKey, err := k.unwrapDataKey(aad, block)
+		if err != nil {
+			return nil, false, err
+		}
+		wrapped, err := wrapDataKey(primary, aad, dataKey)
+		if err != nil {
+			return nil, false, err
+		}
+		block.Headers[kekIDHeader] = primary.ID
+		block.Headers[wrappedKeyHeader] = wrapped
+		return pem.EncodeToMemory(block), true, nil
+	}
+}
+
+// keyAAD binds envelopes to the device ID and key version they were sealed for.
+func keyAAD(deviceID uuid.UUID, version int) []byte {
+	return binary.BigEndian.AppendUint64(deviceID[:], uint64(version))
+}
+
+// secondaryKeyAAD binds the secondary key of hybrid devices, so it cannot be
+// swapped with the primary key of the same version.
+func secondaryKeyAAD(deviceID uuid.UUID, version int) []byte {
+	return append(keyAAD(deviceID, version), "secondary"...)
+}
+
+// seal encrypts plaintext under a fresh data key bound to aad.
+func (k *KeyStore) seal(aad []byte, plaintext []byte) ([]byte, error) {
+	dataKey := make([]byte, dataKeySize)
+	if _, err := rand.Read(dataKey); err != nil {
+		return nil, fmt.Errorf("generate data key: %w", err)
+	}
+
+	ciphertext, err := sealAESGCM(dataKey, plaintext, aad)
+	if err != nil {
+		return nil, err
+	}
+	primary := k.keyring.Primary()
+	wrapped, err := wrapDataKey(primary, aad, dataKey)
+	if err != nil {
+		return nil, err
+	}
+
+	return pem.EncodeToMemory(&pem.Block{
+		Type: envelopeBlockType,
+		Headers: map[string]string{
+			kekIDHeader:      primary.ID,
+			wrappedKeyHeader: wrapped,
+		},
+		Bytes: ciphertext,
+	}), nil
+}
+
+func (k *KeyStore) open(aad []byte, block *pem.Block) ([]byte, error) {
+	dataKey, err := k.unwrapDataKey(aad, block)
+	if err != nil {
+		return nil, err
+	}
+	plaintext, err := openAESGCM(dataKey, block.Bytes, aad)
+	if err != nil {
+		return nil, domain.InternalError{Reason: "decrypt private key: " + err.Error()}
+	}
+	return plaintext, nil
+}
+
+func (k *KeyStore) unwrapDataKey(aad []byte, block *pem.Block) ([]byte, error) {
+	kekID := block.Headers[kekIDHeader]
+	kek, ok := k.keyring.lookup(kekID)
+	if !ok {
+		return nil, domain.InternalError{Reason: fmt.Sprintf("unknown key-encryption key %q", kekID)}
+	}
+	wrapped, err := base64.StdEncoding.DecodeString(block.Headers[wrappedKeyHeader])
+	if err != nil {
+		return nil, domain.InternalError{Reason: "decode wrapped data key: " + err.Error()}
+	}
+	dataKey, err := openAESGCM(kek, wrapped, aad)
+	if err != nil {
+		return nil, domain.InternalError{Reason: "unwrap data key: " + err.Error()}
+	}
+	return dataKey, nil
+}
+
+func wrapDataKey(kek KEK, aad []byte, dataKey []byte) (string, error) {
+	wrapped, err := sealAESGCM(kek.Key, dataKey, aad)
+	if err != nil {
+		return "", fmt.Errorf("wrap data key: %w", err)
+	}
+	return base64.StdEncoding.EncodeToString(wrapped), nil
+}
+
+// decodeEnvelope returns the envelope block or nil for plaintext material.
+func decodeEnvelope(private []byte) *pem.Block {
+	if !bytes.Contains(private, []byte(envelopeBlockType)) {
+		return nil
+	}
+	block, _ := pem.Decode(private)
+	if block == nil || block.Type != envelopeBlockType {
+		return nil
+	}
+	return block
+}
+
+// sealAESGCM returns nonce || ciphertext.
+func sealAESGCM(key, plaintext, additionalData []byte) ([]byte, error) {
+	aead, err := newGCM(key)
+	if err != nil {
+		return nil, err
+	}
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, fmt.Errorf("generate nonce: %w", err)
+	}
+	return aead.Seal(nonce, nonce, plaintext, additionalData), nil
+}
+
+func openAESGCM(key, sealed, additionalData []byte) ([]byte, error) {
+	aead, err := newGCM(key)
+	if err != nil {
+		return nil, err
+	}
+	if len(sealed) < aead.NonceSize() {
+		return nil, errors.New("ciphertext too short")
+	}
+	nonce, ciphertext := sealed[:aead.NonceSize()], sealed[aead.NonceSize():]
+	return aead.Open(nil, nonce, ciphertext, additionalData)
+}
+
+func newGCM(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
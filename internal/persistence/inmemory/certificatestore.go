@@ -0,0 +1,81 @@
+package inmemory
+
+import (
+	"context"
+	"strconv"
+	"sync"
+
+	"github.com/fiskaly/coding-challenges/signing-service-challenge/domain"
+	"github.com/fiskaly/coding-challenges/signing-service-challenge/internal/devices"
+	"github.com/google/uuid"
+)
+
+// CertificateStore keeps certificate chains in RAM keyed by device ID and key version.
+type CertificateStore struct {
+	mu     sync.RWMutex
+	chains map[uuid.UUID]map[int][][]byte
+}
+
+var _ devices.CertificateStore = (*CertificateStore)(nil)
+
+// NewCertificateStore instantiates the in-memory certificate store.
+func NewCertificateStore() *CertificateStore {
+	return &CertificateStore{
+		chains: make(map[uuid.UUID]map[int][][]byte),
+	}
+}
+
+// Put stores the chain of a device key version, replacing an earlier one.
+func (c *CertificateStore) Put(_ context.Context, deviceID uuid.UUID, version int, chain [][]byte) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	versions, exists := c.chains[deviceID]
+	if !exists {
+		versions = make(map[int][][]byte)
+		c.chains[deviceID] = versions
+	}
+	versions[version] = cloneChain(chain)
+	return nil
+}
+
+// Get returns the chain of a device key version.
+func (c *CertificateStore) Get(_ context.Context, deviceID uuid.UUID, version int) ([][]byte, error) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	chain, exists := c.chains[deviceID][version]
+	if !exists {
+		return nil, domain.NotFoundError{Resource: "certificate", ID: strconv.Itoa(version)}
+	}
+	return cloneChain(chain), nil
+}
+
+// Delete drops the chains of every key version of a device.
+func (c *CertificateStore) Delete(_ context.Context, deviceID uuid.UUID) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	delete(c.chains, deviceID)
+	return nil
+}
+
+// DeleteVersion drops the chain of one key version of a device.
+func (c *CertificateStore) DeleteVersion(_ context.Context, deviceID uuid.UUID, version int) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	delete(c.chains[deviceID], version)
+	if len(c.chains[deviceID]) == 0 {
+		delete(c.chains, deviceID)
+	}
+	return nil
+}
+
+func cloneChain(chain [][]byte) [][]byte {
+	clone := make([][]byte, len(chain))
+	for i, certificate := range chain {
+		clone[i] = append([]byte(nil), certificate...)
+	}
+	return clone
+}
@@ -0,0 +1,54 @@
+package inmemory
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/fiskaly/coding-challenges/signing-service-challenge/domain"
+	"github.com/google/uuid"
+)
+
+func TestCertificateStoreLifecycle(t *testing.T) {
+	store := NewCertificateStore()
+	uid := uuid.New()
+	chain := [][]byte{[]byte("leaf"), []byte("intermediate")}
+
+	if err := store.Put(context.Background(), uid, 1, chain); err != nil {
+		t.Fatalf("put failed: %v", err)
+	}
+	chain[0][0] = 'L'
+
+	loaded, err := store.Get(context.Background(), uid, 1)
+	if err != nil {
+		t.Fatalf("get failed: %v", err)
+	}
+	if len(loaded) != 2 || string(loaded[0]) != "leaf" {
+		t.Fatalf("unexpected chain %q", loaded)
+	}
+
+	var notFound domain.NotFoundError
+	if _, err := store.Get(context.Background(), uid, 2); !errors.As(err, &notFound) {
+		t.Fatalf("expected not found for unknown version, got %v", err)
+	}
+
+	if err := store.Put(context.Background(), uid, 2, chain); err != nil {
+		t.Fatalf("put v2 failed: %v", err)
+	}
+	if err := store.DeleteVersion(context.Background(), uid, 2); err != nil {
+		t.Fatalf("delete v2 failed: %v", err)
+	}
+	if _, err := store.Get(context.Background(), uid, 2); !errors.As(err, &notFound) {
+		t.Fatalf("expected not found after version delete, got %v", err)
+	}
+	if _, err := store.Get(context.Background(), uid, 1); err != nil {
+		t.Fatalf("expected version 1 to survive, got %v", err)
+	}
+
+	if err := store.Delete(context.Background(), uid); err != nil {
+		t.Fatalf("delete failed: %v", err)
+	}
+	if _, err := store.Get(context.Background(), uid, 1); !errors.As(err, &notFound) {
+		t.Fatalf("expected not found after delete, got %v", err)
+	}
+}
@@ -2,6 +2,9 @@ package inmemory
 
 import (
 	"context"
+	"crypto/x509"
+	"encoding/pem"
+	"sort"
 	"sync"
 
 	"github.com/fiskaly/coding-challenges/signing-service-challenge/domain"
@@ -9,10 +12,11 @@ import (
 	"github.com/google/uuid"
 )
 
-// InMemoryKeyStore keeps key material in RAM keyed by device ID.
+// InMemoryKeyStore keeps key material in RAM keyed by device ID and key version.
 type KeyStore struct {
-	mu    sync.RWMutex
-	store map[uuid.UUID]domain.KeyMaterial
+	mu     sync.RWMutex
+	store  map[uuid.UUID]map[int]domain.KeyMaterial
+	owners map[string]uuid.UUID // canonical public key -> device, keeps keys unique across devices
 }
 
 var _ devices.KeyStore = (*KeyStore)(nil)
@@ -20,45 +24,130 @@ var _ devices.KeyStore = (*KeyStore)(nil)
 // NewKeyStore instantiates the in-memory key store.
 func NewKeyStore() *KeyStore {
 	return &KeyStore{
-		store: make(map[uuid.UUID]domain.KeyMaterial),
+		store:  make(map[uuid.UUID]map[int]domain.KeyMaterial),
+		owners: make(map[string]uuid.UUID),
 	}
 }
 
-// Store persists key material for a device.
-func (k *KeyStore) Store(_ context.Context, deviceID uuid.UUID, material domain.KeyMaterial) error {
+// Store persists key material as the given version of a device key. It
+// rejects public keys, primary or secondary, already stored for a different
+// device, however they are encoded.
+func (k *KeyStore) Store(_ context.Context, deviceID uuid.UUID, version int, material domain.KeyMaterial) error {
 	k.mu.Lock()
 	defer k.mu.Unlock()
 
-	copyMaterial := domain.KeyMaterial{
-		Public:  append([]byte(nil), material.Public...),
-		Private: append([]byte(nil), material.Private...),
+	keys := publicKeyIDs(material)
+	for _, key := range keys {
+		if owner, taken := k.owners[key]; taken && owner != deviceID {
+			return domain.ErrKeyInUse
+		}
+	}
+	versions, exists := k.store[deviceID]
+	if !exists {
+		versions = make(map[int]domain.KeyMaterial)
+		k.store[deviceID] = versions
+	}
+	if previous, exists := versions[version]; exists {
+		for _, key := range publicKeyIDs(previous) {
+			delete(k.owners, key)
+		}
 	}
 
-	k.store[deviceID] = copyMaterial
+	versions[version] = material.Clone()
+	for _, key := range keys {
+		k.owners[key] = deviceID
+	}
 	return nil
 }
 
-// Load fetches key material for a device.
-func (k *KeyStore) Load(_ context.Context, deviceID uuid.UUID) (domain.KeyMaterial, error) {
+// Load fetches one version of the key material of a device.
+func (k *KeyStore) Load(_ context.Context, deviceID uuid.UUID, version int) (domain.KeyMaterial, error) {
 	k.mu.RLock()
 	defer k.mu.RUnlock()
 
-	material, exists := k.store[deviceID]
+	material, exists := k.store[deviceID][version]
 	if !exists {
 		return domain.KeyMaterial{}, domain.ErrKeyMaterialMissing
 	}
 
-	return domain.KeyMaterial{
-		Public:  append([]byte(nil), material.Public...),
-		Private: append([]byte(nil), material.Private...),
-	}, nil
+	return material.Clone(), nil
 }
 
-// Delete removes key material from the store.
+// Versions lists the stored key versions of a device in ascending order.
+func (k *KeyStore) Versions(_ context.Context, deviceID uuid.UUID) ([]int, error) {
+	k.mu.RLock()
+	defer k.mu.RUnlock()
+
+	versions := make([]int, 0, len(k.store[deviceID]))
+	for version := range k.store[deviceID] {
+		versions = append(versions, version)
+	}
+	sort.Ints(versions)
+	return versions, nil
+}
+
+// Delete removes all key versions of a device from the store.
 func (k *KeyStore) Delete(_ context.Context, deviceID uuid.UUID) error {
 	k.mu.Lock()
 	defer k.mu.Unlock()
 
+	for _, material := range k.store[deviceID] {
+		for _, key := range publicKeyIDs(material) {
+			delete(k.owners, key)
+		}
+	}
 	delete(k.store, deviceID)
 	return nil
 }
+
+// DeleteVersion removes one key version of a device from the store.
+func (k *KeyStore) DeleteVersion(_ context.Context, deviceID uuid.UUID, version int) error {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+
+	material, exists := k.store[deviceID][version]
+	if !exists {
+		return nil
+	}
+	for _, key := range publicKeyIDs(material) {
+		delete(k.owners, key)
+	}
+	delete(k.store[deviceID], version)
+	if len(k.store[deviceID]) == 0 {
+		delete(k.store, deviceID)
+	}
+	return nil
+}
+
+// publicKeyIDs returns the uniqueness keys of the primary and secondary public
+// keys in material. Symmetric keys have no public half and are not tracked.
+func publicKeyIDs(material domain.KeyMaterial) []string {
+	var keys []string
+	for current := &material; current != nil; current = current.Secondary {
+		if len(current.Public) > 0 {
+			keys = append(keys, canonicalPublicKey(current.Public))
+		}
+	}
+	return keys
+}
+
+// canonicalPublicKey reduces a PEM or DER public key to its SubjectPublicKeyInfo
+// DER so that different encodings of one key compare equal. Input that does
+// not parse as a public key is compared byte for byte.
+func canonicalPublicKey(public []byte) string {
+	der := public
+	if block, _ := pem.Decode(public); block != nil {
+		der = block.Bytes
+	}
+	key, err := x509.ParsePKIXPublicKey(der)
+	if err != nil {
+		if key, err = x509.ParsePKCS1PublicKey(der); err != nil {
+			return string(public)
+		}
+	}
+	canonical, err := x509.MarshalPKIXPublicKey(key)
+	if err != nil {
+		return string(public)
+	}
+	return string(canonical)
+}
@@ -2,6 +2,11 @@ package inmemory
 
 import (
 	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
 	"testing"
 
 	"github.com/fiskaly/coding-challenges/signing-service-challenge/domain"
@@ -13,11 +18,11 @@ func TestKeyStoreLifecycle(t *testing.T) {
 	uid := uuid.New()
 	material := domain.KeyMaterial{Public: []byte("pub"), Private: []byte("priv")}
 
-	if err := store.Store(context.Background(), uid, material); err != nil {
+	if err := store.Store(context.Background(), uid, 1, material); err != nil {
 		t.Fatalf("store failed: %v", err)
 	}
 
-	loaded, err := store.Load(context.Background(), uid)
+	loaded, err := store.Load(context.Background(), uid, 1)
 	if err != nil {
 		t.Fatalf("load failed: %v", err)
 	}
@@ -27,7 +32,7 @@ func TestKeyStoreLifecycle(t *testing.T) {
 		t.Fatalf("store mutated original material")
 	}
 
-	again, err := store.Load(context.Background(), uid)
+	again, err := store.Load(context.Background(), uid, 1)
 	if err != nil {
 		t.Fatalf("second load failed: %v", err)
 	}
@@ -39,7 +44,132 @@ func TestKeyStoreLifecycle(t *testing.T) {
 		t.Fatalf("delete failed: %v", err)
 	}
 
-	if _, err := store.Load(context.Background(), uid); err != domain.ErrKeyMaterialMissing {
+	if _, err := store.Load(context.Background(), uid, 1); err != domain.ErrKeyMaterialMissing {
 		t.Fatalf("expected missing key error, got %v", err)
 	}
 }
+
+func TestKeyStoreRejectsSharedKeys(t *testing.T) {
+	store := NewKeyStore()
+	first, second := uuid.New(), uuid.New()
+	material := domain.KeyMaterial{Public: []byte("pub"), Private: []byte("priv")}
+
+	if err := store.Store(context.Background(), first, 1, material); err != nil {
+		t.Fatalf("store failed: %v", err)
+	}
+	if err := store.Store(context.Background(), second, 1, material); err != domain.ErrKeyInUse {
+		t.Fatalf("expected ErrKeyInUse, got %v", err)
+	}
+
+	if err := store.Delete(context.Background(), first); err != nil {
+		t.Fatalf("delete failed: %v", err)
+	}
+	if err := store.Store(context.Background(), second, 1, material); err != nil {
+		t.Fatalf("expected key to be reusable after delete, got %v", err)
+	}
+}
+
+func TestKeyStoreRejectsReencodedKeys(t *testing.T) {
+	store := NewKeyStore()
+	first, second := uuid.New(), uuid.New()
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("generate key: %v", err)
+	}
+	spki, err := x509.MarshalPKIXPublicKey(&key.PublicKey)
+	if err != nil {
+		t.Fatalf("marshal key: %v", err)
+	}
+
+	pkix := pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: spki})
+	if err := store.Store(context.Background(), first, 1, domain.KeyMaterial{Public: pkix, Private: []byte("priv")}); err != nil {
+		t.Fatalf("store failed: %v", err)
+	}
+	for name, public := range map[string][]byte{
+		"pkcs1":   pem.EncodeToMemory(&pem.Block{Type: "RSA PUBLIC KEY", Bytes: x509.MarshalPKCS1PublicKey(&key.PublicKey)}),
+		"der":     spki,
+		"headers": pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Headers: map[string]string{"Comment": "copy"}, Bytes: spki}),
+	} {
+		if err := store.Store(context.Background(), second, 1, domain.KeyMaterial{Public: public, Private: []byte("priv")}); err != domain.ErrKeyInUse {
+			t.Fatalf("%s: expected ErrKeyInUse, got %v", name, err)
+		}
+	}
+}
+
+func TestKeyStoreRejectsSharedSecondaryKeys(t *testing.T) {
+	store := NewKeyStore()
+	first, second := uuid.New(), uuid.New()
+	public, _, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatalf("generate key: %v", err)
+	}
+	spki, err := x509.MarshalPKIXPublicKey(public)
+	if err != nil {
+		t.Fatalf("marshal key: %v", err)
+	}
+	secondary := domain.KeyMaterial{Public: pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: spki}), Private: []byte("priv-2")}
+
+	hybrid := domain.KeyMaterial{Public: []byte("pub"), Private: []byte("priv"), Secondary: &secondary}
+	if err := store.Store(context.Background(), first, 1, hybrid); err != nil {
+		t.Fatalf("store failed: %v", err)
+	}
+	if err := store.Store(context.Background(), second, 1, secondary); err != domain.ErrKeyInUse {
+		t.Fatalf("expected ErrKeyInUse for a reused secondary key, got %v", err)
+	}
+
+	if err := store.Delete(context.Background(), first); err != nil {
+		t.Fatalf("delete failed: %v", err)
+	}
+	if err := store.Store(context.Background(), second, 1, secondary); err != nil {
+		t.Fatalf("expected secondary key to be reusable after delete, got %v", err)
+	}
+}
+
+func TestKeyStoreKeepsVersions(t *testing.T) {
+	store := NewKeyStore()
+	uid := uuid.New()
+	first := domain.KeyMaterial{Public: []byte("pub-1"), Private: []byte("priv-1")}
+	second := domain.KeyMaterial{Public: []byte("pub-2"), Private: []byte("priv-2")}
+
+	if err := store.Store(context.Background(), uid, 1, first); err != nil {
+		t.Fatalf("store v1 failed: %v", err)
+	}
+	if err := store.Store(context.Background(), uid, 2, second); err != nil {
+		t.Fatalf("store v2 failed: %v", err)
+	}
+
+	old, err := store.Load(context.Background(), uid, 1)
+	if err != nil || string(old.Public) != "pub-1" {
+		t.Fatalf("expected version 1 to stay available, got %q, %v", old.Public, err)
+	}
+	versions, err := store.Versions(context.Background(), uid)
+	if err != nil {
+		t.Fatalf("versions failed: %v", err)
+	}
+	if len(versions) != 2 || versions[0] != 1 || versions[1] != 2 {
+		t.Fatalf("unexpected versions %v", versions)
+	}
+	if _, err := store.Load(context.Background(), uid, 3); err != domain.ErrKeyMaterialMissing {
+		t.Fatalf("expected missing key error, got %v", err)
+	}
+
+	if err := store.DeleteVersion(context.Background(), uid, 2); err != nil {
+		t.Fatalf("delete v2 failed: %v", err)
+	}
+	if versions, _ := store.Versions(context.Background(), uid); len(versions) != 1 || versions[0] != 1 {
+		t.Fatalf("expected only version 1 after deleting v2, got %v", versions)
+	}
+	if err := store.Store(context.Background(), uuid.New(), 1, second); err != nil {
+		t.Fatalf("expected v2 key to be released after version delete, got %v", err)
+	}
+
+	if err := store.Delete(context.Background(), uid); err != nil {
+		t.Fatalf("delete failed: %v", err)
+	}
+	if versions, _ := store.Versions(context.Background(), uid); len(versions) != 0 {
+		t.Fatalf("expected all versions removed, got %v", versions)
+	}
+	if err := store.Store(context.Background(), uuid.New(), 1, first); err != nil {
+		t.Fatalf("expected old key to be released after delete, got %v", err)
+	}
+}
@@ -0,0 +1,326 @@
+package signerd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/rpc"
+	"sync"
+	"time"
+
+	"github.com/fiskaly/coding-challenges/signing-service-challenge/domain"
+	"github.com/fiskaly/coding-challenges/signing-service-challenge/internal/devices"
+	"github.com/fiskaly/coding-challenges/signing-service-challenge/pkg/crypto"
+	"github.com/google/uuid"
+)
+
+// Client reaches the signer daemon. It implements the key generation, import
+// and signer ports so the API process only stores public keys and handles.
+type Client struct {
+	algorithms domain.Algorithms
+	verifiers  devices.SignerFactory
+
+	mu     sync.Mutex
+	rpc    *rpc.Client              // nil until (re)connected
+	dial   func() (net.Conn, error) // nil when the connection cannot be re-established
+	closed bool
+}
+
+var (
+	_ devices.KeyGenerator  = (*Client)(nil)
+	_ devices.KeyImporter   = (*Client)(nil)
+	_ devices.SignerFactory = (*Client)(nil)
+	_ devices.KeyReleaser   = (*Client)(nil)
+)
+
+// Dial connects to the daemon listening on the Unix socket at path. Verifiers
+// only need public keys and are built locally by verifiers, except for
+// symmetric algorithms whose MACs the daemon checks.
+//
+// A lost connection, e.g. after a daemon restart, is dialled again on the next
+// call.
+func Dial(path string, algorithms domain.Algorithms, verifiers devices.SignerFactory) (*Client, error) {
+	dial := func() (net.Conn, error) { return net.Dial("unix", path) }
+	conn, err := dial()
+	if err != nil {
+		return nil, fmt.Errorf("dial signer daemon: %w", err)
+	}
+	client := NewClient(conn, algorithms, verifiers)
+	client.dial = dial
+	return client, nil
+}
+
+// NewClient speaks the daemon protocol over an established connection. It
+// cannot reconnect once that connection is lost; use Dial for that.
+func NewClient(conn net.Conn, algorithms domain.Algorithms, verifiers devices.SignerFactory) *Client {
+	return &Client{rpc: rpc.NewClient(conn), algorithms: algorithms, verifiers: verifiers}
+}
+
+// Close releases the connection to the daemon. Calls after Close fail with
+// rpc.ErrShutdown.
+func (c *Client) Close() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.closed = true
+	if c.rpc == nil {
+		return nil
+	}
+	err := c.rpc.Close()
+	c.rpc = nil
+	return err
+}
+
+// Generate has the daemon create a key pair and returns its public key with a handle.
+func (c *Client) Generate(algorithm domain.Algorithm, spec domain.KeySpec) (domain.KeyMaterial, error) {
+	var reply KeyReply
+	if err := c.call("Generate", GenerateArgs{Algorithm: algorithm, KeySpec: spec}, &reply); err != nil {
+		return domain.KeyMaterial{}, err
+	}
+	return domain.KeyMaterial{Public: reply.Public, Private: reply.Handle}, nil
+}
+
+// Import hands a caller-supplied private key to the daemon without decoding it locally.
+func (c *Client) Import(algorithm domain.Algorithm, privateKeyPEM []byte) (devices.ImportedKey, error) {
+	var reply KeyReply
+	if err := c.call("Import", ImportArgs{Algorithm: algorithm, PrivateKey: privateKeyPEM}, &reply); err != nil {
+		return devices.ImportedKey{}, err
+	}
+	return devices.ImportedKey{
+		KeySpec:  reply.KeySpec,
+		Material: domain.KeyMaterial{Public: reply.Public, Private: reply.Handle},
+	}, nil
+}
+
+// SignerFor returns a signer that forwards payloads to the daemon.
+func (c *Client) SignerFor(device domain.Device, material domain.KeyMaterial) (devices.Signer, error) {
+	if _, err := parseHandle(material.Private); err != nil {
+		return nil, err
+	}
+	return &remoteSigner{client: c, device: device, handle: append([]byte(nil), material.Private...)}, nil
+}
+
+// VerifierFor builds a local verifier from the public key. Symmetric
+// algorithms have none, so their MACs are checked by the daemon.
+func (c *Client) VerifierFor(device domain.Device, material domain.KeyMaterial) (devices.Verifier, error) {
+	if domain.IsSymmetric(c.algorithms, device.Algorithm) {
+		if _, err := parseHandle(material.Private); err != nil {
+			return nil, err
+		}
+		return &remoteVerifier{client: c, device: device, handle: append([]byte(nil), material.Private...)}, nil
+	}
+	return c.verifiers.VerifierFor(device, domain.KeyMaterial{Public: material.Public})
+}
+
+// Destroy asks the daemon to forget the key behind handle.
+func (c *Client) Destroy(handle []byte) error {
+	var reply DestroyReply
+	return c.call("Destroy", DestroyArgs{Handle: handle}, &reply)
+}
+
+// Release destroys the daemon keys behind the handles in material, primary and
+// secondary. The service calls it for keys that never reached the key store.
+func (c *Client) Release(material domain.KeyMaterial) error {
+	for _, handle := range keyHandles(material) {
+		if err := c.Destroy(handle); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// CertificateAuthority returns an issuer that has the daemon's certificate
+// authority certify device keys. The root certificate is fetched once, so the
+// daemon must be configured with a CA.
+func (c *Client) CertificateAuthority() (devices.CertificateIssuer, error) {
+	var reply CertificateReply
+	if err := c.call("Root", RootArgs{}, &reply); err != nil {
+		return nil, err
+	}
+	return &remoteIssuer{client: c, root: reply.Certificate}, nil
+}
+
+// Timestamper returns a time-stamping authority backed by the daemon's TSA.
+func (c *Client) Timestamper() devices.Timestamper {
+	return &remoteTimestamper{client: c}
+}
+
+// KeyPoolStats returns the counters of the daemon's key pool, which runs in
+// the daemon instead of the API process; it is empty when no pool is configured.
+func (c *Client) KeyPoolStats() ([]crypto.KeyPoolStats, error) {
+	var reply KeyPoolStatsReply
+	if err := c.call("KeyPoolStats", KeyPoolStatsArgs{}, &reply); err != nil {
+		return nil, err
+	}
+	return reply.Pools, nil
+}
+
+// KeyStore decorates inner so deleting a device also destroys its daemon-held keys.
+func (c *Client) KeyStore(inner devices.KeyStore) devices.KeyStore {
+	return &releasingKeyStore{KeyStore: inner, client: c}
+}
+
+func (c *Client) call(method string, args interface{}, reply remoteReply) error {
+	client, err := c.connection()
+	if err == nil {
+		err = client.Call(serviceName+"."+method, args, reply)
+		if unsent(err) {
+			// The request never reached the daemon, so it is safe to send it
+			// again over a fresh connection.
+			c.disconnect(client)
+			if client, err = c.connection(); err == nil {
+				err = client.Call(serviceName+"."+method, args, reply)
+			}
+		}
+		var serverErr rpc.ServerError
+		if err != nil && !errors.As(err, &serverErr) {
+			// The connection is broken; reconnect on the next call. A request
+			// lost mid-call is not repeated, as the daemon may have acted on it.
+			c.disconnect(client)
+		}
+	}
+	if err != nil {
+		return fmt.Errorf("signer daemon %s: %w", method, err)
+	}
+	return reply.remoteError().decode()
+}
+
+// unsent reports whether err means the request was not delivered: the
+// connection was already shut down or writing the request failed.
+func unsent(err error) bool {
+	var opErr *net.OpError
+	return errors.Is(err, rpc.ErrShutdown) || (errors.As(err, &opErr) && opErr.Op == "write")
+}
+
+// connection returns the current daemon connection, dialling a new one when
+// the previous connection was lost.
+func (c *Client) connection() (*rpc.Client, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	switch {
+	case c.closed:
+		return nil, rpc.ErrShutdown
+	case c.rpc != nil:
+		return c.rpc, nil
+	case c.dial == nil:
+		return nil, rpc.ErrShutdown
+	}
+	conn, err := c.dial()
+	if err != nil {
+		return nil, err
+	}
+	c.rpc = rpc.NewClient(conn)
+	return c.rpc, nil
+}
+
+// disconnect drops client if it is still the current connection, so the next
+// call dials again.
+func (c *Client) disconnect(client *rpc.Client) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if client != nil && c.rpc == client && c.dial != nil {
+		c.rpc.Close()
+		c.rpc = nil
+	}
+}
+
+type remoteSigner struct {
+	client *Client
+	device domain.Device
+	handle []byte
+}
+
+func (s *remoteSigner) Sign(dataToBeSigned []byte) ([]byte, error) {
+	var reply SignReply
+	args := SignArgs{Device: s.device, Handle: s.handle, Data: dataToBeSigned}
+	if err := s.client.call("Sign", args, &reply); err != nil {
+		return nil, err
+	}
+	return reply.Signature, nil
+}
+
+type remoteVerifier struct {
+	client *Client
+	device domain.Device
+	handle []byte
+}
+
+func (v *remoteVerifier) Verify(data, signature []byte) (bool, error) {
+	var reply VerifyReply
+	args := VerifyArgs{Device: v.device, Handle: v.handle, Data: data, Signature: signature}
+	if err := v.client.call("Verify", args, &reply); err != nil {
+		return false, err
+	}
+	return reply.Valid, nil
+}
+
+type remoteIssuer struct {
+	client *Client
+	root   []byte
+}
+
+func (i *remoteIssuer) Issue(device domain.Device, publicKeyPEM []byte, notBefore time.Time) ([]byte, error) {
+	var reply CertificateReply
+	args := IssueArgs{Device: device, Public: publicKeyPEM, NotBefore: notBefore}
+	if err := i.client.call("Issue", args, &reply); err != nil {
+		return nil, err
+	}
+	return reply.Certificate, nil
+}
+
+func (i *remoteIssuer) Root() []byte {
+	return i.root
+}
+
+type remoteTimestamper struct {
+	client *Client
+}
+
+func (t *remoteTimestamper) Timestamp(_ context.Context, digest []byte) ([]byte, error) {
+	var reply TimestampReply
+	if err := t.client.call("Timestamp", TimestampArgs{Digest: digest}, &reply); err != nil {
+		return nil, err
+	}
+	return reply.Token, nil
+}
+
+type releasingKeyStore struct {
+	devices.KeyStore
+	client *Client
+}
+
+func (k *releasingKeyStore) Delete(ctx context.Context, deviceID uuid.UUID) error {
+	versions, err := k.KeyStore.Versions(ctx, deviceID)
+	if err != nil {
+		return err
+	}
+	handles := make([][]byte, 0, len(versions))
+	for _, version := range versions {
+		material, err := k.KeyStore.Load(ctx, deviceID, version)
+		if err != nil && !errors.Is(err, domain.ErrKeyMaterialMissing) {
+			return err
+		}
+		handles = append(handles, keyHandles(material)...)
+	}
+	if err := k.KeyStore.Delete(ctx, deviceID); err != nil {
+		return err
+	}
+	for _, handle := range handles {
+		if err := k.client.Destroy(handle); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// keyHandles lists the daemon key handles held by material.
+func keyHandles(material domain.KeyMaterial) [][]byte {
+	var handles [][]byte
+	if len(material.Private) > 0 {
+		handles = append(handles, material.Private)
+	}
+	if material.Secondary != nil && len(material.Secondary.Private) > 0 {
+		handles = append(handles, material.Secondary.Private)
+	}
+	return handles
+}
@@ -0,0 +1,4 @@
+// Package signerd runs key generation and signing in a separate process that
+// owns every private key. The API process talks to it over a Unix domain socket
+// through Client and only ever holds opaque key handles.
+package signerd
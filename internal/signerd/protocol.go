@@ -0,0 +1,193 @@
+package signerd
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/fiskaly/coding-challenges/signing-service-challenge/domain"
+	"github.com/fiskaly/coding-challenges/signing-service-challenge/pkg/crypto"
+	"github.com/google/uuid"
+)
+
+// serviceName is the net/rpc name the daemon registers its methods under.
+const serviceName = "Signer"
+
+// handlePrefix marks KeyMaterial.Private values that reference a daemon-held key.
+const handlePrefix = "signerd-key:"
+
+// GenerateArgs asks the daemon for a fresh key pair.
+type GenerateArgs struct {
+	Algorithm domain.Algorithm
+	KeySpec   domain.KeySpec
+}
+
+// ImportArgs hands a caller-supplied private key to the daemon.
+type ImportArgs struct {
+	Algorithm  domain.Algorithm
+	PrivateKey []byte
+}
+
+// KeyReply describes a key now owned by the daemon.
+type KeyReply struct {
+	KeySpec domain.KeySpec
+	Public  []byte
+	Handle  []byte
+	Err     *Error
+}
+
+// SignArgs asks the daemon to sign data with the key behind Handle using the device parameters.
+type SignArgs struct {
+	Device domain.Device
+	Handle []byte
+	Data   []byte
+}
+
+// SignReply carries the raw signature.
+type SignReply struct {
+	Signature []byte
+	Err       *Error
+}
+
+// VerifyArgs asks the daemon to check a MAC with the secret behind Handle.
+type VerifyArgs struct {
+	Device    domain.Device
+	Handle    []byte
+	Data      []byte
+	Signature []byte
+}
+
+// VerifyReply carries the verification outcome.
+type VerifyReply struct {
+	Valid bool
+	Err   *Error
+}
+
+// DestroyArgs asks the daemon to forget a key.
+type DestroyArgs struct {
+	Handle []byte
+}
+
+// DestroyReply acknowledges a destroyed key.
+type DestroyReply struct {
+	Err *Error
+}
+
+// IssueArgs asks the daemon's certificate authority to certify a device public key.
+type IssueArgs struct {
+	Device    domain.Device
+	Public    []byte
+	NotBefore time.Time
+}
+
+// RootArgs asks for the root certificate of the daemon's certificate authority.
+type RootArgs struct{}
+
+// CertificateReply carries a DER certificate.
+type CertificateReply struct {
+	Certificate []byte
+	Err         *Error
+}
+
+// TimestampArgs asks the daemon's time-stamping authority for a token over Digest.
+type TimestampArgs struct {
+	Digest []byte
+}
+
+// TimestampReply carries a DER TimeStampToken.
+type TimestampReply struct {
+	Token []byte
+	Err   *Error
+}
+
+// KeyPoolStatsArgs asks for the counters of the daemon's key pool.
+type KeyPoolStatsArgs struct{}
+
+// KeyPoolStatsReply carries the key pool counters; Pools is empty without a pool.
+type KeyPoolStatsReply struct {
+	Pools []crypto.KeyPoolStats
+	Err   *Error
+}
+
+// remoteReply is implemented by every reply so the client can surface daemon errors.
+type remoteReply interface {
+	remoteError() *Error
+}
+
+func (r *KeyReply) remoteError() *Error          { return r.Err }
+func (r *SignReply) remoteError() *Error         { return r.Err }
+func (r *VerifyReply) remoteError() *Error       { return r.Err }
+func (r *DestroyReply) remoteError() *Error      { return r.Err }
+func (r *CertificateReply) remoteError() *Error  { return r.Err }
+func (r *TimestampReply) remoteError() *Error    { return r.Err }
+func (r *KeyPoolStatsReply) remoteError() *Error { return r.Err }
+
+// Error transports domain errors across the socket so callers keep their HTTP
+// mapping. Domain errors are rebuilt with the same fields, so errors.Is matches
+// sentinels such as domain.ErrKeyInUse and domain.ErrKeyMaterialMissing.
+type Error struct {
+	Kind     string // "validation", "not_found", "conflict", "internal" or "other"
+	Field    string
+	Message  string
+	Resource string
+	ID       string
+}
+
+func encodeError(err error) *Error {
+	if err == nil {
+		return nil
+	}
+	var (
+		validation domain.ValidationError
+		notFound   domain.NotFoundError
+		conflict   domain.ConflictError
+		internal   domain.InternalError
+	)
+	switch {
+	case errors.As(err, &validation):
+		return &Error{Kind: "validation", Field: validation.Field, Message: validation.Message}
+	case errors.As(err, &notFound):
+		return &Error{Kind: "not_found", Resource: notFound.Resource, ID: notFound.ID}
+	case errors.As(err, &conflict):
+		return &Error{Kind: "conflict", Message: conflict.Reason}
+	case errors.As(err, &internal):
+		return &Error{Kind: "internal", Message: internal.Reason}
+	default:
+		return &Error{Kind: "other", Message: err.Error()}
+	}
+}
+
+func (e *Error) decode() error {
+	if e == nil {
+		return nil
+	}
+	switch e.Kind {
+	case "validation":
+		return domain.ValidationError{Field: e.Field, Message: e.Message}
+	case "not_found":
+		return domain.NotFoundError{Resource: e.Resource, ID: e.ID}
+	case "conflict":
+		return domain.ConflictError{Reason: e.Message}
+	case "internal":
+		return domain.InternalError{Reason: e.Message}
+	default:
+		return fmt.Errorf("signer daemon: %s", e.Message)
+	}
+}
+
+func newHandle(keyID uuid.UUID) []byte {
+	return []byte(handlePrefix + keyID.String())
+}
+
+func parseHandle(handle []byte) (uuid.UUID, error) {
+	value, ok := strings.CutPrefix(string(handle), handlePrefix)
+	if !ok {
+		return uuid.Nil, errors.New("key material is not a signer daemon handle")
+	}
+	keyID, err := uuid.Parse(value)
+	if err != nil {
+		return uuid.Nil, fmt.Errorf("malformed signer daemon handle: %w", err)
+	}
+	return keyID, nil
+}
@@ -0,0 +1,248 @@
+package signerd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/rpc"
+	"os"
+
+	"github.com/fiskaly/coding-challenges/signing-service-challenge/domain"
+	"github.com/fiskaly/coding-challenges/signing-service-challenge/internal/devices"
+	"github.com/fiskaly/coding-challenges/signing-service-challenge/pkg/crypto"
+	"github.com/google/uuid"
+)
+
+// Server is the daemon side: it generates, imports and stores private keys and
+// signs on behalf of the API process.
+type Server struct {
+	rpc     *rpc.Server
+	service *rpcService
+}
+
+// NewServer exposes the given crypto ports over net/rpc. Keys are kept in
+// keys under random key IDs that never leave the daemon in usable form.
+func NewServer(algorithms domain.Algorithms, keys devices.KeyStore, generator devices.KeyGenerator, importer devices.KeyImporter, factory devices.SignerFactory) (*Server, error) {
+	server := rpc.NewServer()
+	service := &rpcService{algorithms: algorithms, keys: keys, generator: generator, importer: importer, factory: factory}
+	if err := server.RegisterName(serviceName, service); err != nil {
+		return nil, fmt.Errorf("register signer service: %w", err)
+	}
+	return &Server{rpc: server, service: service}, nil
+}
+
+// WithCertificateAuthority has the daemon certify device keys, so the CA key
+// stays out of the API process. Call it before Serve.
+func (s *Server) WithCertificateAuthority(issuer devices.CertificateIssuer) {
+	if issuer != nil {
+		s.service.issuer = issuer
+	}
+}
+
+// WithTimestamper has the daemon issue time-stamp tokens, so the TSA key stays
+// out of the API process. Call it before Serve.
+func (s *Server) WithTimestamper(timestamper devices.Timestamper) {
+	if timestamper != nil {
+		s.service.timestamper = timestamper
+	}
+}
+
+// WithKeyPool reports the counters of pool, which feeds the daemon's key
+// generator, to clients and stops it on Close. Call it before Serve.
+func (s *Server) WithKeyPool(pool *crypto.KeyPool) {
+	if pool != nil {
+		s.service.pool = pool
+	}
+}
+
+// Close stops the daemon's background work, i.e. the key pool workers. Call it
+// once Serve has returned.
+func (s *Server) Close() {
+	if s.service.pool != nil {
+		s.service.pool.Close()
+	}
+}
+
+// Serve answers connections on listener until it is closed.
+func (s *Server) Serve(listener net.Listener) error {
+	for {
+		conn, err := listener.Accept()
+		if err != nil {
+			if errors.Is(err, net.ErrClosed) {
+				return nil
+			}
+			return err
+		}
+		go s.rpc.ServeConn(conn)
+	}
+}
+
+// Listen opens the Unix socket at path, replacing a stale socket file and
+// restricting access to the daemon's user.
+func Listen(path string) (net.Listener, error) {
+	if err := os.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf("remove stale socket: %w", err)
+	}
+	listener, err := net.Listen("unix", path)
+	if err != nil {
+		return nil, err
+	}
+	if err := os.Chmod(path, 0o600); err != nil {
+		listener.Close()
+		return nil, fmt.Errorf("restrict socket permissions: %w", err)
+	}
+	return listener, nil
+}
+
+// rpcService holds the net/rpc methods; domain errors travel in the replies.
+type rpcService struct {
+	algorithms  domain.Algorithms
+	keys        devices.KeyStore
+	generator   devices.KeyGenerator
+	importer    devices.KeyImporter
+	factory     devices.SignerFactory
+	issuer      devices.CertificateIssuer
+	timestamper devices.Timestamper
+	pool        *crypto.KeyPool
+}
+
+func (s *rpcService) Generate(args GenerateArgs, reply *KeyReply) error {
+	material, err := s.generator.Generate(args.Algorithm, args.KeySpec)
+	if err != nil {
+		reply.Err = encodeError(err)
+		return nil
+	}
+	*reply, err = s.keep(args.KeySpec, material)
+	reply.Err = encodeError(err)
+	return nil
+}
+
+func (s *rpcService) Import(args ImportArgs, reply *KeyReply) error {
+	if s.importer == nil {
+		reply.Err = encodeError(domain.InternalError{Reason: "key import not configured"})
+		return nil
+	}
+	imported, err := s.importer.Import(args.Algorithm, args.PrivateKey)
+	if err != nil {
+		reply.Err = encodeError(err)
+		return nil
+	}
+	*reply, err = s.keep(imported.KeySpec, imported.Material)
+	reply.Err = encodeError(err)
+	return nil
+}
+
+func (s *rpcService) Sign(args SignArgs, reply *SignReply) error {
+	signature, err := s.sign(args)
+	reply.Signature = signature
+	reply.Err = encodeError(err)
+	return nil
+}
+
+func (s *rpcService) Verify(args VerifyArgs, reply *VerifyReply) error {
+	valid, err := s.verify(args)
+	reply.Valid = valid
+	reply.Err = encodeError(err)
+	return nil
+}
+
+func (s *rpcService) Destroy(args DestroyArgs, reply *DestroyReply) error {
+	keyID, err := parseHandle(args.Handle)
+	if err == nil {
+		err = s.keys.Delete(context.Background(), keyID)
+	}
+	reply.Err = encodeError(err)
+	return nil
+}
+
+func (s *rpcService) Issue(args IssueArgs, reply *CertificateReply) error {
+	if s.issuer == nil {
+		reply.Err = encodeError(errNoCertificateAuthority)
+		return nil
+	}
+	certificate, err := s.issuer.Issue(args.Device, args.Public, args.NotBefore)
+	reply.Certificate = certificate
+	reply.Err = encodeError(err)
+	return nil
+}
+
+func (s *rpcService) Root(_ RootArgs, reply *CertificateReply) error {
+	if s.issuer == nil {
+		reply.Err = encodeError(errNoCertificateAuthority)
+		return nil
+	}
+	reply.Certificate = s.issuer.Root()
+	return nil
+}
+
+func (s *rpcService) Timestamp(args TimestampArgs, reply *TimestampReply) error {
+	if s.timestamper == nil {
+		reply.Err = encodeError(domain.InternalError{Reason: "time-stamping authority not configured"})
+		return nil
+	}
+	token, err := s.timestamper.Timestamp(context.Background(), args.Digest)
+	reply.Token = token
+	reply.Err = encodeError(err)
+	return nil
+}
+
+func (s *rpcService) KeyPoolStats(_ KeyPoolStatsArgs, reply *KeyPoolStatsReply) error {
+	if s.pool != nil {
+		reply.Pools = s.pool.Stats()
+	}
+	return nil
+}
+
+var errNoCertificateAuthority = domain.InternalError{Reason: "certificate authority not configured"}
+
+// keyVersion is the only version used in the daemon key store: rotated device
+// keys get a new key ID instead.
+const keyVersion = domain.InitialKeyVersion
+
+// keep stores material under a new key ID and returns the handle for it.
+func (s *rpcService) keep(spec domain.KeySpec, material domain.KeyMaterial) (KeyReply, error) {
+	keyID := uuid.New()
+	if err := s.keys.Store(context.Background(), keyID, keyVersion, material); err != nil {
+		return KeyReply{}, err
+	}
+	return KeyReply{KeySpec: spec, Public: material.Public, Handle: newHandle(keyID)}, nil
+}
+
+func (s *rpcService) sign(args SignArgs) ([]byte, error) {
+	material, err := s.load(args.Handle)
+	if err != nil {
+		return nil, err
+	}
+	signer, err := s.factory.SignerFor(args.Device, material)
+	if err != nil {
+		return nil, err
+	}
+	return signer.Sign(args.Data)
+}
+
+// verify checks a MAC; only symmetric algorithms need the daemon-held secret
+// for verification, every other verifier is built by the client.
+func (s *rpcService) verify(args VerifyArgs) (bool, error) {
+	if !domain.IsSymmetric(s.algorithms, args.Device.Algorithm) {
+		return false, domain.ValidationError{Field: "algorithm", Message: fmt.Sprintf("%s signatures are verified with the public key", args.Device.Algorithm)}
+	}
+	material, err := s.load(args.Handle)
+	if err != nil {
+		return false, err
+	}
+	verifier, err := s.factory.VerifierFor(args.Device, material)
+	if err != nil {
+		return false, err
+	}
+	return verifier.Verify(args.Data, args.Signature)
+}
+
+// load fetches the key material behind a handle.
+func (s *rpcService) load(handle []byte) (domain.KeyMaterial, error) {
+	keyID, err := parseHandle(handle)
+	if err != nil {
+		return domain.KeyMaterial{}, err
+	}
+	return s.keys.Load(context.Background(), keyID, keyVersion)
+}
@@ -1,7 +1,11 @@
 package main
 
 import (
+	"context"
 	"log"
+	"os"
+	"os/signal"
+	"syscall"
 
 	"github.com/fiskaly/coding-challenges/signing-service-challenge/internal/app"
 	"github.com/fiskaly/coding-challenges/signing-service-challenge/internal/config"
@@ -10,9 +14,14 @@ import (
 func main() {
 	cfg := config.Load()
 
-	server := app.NewServer(cfg.ListenAddress)
+	server, err := app.NewServer(cfg)
+	if err != nil {
+		log.Fatalf("could not configure server: %v", err)
+	}
 
-	if err := server.Run(); err != nil {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	if err := server.Run(ctx); err != nil {
 		log.Fatalf("could not start server on %s: %v", cfg.ListenAddress, err)
 	}
 }
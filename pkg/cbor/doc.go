@@ -0,0 +1,3 @@
+// Package cbor contains a small deterministic CBOR (RFC 8949) encoder covering
+// the data model needed for COSE structures.
+package cbor
@@ -0,0 +1,163 @@
+package cbor
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"math"
+	"sort"
+)
+
+// Major types (RFC 8949 section 3.1).
+const (
+	majorUnsigned byte = 0
+	majorNegative byte = 1
+	majorBytes    byte = 2
+	majorText     byte = 3
+	majorArray    byte = 4
+	majorMap      byte = 5
+	majorTag      byte = 6
+	majorSimple   byte = 7
+)
+
+// Simple values (RFC 8949 section 3.3).
+const (
+	simpleFalse byte = 20
+	simpleTrue  byte = 21
+	simpleNull  byte = 22
+)
+
+// Tag wraps a data item with a semantic tag number, e.g. 18 for COSE_Sign1.
+type Tag struct {
+	Number  uint64
+	Content any
+}
+
+// RawMessage is an already encoded data item embedded as is.
+type RawMessage []byte
+
+// Marshal encodes v using the core deterministic encoding requirements of
+// RFC 8949 section 4.2.1: integers and lengths take their shortest form and
+// map keys are sorted by the bytewise order of their encodings.
+//
+// Supported values are nil, bool, signed and unsigned integers, []byte,
+// string, []any, map[any]any, Tag and RawMessage.
+func Marshal(v any) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := encode(&buf, v); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func encode(buf *bytes.Buffer, v any) error {
+	switch value := v.(type) {
+	case nil:
+		buf.WriteByte(majorSimple<<5 | simpleNull)
+	case bool:
+		if value {
+			buf.WriteByte(majorSimple<<5 | simpleTrue)
+		} else {
+			buf.WriteByte(majorSimple<<5 | simpleFalse)
+		}
+	case int:
+		encodeInt(buf, int64(value))
+	case int64:
+		encodeInt(buf, value)
+	case int32:
+		encodeInt(buf, int64(value))
+	case uint:
+		writeHead(buf, majorUnsigned, uint64(value))
+	case uint64:
+		writeHead(buf, majorUnsigned, value)
+	case uint32:
+		writeHead(buf, majorUnsigned, uint64(value))
+	case []byte:
+		writeHead(buf, majorBytes, uint64(len(value)))
+		buf.Write(value)
+	case string:
+		writeHead(buf, majorText, uint64(len(value)))
+		buf.WriteString(value)
+	case []any:
+		writeHead(buf, majorArray, uint64(len(value)))
+		for _, item := range value {
+			if err := encode(buf, item); err != nil {
+				return err
+			}
+		}
+	case map[any]any:
+		return encodeMap(buf, value)
+	case Tag:
+		writeHead(buf, majorTag, value.Number)
+		return encode(buf, value.Content)
+	case RawMessage:
+		if len(value) == 0 {
+			return fmt.Errorf("cbor: empty raw message")
+		}
+		buf.Write(value)
+	default:
+		return fmt.Errorf("cbor: unsupported type %T", v)
+	}
+	return nil
+}
+
+func encodeInt(buf *bytes.Buffer, value int64) {
+	if value >= 0 {
+		writeHead(buf, majorUnsigned, uint64(value))
+		return
+	}
+	// -1 - value cannot overflow for any negative int64.
+	writeHead(buf, majorNegative, uint64(-1-value))
+}
+
+type mapEntry struct {
+	key   []byte
+	value any
+}
+
+func encodeMap(buf *bytes.Buffer, m map[any]any) error {
+	entries := make([]mapEntry, 0, len(m))
+	for key, value := range m {
+		encoded, err := Marshal(key)
+		if err != nil {
+			return err
+		}
+		entries = append(entries, mapEntry{key: encoded, value: value})
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		return bytes.Compare(entries[i].key, entries[j].key) < 0
+	})
+
+	writeHead(buf, majorMap, uint64(len(entries)))
+	for i, entry := range entries {
+		if i > 0 && bytes.Equal(entries[i-1].key, entry.key) {
+			return fmt.Errorf("cbor: duplicate map key %x", entry.key)
+		}
+		buf.Write(entry.key)
+		if err := encode(buf, entry.value); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// writeHead writes the initial byte and argument of a data item in the
+// shortest form.
+func writeHead(buf *bytes.Buffer, major byte, argument uint64) {
+	switch {
+	case argument < 24:
+		buf.WriteByte(major<<5 | byte(argument))
+	case argument <= math.MaxUint8:
+		buf.WriteByte(major<<5 | 24)
+		buf.WriteByte(byte(argument))
+	case argument <= math.MaxUint16:
+		buf.WriteByte(major<<5 | 25)
+		buf.Write(binary.BigEndian.AppendUint16(nil, uint16(argument)))
+	case argument <= math.MaxUint32:
+		buf.WriteByte(major<<5 | 26)
+		buf.Write(binary.BigEndian.AppendUint32(nil, uint32(argument)))
+	default:
+		buf.WriteByte(major<<5 | 27)
+		buf.Write(binary.BigEndian.AppendUint64(nil, argument))
+	}
+}
@@ -0,0 +1,78 @@
+package cbor
+
+import (
+	"encoding/hex"
+	"math"
+	"testing"
+)
+
+// TestMarshalVectors checks the encodings listed in RFC 8949 appendix A.
+func TestMarshalVectors(t *testing.T) {
+	for _, tc := range []struct {
+		value    any
+		expected string
+	}{
+		{0, "00"},
+		{1, "01"},
+		{10, "0a"},
+		{23, "17"},
+		{24, "1818"},
+		{25, "1819"},
+		{100, "1864"},
+		{1000, "1903e8"},
+		{1000000, "1a000f4240"},
+		{uint64(1000000000000), "1b000000e8d4a51000"},
+		{uint64(math.MaxUint64), "1bffffffffffffffff"},
+		{-1, "20"},
+		{-10, "29"},
+		{-100, "3863"},
+		{-1000, "3903e7"},
+		{int64(math.MinInt64), "3b7fffffffffffffff"},
+		{false, "f4"},
+		{true, "f5"},
+		{nil, "f6"},
+		{[]byte{}, "40"},
+		{[]byte{1, 2, 3, 4}, "4401020304"},
+		{"", "60"},
+		{"a", "6161"},
+		{"IETF", "6449455446"},
+		{"ü", "62c3bc"},
+		{[]any{}, "80"},
+		{[]any{1, 2, 3}, "83010203"},
+		{[]any{1, []any{2, 3}, []any{4, 5}}, "8301820203820405"},
+		{map[any]any{}, "a0"},
+		{map[any]any{1: 2, 3: 4}, "a201020304"},
+		{map[any]any{"a": 1, "b": []any{2, 3}}, "a26161016162820203"},
+		{Tag{Number: 1, Content: 1363896240}, "c11a514b67b0"},
+		{Tag{Number: 23, Content: []byte{1, 2, 3, 4}}, "d74401020304"},
+		{RawMessage{0x18, 0x64}, "1864"},
+	} {
+		encoded, err := Marshal(tc.value)
+		if err != nil {
+			t.Fatalf("marshal %#v: %v", tc.value, err)
+		}
+		if got := hex.EncodeToString(encoded); got != tc.expected {
+			t.Fatalf("marshal %#v: expected %s, got %s", tc.value, tc.expected, got)
+		}
+	}
+}
+
+func TestMarshalSortsMapKeys(t *testing.T) {
+	// Bytewise order of the encoded keys puts shorter heads first: 10, 100,
+	// -1, "z", "aa" (RFC 8949 section 4.2.1).
+	encoded, err := Marshal(map[any]any{"aa": 0, "z": 0, -1: 0, 100: 0, 10: 0})
+	if err != nil {
+		t.Fatalf("marshal: %v", err)
+	}
+	if got := hex.EncodeToString(encoded); got != "a50a001864002000617a0062616100" {
+		t.Fatalf("unexpected key order %s", got)
+	}
+}
+
+func TestMarshalRejectsUnsupportedValues(t *testing.T) {
+	for _, value := range []any{1.5, map[string]int{"a": 1}, struct{}{}, map[any]any{1.5: 1}, RawMessage(nil)} {
+		if _, err := Marshal(value); err == nil {
+			t.Fatalf("expected %#v to be rejected", value)
+		}
+	}
+}
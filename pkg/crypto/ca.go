@@ -0,0 +1,244 @@
+package crypto
+
+import (
+	stdlibcrypto "crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha1"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"errors"
+	"fmt"
+	"math/big"
+	"net/url"
+	"time"
+
+	"github.com/fiskaly/coding-challenges/signing-service-challenge/domain"
+)
+
+// Defaults applied by NewCertificateAuthority.
+const (
+	DefaultCACommonName          = "Signing Service Root CA"
+	DefaultCAValidity            = 10 * 365 * 24 * time.Hour
+	DefaultDeviceCertValidity    = 365 * 24 * time.Hour
+	certificateSerialNumberBytes = 16
+)
+
+// CertificateAuthorityConfig configures the service root CA.
+type CertificateAuthorityConfig struct {
+	// CommonName names the root certificate subject.
+	CommonName string
+	// Key is a PEM encoded root private key; nil generates an ECDSA P-384 key
+	// that lives as long as the process.
+	Key []byte
+	// Validity is the lifetime of the self-signed root certificate.
+	Validity time.Duration
+	// DeviceValidity is the lifetime of issued device certificates.
+	DeviceValidity time.Duration
+}
+
+// CertificateAuthority implements internal/devices.CertificateIssuer with a
+// self-signed root that directly certifies device public keys.
+type CertificateAuthority struct {
+	key            stdlibcrypto.Signer
+	root           *x509.Certificate
+	deviceValidity time.Duration
+}
+
+// NewCertificateAuthority loads or generates the root key and self-signs the root certificate.
+func NewCertificateAuthority(config CertificateAuthorityConfig) (*CertificateAuthority, error) {
+	if config.CommonName == "" {
+		config.CommonName = DefaultCACommonName
+	}
+	if config.Validity == 0 {
+		config.Validity = DefaultCAValidity
+	}
+	if config.DeviceValidity == 0 {
+		config.DeviceValidity = DefaultDeviceCertValidity
+	}
+	if config.Validity < 0 || config.DeviceValidity < 0 {
+		return nil, errors.New("certificate validity must be positive")
+	}
+
+	key, err := loadSigningKey(config.Key, "root")
+	if err != nil {
+		return nil, err
+	}
+	keyID, err := subjectKeyID(key.Public())
+	if err != nil {
+		return nil, err
+	}
+	serial, err := newSerialNumber()
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now().UTC()
+	template := &x509.Certificate{
+		SerialNumber:          serial,
+		Subject:               pkix.Name{CommonName: config.CommonName},
+		NotBefore:             now,
+		NotAfter:              now.Add(config.Validity),
+		KeyUsage:              x509.KeyUsageCertSign | x509.KeyUsageCRLSign,
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+		MaxPathLen:            1,
+		SubjectKeyId:          keyID,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, key.Public(), key)
+	if err != nil {
+		return nil, fmt.Errorf("create root certificate: %w", err)
+	}
+	root, err := x509.ParseCertificate(der)
+	if err != nil {
+		return nil, fmt.Errorf("parse root certificate: %w", err)
+	}
+
+	return &CertificateAuthority{key: key, root: root, deviceValidity: config.DeviceValidity}, nil
+}
+
+// Root returns the DER encoded root certificate, the trust anchor for device certificates.
+func (ca *CertificateAuthority) Root() []byte {
+	return ca.root.Raw
+}
+
+// Issue certifies a device public key. The subject carries the device ID as
+// serialNumber and the label (or the ID when unlabelled) as common name; the
+// ID is repeated as a urn:uuid URI SAN.
+func (ca *CertificateAuthority) Issue(device domain.Device, publicKeyPEM []byte, notBefore time.Time) ([]byte, error) {
+	public, err := NewPEMCodec().DecodePublic(publicKeyPEM)
+	if err != nil {
+		return nil, err
+	}
+	keyID, err := subjectKeyID(public)
+	if err != nil {
+		return nil, err
+	}
+	serial, err := newSerialNumber()
+	if err != nil {
+		return nil, err
+	}
+
+	commonName := device.Label
+	if commonName == "" {
+		commonName = device.ID.String()
+	}
+	notBefore = notBefore.UTC()
+	notAfter := notBefore.Add(ca.deviceValidity)
+	if notAfter.After(ca.root.NotAfter) {
+		notAfter = ca.root.NotAfter
+	}
+
+	template := &x509.Certificate{
+		SerialNumber: serial,
+		Subject: pkix.Name{
+			CommonName:   commonName,
+			SerialNumber: device.ID.String(),
+		},
+		URIs:                  []*url.URL{{Scheme: "urn", Opaque: "uuid:" + device.ID.String()}},
+		NotBefore:             notBefore,
+		NotAfter:              notAfter,
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageContentCommitment,
+		BasicConstraintsValid: true,
+		SubjectKeyId:          keyID,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, ca.root, public, ca.key)
+	if err != nil {
+		return nil, fmt.Errorf("issue device certificate: %w", err)
+	}
+	return der, nil
+}
+
+var (
+	oidExtensionExtKeyUsage = asn1.ObjectIdentifier{2, 5, 29, 37}
+	oidKeyPurposeTimeStamp  = asn1.ObjectIdentifier{1, 3, 6, 1, 5, 5, 7, 3, 8}
+)
+
+// IssueTimestamping certifies the key of a time-stamping authority until the
+// root expires. The certificate carries timeStamping as its only extended key
+// usage, marked critical as RFC 3161 section 2.3 requires; crypto/x509 would
+// mark it non-critical, so the extension is encoded here.
+func (ca *CertificateAuthority) IssueTimestamping(public stdlibcrypto.PublicKey, commonName string, notBefore time.Time) (*x509.Certificate, error) {
+	keyID, err := subjectKeyID(public)
+	if err != nil {
+		return nil, err
+	}
+	serial, err := newSerialNumber()
+	if err != nil {
+		return nil, err
+	}
+	usage, err := asn1.Marshal([]asn1.ObjectIdentifier{oidKeyPurposeTimeStamp})
+	if err != nil {
+		return nil, fmt.Errorf("marshal extended key usage: %w", err)
+	}
+
+	template := &x509.Certificate{
+		SerialNumber:          serial,
+		Subject:               pkix.Name{CommonName: commonName},
+		NotBefore:             notBefore.UTC(),
+		NotAfter:              ca.root.NotAfter,
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageContentCommitment,
+		ExtraExtensions:       []pkix.Extension{{Id: oidExtensionExtKeyUsage, Critical: true, Value: usage}},
+		BasicConstraintsValid: true,
+		SubjectKeyId:          keyID,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, ca.root, public, ca.key)
+	if err != nil {
+		return nil, fmt.Errorf("issue time-stamping certificate: %w", err)
+	}
+	certificate, err := x509.ParseCertificate(der)
+	if err != nil {
+		return nil, fmt.Errorf("parse time-stamping certificate: %w", err)
+	}
+	return certificate, nil
+}
+
+// loadSigningKey decodes the PEM private key of a service signing role (the
+// root CA or the TSA) or, without one, generates an ECDSA P-384 key.
+func loadSigningKey(pemBytes []byte, role string) (stdlibcrypto.Signer, error) {
+	if len(pemBytes) == 0 {
+		key, err := ecdsa.GenerateKey(elliptic.P384(), rand.Reader)
+		if err != nil {
+			return nil, fmt.Errorf("generate %s key: %w", role, err)
+		}
+		return key, nil
+	}
+
+	private, err := NewPEMCodec().DecodePrivate(pemBytes)
+	if err != nil {
+		return nil, fmt.Errorf("decode %s key: %w", role, err)
+	}
+	key, ok := private.(stdlibcrypto.Signer)
+	if !ok {
+		return nil, unexpectedKeyType(private)
+	}
+	return key, nil
+}
+
+// subjectKeyID derives the RFC 5280 method 1 key identifier: the SHA-1 of the
+// subjectPublicKey bit string.
+func subjectKeyID(public stdlibcrypto.PublicKey) ([]byte, error) {
+	spki, err := x509.MarshalPKIXPublicKey(public)
+	if err != nil {
+		return nil, fmt.Errorf("marshal public key: %w", err)
+	}
+	var info struct {
+		Algorithm pkix.AlgorithmIdentifier
+		PublicKey asn1.BitString
+	}
+	if _, err := asn1.Unmarshal(spki, &info); err != nil {
+		return nil, fmt.Errorf("parse public key: %w", err)
+	}
+	sum := sha1.Sum(info.PublicKey.Bytes)
+	return sum[:], nil
+}
+
+func newSerialNumber() (*big.Int, error) {
+	serial, err := rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), certificateSerialNumberBytes*8))
+	if err != nil {
+		return nil, fmt.Errorf("generate serial number: %w", err)
+	}
+	return serial, nil
+}
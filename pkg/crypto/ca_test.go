@@ -0,0 +1,117 @@
+package crypto
+
+import (
+	"bytes"
+	"crypto/x509"
+	"testing"
+	"time"
+
+	"github.com/fiskaly/coding-challenges/signing-service-challenge/domain"
+	"github.com/google/uuid"
+)
+
+func TestCertificateAuthorityIssuesDeviceCertificates(t *testing.T) {
+	ca, err := NewCertificateAuthority(CertificateAuthorityConfig{DeviceValidity: 24 * time.Hour})
+	if err != nil {
+		t.Fatalf("new CA: %v", err)
+	}
+	root, err := x509.ParseCertificate(ca.Root())
+	if err != nil {
+		t.Fatalf("parse root: %v", err)
+	}
+	if !root.IsCA || root.Subject.CommonName != DefaultCACommonName {
+		t.Fatalf("unexpected root certificate %s", root.Subject)
+	}
+
+	generator := NewDefaultKeyGenerator()
+	for _, algorithm := range []domain.Algorithm{domain.AlgorithmRSA, domain.AlgorithmECDSA, domain.AlgorithmEd25519} {
+		t.Run(string(algorithm), func(t *testing.T) {
+			material, err := generator.Generate(algorithm, "")
+			if err != nil {
+				t.Fatalf("generate: %v", err)
+			}
+			device := domain.Device{ID: uuid.New(), Algorithm: algorithm, Label: "till 1"}
+			notBefore := time.Now().UTC().Truncate(time.Second)
+
+			der, err := ca.Issue(device, material.Public, notBefore)
+			if err != nil {
+				t.Fatalf("issue: %v", err)
+			}
+			leaf, err := x509.ParseCertificate(der)
+			if err != nil {
+				t.Fatalf("parse leaf: %v", err)
+			}
+			if err := leaf.CheckSignatureFrom(root); err != nil {
+				t.Fatalf("leaf not signed by root: %v", err)
+			}
+			if _, err := leaf.Verify(x509.VerifyOptions{Roots: rootPool(root), CurrentTime: notBefore.Add(time.Hour)}); err != nil {
+				t.Fatalf("verify chain: %v", err)
+			}
+
+			if leaf.Subject.SerialNumber != device.ID.String() || leaf.Subject.CommonName != "till 1" {
+				t.Fatalf("unexpected subject %s", leaf.Subject)
+			}
+			if len(leaf.URIs) != 1 || leaf.URIs[0].String() != "urn:uuid:"+device.ID.String() {
+				t.Fatalf("unexpected URIs %v", leaf.URIs)
+			}
+			if !leaf.NotBefore.Equal(notBefore) || !leaf.NotAfter.Equal(notBefore.Add(24*time.Hour)) {
+				t.Fatalf("unexpected validity %s - %s", leaf.NotBefore, leaf.NotAfter)
+			}
+			if leaf.IsCA || leaf.KeyUsage&x509.KeyUsageDigitalSignature == 0 {
+				t.Fatal("expected an end-entity certificate for digital signatures")
+			}
+			spki, err := x509.MarshalPKIXPublicKey(leaf.PublicKey)
+			if err != nil {
+				t.Fatalf("marshal leaf key: %v", err)
+			}
+			public, _ := NewPEMCodec().DecodePublic(material.Public)
+			expected, _ := x509.MarshalPKIXPublicKey(public)
+			if !bytes.Equal(spki, expected) {
+				t.Fatal("certificate does not carry the device public key")
+			}
+		})
+	}
+}
+
+func TestCertificateAuthorityLoadsKey(t *testing.T) {
+	material, err := NewDefaultKeyGenerator().Generate(domain.AlgorithmECDSA, domain.KeySpecP256)
+	if err != nil {
+		t.Fatalf("generate: %v", err)
+	}
+	first, err := NewCertificateAuthority(CertificateAuthorityConfig{Key: material.Private, CommonName: "Test Root"})
+	if err != nil {
+		t.Fatalf("new CA: %v", err)
+	}
+	second, err := NewCertificateAuthority(CertificateAuthorityConfig{Key: material.Private, CommonName: "Test Root"})
+	if err != nil {
+		t.Fatalf("new CA: %v", err)
+	}
+
+	// Certificates issued before a restart keep verifying against the new root.
+	device := domain.Device{ID: uuid.New(), Algorithm: domain.AlgorithmECDSA}
+	der, err := first.Issue(device, material.Public, time.Now())
+	if err != nil {
+		t.Fatalf("issue: %v", err)
+	}
+	leaf, _ := x509.ParseCertificate(der)
+	root, _ := x509.ParseCertificate(second.Root())
+	if err := leaf.CheckSignatureFrom(root); err != nil {
+		t.Fatalf("expected the reloaded root to verify earlier certificates: %v", err)
+	}
+	if leaf.Subject.CommonName != device.ID.String() {
+		t.Fatalf("expected the device ID as common name of an unlabelled device, got %q", leaf.Subject.CommonName)
+	}
+
+	if _, err := NewCertificateAuthority(CertificateAuthorityConfig{Key: []byte("not a key")}); err == nil {
+		t.Fatal("expected malformed key to be rejected")
+	}
+	if _, err := NewCertificateAuthority(CertificateAuthorityConfig{DeviceValidity: -time.Hour}); err == nil {
+		t.Fatal("expected negative validity to be rejected")
+	}
+}
+
+func rootPool(root *x509.Certificate) *x509.CertPool {
+	pool := x509.NewCertPool()
+	pool.AddCert(root)
+	return pool
+}
@@ -0,0 +1,189 @@
+package crypto
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"fmt"
+	"math/big"
+	"sort"
+
+	"github.com/fiskaly/coding-challenges/signing-service-challenge/domain"
+	"github.com/fiskaly/coding-challenges/signing-service-challenge/internal/devices"
+)
+
+var (
+	oidContentData       = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 7, 1}
+	oidContentSignedData = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 7, 2}
+	oidRSAEncryption     = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 1, 1}
+)
+
+// CMS structure versions (RFC 5652 sections 5.1 and 5.3).
+const (
+	cmsVersionIssuerSerial = 1
+	cmsVersionKeyID        = 3
+)
+
+// errCMSEd25519 rejects CMS exports of Ed25519 signatures, which would need
+// signed attributes and thus a new signature.
+var errCMSEd25519 = domain.ValidationError{Field: "format", Message: "CMS export is not available for ED25519 signatures"}
+
+// CMSEncoder implements internal/devices.SignedDataEncoder. It wraps an
+// existing signature over the secured payload into a detached SignedData
+// without signed attributes, so the signature is checked against the content
+// itself (RFC 5652 section 5.4) and no new signature is needed.
+type CMSEncoder struct {
+	algorithms domain.Algorithms
+}
+
+var _ devices.SignedDataEncoder = CMSEncoder{}
+
+// NewCMSEncoder creates a CMSEncoder resolving default schemes through algorithms.
+func NewCMSEncoder(algorithms domain.Algorithms) CMSEncoder {
+	return CMSEncoder{algorithms: algorithms}
+}
+
+type cmsContentInfo struct {
+	ContentType asn1.ObjectIdentifier
+	Content     asn1.RawValue // [0] EXPLICIT
+}
+
+type cmsEncapsulatedContentInfo struct {
+	ContentType asn1.ObjectIdentifier
+	Content     asn1.RawValue `asn1:"optional,tag:0"` // [0] EXPLICIT OCTET STRING; absent when detached
+}
+
+type cmsSignedData struct {
+	Version          int
+	DigestAlgorithms []pkix.AlgorithmIdentifier `asn1:"set"`
+	EncapContentInfo cmsEncapsulatedContentInfo
+	Certificates     asn1.RawValue   `asn1:"optional"`
+	SignerInfos      []cmsSignerInfo `asn1:"set"`
+}
+
+type cmsSignerInfo struct {
+	Version            int
+	SignerIdentifier   asn1.RawValue
+	DigestAlgorithm    pkix.AlgorithmIdentifier
+	SignedAttributes   asn1.RawValue `asn1:"optional,tag:0"` // [0] IMPLICIT SET OF Attribute
+	SignatureAlgorithm pkix.AlgorithmIdentifier
+	Signature          []byte
+}
+
+type cmsIssuerAndSerialNumber struct {
+	Issuer       asn1.RawValue
+	SerialNumber *big.Int
+}
+
+// EncodeSignedData returns a DER ContentInfo holding a detached SignedData
+// for signature. The signer is identified by issuer and serial number of the
+// leaf of chain, which is embedded with the rest of the chain; without a chain
+// the subject key identifier of the public key is used instead. P1363 encoded
+// ECDSA signatures are converted to DER as CMS requires.
+func (e CMSEncoder) EncodeSignedData(device domain.Device, publicKeyPEM []byte, chain [][]byte, signature []byte) ([]byte, error) {
+	public, err := NewPEMCodec().DecodePublic(publicKeyPEM)
+	if err != nil {
+		return nil, err
+	}
+	if key, ok := public.(*ecdsa.PublicKey); ok && device.Encoding == domain.EncodingP1363 {
+		if signature, err = ECDSAFromP1363(key.Curve, signature); err != nil {
+			return nil, err
+		}
+	}
+	digestAlgorithm, signatureAlgorithm, err := cmsAlgorithms(e.algorithms, device)
+	if err != nil {
+		return nil, err
+	}
+
+	version := cmsVersionIssuerSerial
+	var identifier []byte
+	var certificates asn1.RawValue
+	if len(chain) > 0 {
+		leaf, err := x509.ParseCertificate(chain[0])
+		if err != nil {
+			return nil, fmt.Errorf("parse device certificate: %w", err)
+		}
+		if identifier, err = issuerAndSerialNumber(leaf); err != nil {
+			return nil, err
+		}
+		// DER orders SET OF members by their encodings.
+		sorted := append([][]byte(nil), chain...)
+		sort.Slice(sorted, func(i, j int) bool { return bytes.Compare(sorted[i], sorted[j]) < 0 })
+		certificates = asn1.RawValue{Class: asn1.ClassContextSpecific, Tag: 0, IsCompound: true, Bytes: bytes.Join(sorted, nil)}
+	} else {
+		keyID, err := subjectKeyID(public)
+		if err != nil {
+			return nil, err
+		}
+		version = cmsVersionKeyID
+		if identifier, err = asn1.Marshal(asn1.RawValue{Class: asn1.ClassContextSpecific, Tag: 0, Bytes: keyID}); err != nil {
+			return nil, fmt.Errorf("marshal signer identifier: %w", err)
+		}
+	}
+
+	signed, err := asn1.Marshal(cmsSignedData{
+		Version:          version,
+		DigestAlgorithms: []pkix.AlgorithmIdentifier{digestAlgorithm},
+		EncapContentInfo: cmsEncapsulatedContentInfo{ContentType: oidContentData},
+		Certificates:     certificates,
+		SignerInfos: []cmsSignerInfo{{
+			Version:            version,
+			SignerIdentifier:   asn1.RawValue{FullBytes: identifier},
+			DigestAlgorithm:    digestAlgorithm,
+			SignatureAlgorithm: signatureAlgorithm,
+			Signature:          signature,
+		}},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("marshal signed data: %w", err)
+	}
+	der, err := asn1.Marshal(cmsContentInfo{
+		ContentType: oidContentSignedData,
+		Content:     asn1.RawValue{Class: asn1.ClassContextSpecific, Tag: 0, IsCompound: true, Bytes: signed},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("marshal content info: %w", err)
+	}
+	return der, nil
+}
+
+// issuerAndSerialNumber encodes the SignerIdentifier naming certificate.
+func issuerAndSerialNumber(certificate *x509.Certificate) ([]byte, error) {
+	identifier, err := asn1.Marshal(cmsIssuerAndSerialNumber{
+		Issuer:       asn1.RawValue{FullBytes: certificate.RawIssuer},
+		SerialNumber: certificate.SerialNumber,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("marshal signer identifier: %w", err)
+	}
+	return identifier, nil
+}
+
+// cmsAlgorithms returns the digest and signature algorithm identifiers of a
+// SignerInfo for the device scheme and digest. PKCS#1 v1.5 uses rsaEncryption
+// (RFC 3370 section 3.2). Ed25519 is rejected: a stored signature covers the
+// payload directly, and verifiers such as OpenSSL only accept Ed25519
+// SignerInfos over signed attributes.
+func cmsAlgorithms(algorithms domain.Algorithms, device domain.Device) (pkix.AlgorithmIdentifier, pkix.AlgorithmIdentifier, error) {
+	signing, err := signatureAlgorithm(algorithms, device)
+	if err != nil {
+		return pkix.AlgorithmIdentifier{}, pkix.AlgorithmIdentifier{}, err
+	}
+	if signing.Algorithm.Equal(oidSignatureEd25519) {
+		return pkix.AlgorithmIdentifier{}, pkix.AlgorithmIdentifier{}, errCMSEd25519
+	}
+	digest := device.Digest
+	if digest == "" {
+		digest = domain.DigestSHA256
+	}
+	oid, ok := digestOIDs[digest]
+	if !ok {
+		return pkix.AlgorithmIdentifier{}, pkix.AlgorithmIdentifier{}, fmt.Errorf("no CMS digest algorithm for %s", digest)
+	}
+	if signing.Algorithm.Equal(rsaPKCS1v15OIDs[digest]) {
+		signing = pkix.AlgorithmIdentifier{Algorithm: oidRSAEncryption, Parameters: asn1.NullRawValue}
+	}
+	return pkix.AlgorithmIdentifier{Algorithm: oid}, signing, nil
+}
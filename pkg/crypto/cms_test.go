@@ -0,0 +1,256 @@
+package crypto
+
+import (
+	"bytes"
+	"crypto/x509"
+	"encoding/asn1"
+	"encoding/pem"
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/fiskaly/coding-challenges/signing-service-challenge/domain"
+	"github.com/google/uuid"
+)
+
+// parsedSignedData mirrors cmsSignedData with the optional certificates tagged
+// so the SignerInfos are not mistaken for them when decoding.
+type parsedSignedData struct {
+	Version          int
+	DigestAlgorithms asn1.RawValue
+	EncapContentInfo asn1.RawValue
+	Certificates     asn1.RawValue   `asn1:"optional,tag:0"`
+	SignerInfos      []cmsSignerInfo `asn1:"set"`
+}
+
+func parseSignedData(t *testing.T, der []byte) parsedSignedData {
+	t.Helper()
+	var info cmsContentInfo
+	if rest, err := asn1.Unmarshal(der, &info); err != nil || len(rest) != 0 {
+		t.Fatalf("parse content info: %v", err)
+	}
+	if !info.ContentType.Equal(oidContentSignedData) || info.Content.Tag != 0 || info.Content.Class != asn1.ClassContextSpecific {
+		t.Fatalf("unexpected content info %v", info.ContentType)
+	}
+	var signed parsedSignedData
+	if _, err := asn1.Unmarshal(info.Content.Bytes, &signed); err != nil {
+		t.Fatalf("parse signed data: %v", err)
+	}
+	if len(signed.SignerInfos) != 1 {
+		t.Fatalf("expected one signer info, got %d", len(signed.SignerInfos))
+	}
+	return signed
+}
+
+func assertDetached(t *testing.T, signed parsedSignedData) {
+	t.Helper()
+	var encapsulated cmsEncapsulatedContentInfo
+	if _, err := asn1.Unmarshal(signed.EncapContentInfo.FullBytes, &encapsulated); err != nil {
+		t.Fatalf("parse encapsulated content info: %v", err)
+	}
+	if !encapsulated.ContentType.Equal(oidContentData) || encapsulated.Content.FullBytes != nil {
+		t.Fatal("expected detached id-data content")
+	}
+}
+
+func TestCMSEncoderEncodeSignedData(t *testing.T) {
+	cases := []struct {
+		name      string
+		device    domain.Device
+		signature asn1.ObjectIdentifier
+		digest    asn1.ObjectIdentifier
+	}{
+		{"rsa-pkcs1", domain.Device{Algorithm: domain.AlgorithmRSA, Scheme: domain.SchemeRSAPKCS1v15, Digest: domain.DigestSHA384}, oidRSAEncryption, digestOIDs[domain.DigestSHA384]},
+		{"rsa-pss", domain.Device{Algorithm: domain.AlgorithmRSA, Scheme: domain.SchemeRSAPSS, Digest: domain.DigestSHA256, SaltLength: 20}, oidSignatureRSAPSS, digestOIDs[domain.DigestSHA256]},
+		{"ecdsa-der", domain.Device{Algorithm: domain.AlgorithmECDSA, Scheme: domain.SchemeECDSA, Digest: domain.DigestSHA384, Encoding: domain.EncodingDER}, ecdsaOIDs[domain.DigestSHA384], digestOIDs[domain.DigestSHA384]},
+		{"ecdsa-p1363", domain.Device{Algorithm: domain.AlgorithmECDSA, Scheme: domain.SchemeECDSA, Digest: domain.DigestSHA384, Encoding: domain.EncodingP1363}, ecdsaOIDs[domain.DigestSHA384], digestOIDs[domain.DigestSHA384]},
+	}
+
+	ca, err := NewCertificateAuthority(CertificateAuthorityConfig{})
+	if err != nil {
+		t.Fatalf("create CA: %v", err)
+	}
+	generator := NewDefaultKeyGenerator()
+	factory := NewSignerFactory()
+	encoder := NewCMSEncoder(DefaultRegistry())
+	content := []byte("1_payload_AQI=")
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			device := tc.device
+			device.ID = uuid.New()
+			material, err := generator.Generate(device.Algorithm, "")
+			if err != nil {
+				t.Fatalf("generate: %v", err)
+			}
+			signer, err := factory.SignerFor(device, material)
+			if err != nil {
+				t.Fatalf("signer: %v", err)
+			}
+			signature, err := signer.Sign(content)
+			if err != nil {
+				t.Fatalf("sign: %v", err)
+			}
+			leaf, err := ca.Issue(device, material.Public, time.Now())
+			if err != nil {
+				t.Fatalf("issue: %v", err)
+			}
+
+			der, err := encoder.EncodeSignedData(device, material.Public, [][]byte{leaf, ca.Root()}, signature)
+			if err != nil {
+				t.Fatalf("encode: %v", err)
+			}
+			signed := parseSignedData(t, der)
+			assertDetached(t, signed)
+			info := signed.SignerInfos[0]
+			if signed.Version != cmsVersionIssuerSerial || info.Version != cmsVersionIssuerSerial {
+				t.Fatalf("unexpected versions %d/%d", signed.Version, info.Version)
+			}
+			if !info.SignatureAlgorithm.Algorithm.Equal(tc.signature) || !info.DigestAlgorithm.Algorithm.Equal(tc.digest) {
+				t.Fatalf("unexpected algorithms %v/%v", info.SignatureAlgorithm.Algorithm, info.DigestAlgorithm.Algorithm)
+			}
+
+			certificate, err := x509.ParseCertificate(leaf)
+			if err != nil {
+				t.Fatalf("parse leaf: %v", err)
+			}
+			var sid cmsIssuerAndSerialNumber
+			if _, err := asn1.Unmarshal(info.SignerIdentifier.FullBytes, &sid); err != nil {
+				t.Fatalf("parse signer identifier: %v", err)
+			}
+			if !bytes.Equal(sid.Issuer.FullBytes, certificate.RawIssuer) || sid.SerialNumber.Cmp(certificate.SerialNumber) != 0 {
+				t.Fatal("signer identifier does not name the device certificate")
+			}
+			if !bytes.Contains(signed.Certificates.Bytes, leaf) || !bytes.Contains(signed.Certificates.Bytes, ca.Root()) {
+				t.Fatal("expected the chain to be embedded")
+			}
+
+			// CMS carries ECDSA signatures DER encoded whatever the device stores.
+			verifying := device
+			if verifying.Encoding != "" {
+				verifying.Encoding = domain.EncodingDER
+			}
+			verifier, err := factory.VerifierFor(verifying, material)
+			if err != nil {
+				t.Fatalf("verifier: %v", err)
+			}
+			if ok, err := verifier.Verify(content, info.Signature); err != nil || !ok {
+				t.Fatalf("embedded signature does not verify: %v", err)
+			}
+		})
+	}
+}
+
+func TestCMSEncoderWithoutCertificate(t *testing.T) {
+	device := domain.Device{ID: uuid.New(), Algorithm: domain.AlgorithmECDSA}
+	material, err := NewDefaultKeyGenerator().Generate(device.Algorithm, "")
+	if err != nil {
+		t.Fatalf("generate: %v", err)
+	}
+	der, err := NewCMSEncoder(DefaultRegistry()).EncodeSignedData(device, material.Public, nil, []byte("signature"))
+	if err != nil {
+		t.Fatalf("encode: %v", err)
+	}
+	signed := parseSignedData(t, der)
+	assertDetached(t, signed)
+	info := signed.SignerInfos[0]
+	if signed.Version != cmsVersionKeyID || info.Version != cmsVersionKeyID || signed.Certificates.FullBytes != nil {
+		t.Fatalf("expected a version 3 structure without certificates, got %d/%d", signed.Version, info.Version)
+	}
+
+	public, err := NewPEMCodec().DecodePublic(material.Public)
+	if err != nil {
+		t.Fatalf("decode public key: %v", err)
+	}
+	keyID, err := subjectKeyID(public)
+	if err != nil {
+		t.Fatalf("key id: %v", err)
+	}
+	if identifier := info.SignerIdentifier; identifier.Class != asn1.ClassContextSpecific || identifier.Tag != 0 || !bytes.Equal(identifier.Bytes, keyID) {
+		t.Fatalf("expected the subject key identifier as signer identifier, got %x", identifier.FullBytes)
+	}
+}
+
+func TestCMSEncoderRejectsEd25519(t *testing.T) {
+	device := domain.Device{ID: uuid.New(), Algorithm: domain.AlgorithmEd25519}
+	material, err := NewDefaultKeyGenerator().Generate(device.Algorithm, "")
+	if err != nil {
+		t.Fatalf("generate: %v", err)
+	}
+	var validation domain.ValidationError
+	if _, err := NewCMSEncoder(DefaultRegistry()).EncodeSignedData(device, material.Public, nil, []byte("signature")); !errors.As(err, &validation) {
+		t.Fatalf("expected a validation error for ED25519, got %v", err)
+	}
+}
+
+// TestCMSEncoderVerifiesWithOpenSSL checks the output of every supported
+// scheme with openssl cms -verify, the tool the API documentation points to.
+func TestCMSEncoderVerifiesWithOpenSSL(t *testing.T) {
+	openssl, err := exec.LookPath("openssl")
+	if err != nil {
+		t.Skip("openssl not on PATH")
+	}
+
+	ca, err := NewCertificateAuthority(CertificateAuthorityConfig{})
+	if err != nil {
+		t.Fatalf("create CA: %v", err)
+	}
+	dir := t.TempDir()
+	rootPath := filepath.Join(dir, "root.pem")
+	if err := os.WriteFile(rootPath, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: ca.Root()}), 0o600); err != nil {
+		t.Fatalf("write root: %v", err)
+	}
+
+	generator := NewDefaultKeyGenerator()
+	factory := NewSignerFactory()
+	encoder := NewCMSEncoder(DefaultRegistry())
+	content := []byte("1_payload_AQI=")
+	contentPath := filepath.Join(dir, "content.txt")
+	if err := os.WriteFile(contentPath, content, 0o600); err != nil {
+		t.Fatalf("write content: %v", err)
+	}
+
+	for name, device := range map[string]domain.Device{
+		"rsa-pkcs1":     {Algorithm: domain.AlgorithmRSA, Scheme: domain.SchemeRSAPKCS1v15, Digest: domain.DigestSHA256},
+		"rsa-pss":       {Algorithm: domain.AlgorithmRSA, Scheme: domain.SchemeRSAPSS, Digest: domain.DigestSHA256},
+		"ecdsa-der":     {Algorithm: domain.AlgorithmECDSA, Scheme: domain.SchemeECDSA, Digest: domain.DigestSHA384, Encoding: domain.EncodingDER},
+		"ecdsa-p1363":   {Algorithm: domain.AlgorithmECDSA, Scheme: domain.SchemeECDSA, Digest: domain.DigestSHA256, Encoding: domain.EncodingP1363},
+		"ecdsa-rfc6979": {Algorithm: domain.AlgorithmECDSA, Scheme: domain.SchemeECDSADeterministic, Digest: domain.DigestSHA256, Encoding: domain.EncodingDER},
+	} {
+		t.Run(name, func(t *testing.T) {
+			device.ID = uuid.New()
+			material, err := generator.Generate(device.Algorithm, "")
+			if err != nil {
+				t.Fatalf("generate: %v", err)
+			}
+			signer, err := factory.SignerFor(device, material)
+			if err != nil {
+				t.Fatalf("signer: %v", err)
+			}
+			signature, err := signer.Sign(content)
+			if err != nil {
+				t.Fatalf("sign: %v", err)
+			}
+			leaf, err := ca.Issue(device, material.Public, time.Now())
+			if err != nil {
+				t.Fatalf("issue: %v", err)
+			}
+			der, err := encoder.EncodeSignedData(device, material.Public, [][]byte{leaf, ca.Root()}, signature)
+			if err != nil {
+				t.Fatalf("encode: %v", err)
+			}
+			signaturePath := filepath.Join(dir, name+".p7s")
+			if err := os.WriteFile(signaturePath, der, 0o600); err != nil {
+				t.Fatalf("write signature: %v", err)
+			}
+
+			output, err := exec.Command(openssl, "cms", "-verify", "-binary", "-inform", "DER", "-in", signaturePath,
+				"-content", contentPath, "-CAfile", rootPath, "-purpose", "any", "-out", os.DevNull).CombinedOutput()
+			if err != nil {
+				t.Fatalf("openssl cms -verify failed: %v\n%s", err, output)
+			}
+		})
+	}
+}
@@ -0,0 +1,126 @@
+package crypto
+
+import (
+	stdlibcrypto "crypto"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"fmt"
+)
+
+// Standard PEM block types written by PEMCodec.
+const (
+	PEMTypePrivateKey = "PRIVATE KEY" // PKCS#8
+	PEMTypePublicKey  = "PUBLIC KEY"  // SubjectPublicKeyInfo
+)
+
+// Sentinel causes carried by MalformedKeyError.
+var (
+	ErrNoPEMBlock       = errors.New("no PEM block found")
+	ErrUnsupportedBlock = errors.New("unsupported PEM block type")
+)
+
+// MalformedKeyError reports key material that could not be decoded.
+type MalformedKeyError struct {
+	Kind      string // "private" or "public"
+	BlockType string // empty when no PEM block was found
+	Err       error
+}
+
+func (e MalformedKeyError) Error() string {
+	if e.BlockType == "" {
+		return fmt.Sprintf("malformed %s key: %v", e.Kind, e.Err)
+	}
+	return fmt.Sprintf("malformed %s key in %q block: %v", e.Kind, e.BlockType, e.Err)
+}
+
+func (e MalformedKeyError) Unwrap() error {
+	return e.Err
+}
+
+// PEMCodec writes private keys as PKCS#8 and public keys as SPKI under the
+// standard PEM headers. It also reads the legacy forms earlier releases stored:
+// PKCS#1 RSA keys under "RSA_PRIVATE_KEY"/"RSA_PUBLIC_KEY" and SEC1/SPKI EC keys
+// under "PRIVATE_KEY"/"PUBLIC_KEY", plus the "RSA PRIVATE KEY", "EC PRIVATE KEY"
+// and "RSA PUBLIC KEY" blocks produced by OpenSSL.
+type PEMCodec struct{}
+
+var _ KeyCodec = PEMCodec{}
+
+// NewPEMCodec creates a new PEMCodec.
+func NewPEMCodec() PEMCodec {
+	return PEMCodec{}
+}
+
+// Encode returns the SPKI public key and PKCS#8 private key as PEM.
+func (PEMCodec) Encode(private stdlibcrypto.PrivateKey) ([]byte, []byte, error) {
+	signer, ok := private.(stdlibcrypto.Signer)
+	if !ok {
+		return nil, nil, unexpectedKeyType(private)
+	}
+
+	privateDER, err := x509.MarshalPKCS8PrivateKey(private)
+	if err != nil {
+		return nil, nil, err
+	}
+	publicDER, err := x509.MarshalPKIXPublicKey(signer.Public())
+	if err != nil {
+		return nil, nil, err
+	}
+
+	encodedPublic := pem.EncodeToMemory(&pem.Block{Type: PEMTypePublicKey, Bytes: publicDER})
+	encodedPrivate := pem.EncodeToMemory(&pem.Block{Type: PEMTypePrivateKey, Bytes: privateDER})
+	return encodedPublic, encodedPrivate, nil
+}
+
+// DecodePrivate parses a private key in any supported PEM form.
+func (PEMCodec) DecodePrivate(pemBytes []byte) (stdlibcrypto.PrivateKey, error) {
+	block, _ := pem.Decode(pemBytes)
+	if block == nil {
+		return nil, MalformedKeyError{Kind: "private", Err: ErrNoPEMBlock}
+	}
+
+	var (
+		key stdlibcrypto.PrivateKey
+		err error
+	)
+	switch block.Type {
+	case PEMTypePrivateKey:
+		key, err = x509.ParsePKCS8PrivateKey(block.Bytes)
+	case "RSA PRIVATE KEY", "RSA_PRIVATE_KEY":
+		key, err = x509.ParsePKCS1PrivateKey(block.Bytes)
+	case "EC PRIVATE KEY", "PRIVATE_KEY":
+		key, err = x509.ParseECPrivateKey(block.Bytes)
+	default:
+		err = ErrUnsupportedBlock
+	}
+	if err != nil {
+		return nil, MalformedKeyError{Kind: "private", BlockType: block.Type, Err: err}
+	}
+	return key, nil
+}
+
+// DecodePublic parses a public key in any supported PEM form.
+func (PEMCodec) DecodePublic(pemBytes []byte) (stdlibcrypto.PublicKey, error) {
+	block, _ := pem.Decode(pemBytes)
+	if block == nil {
+		return nil, MalformedKeyError{Kind: "public", Err: ErrNoPEMBlock}
+	}
+
+	var (
+		key stdlibcrypto.PublicKey
+		err error
+	)
+	switch block.Type {
+	case PEMTypePublicKey, "PUBLIC_KEY":
+		key, err = x509.ParsePKIXPublicKey(block.Bytes)
+	case "RSA PUBLIC KEY", "RSA_PUBLIC_KEY":
+		key, err = x509.ParsePKCS1PublicKey(block.Bytes)
+	default:
+		err = ErrUnsupportedBlock
+	}
+	if err != nil {
+		return nil, MalformedKeyError{Kind: "public", BlockType: block.Type, Err: err}
+	}
+	return key, nil
+}
@@ -0,0 +1,146 @@
+package crypto
+
+import (
+	stdlibcrypto "crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestPEMCodec_WritesStandardBlocks(t *testing.T) {
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("generate rsa: %v", err)
+	}
+	ecKey, err := ecdsa.GenerateKey(elliptic.P384(), rand.Reader)
+	if err != nil {
+		t.Fatalf("generate ecdsa: %v", err)
+	}
+	_, edKey, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatalf("generate ed25519: %v", err)
+	}
+
+	codec := NewPEMCodec()
+	for _, key := range []stdlibcrypto.Signer{rsaKey, ecKey, edKey} {
+		public, private, err := codec.Encode(key)
+		if err != nil {
+			t.Fatalf("encode %T: %v", key, err)
+		}
+
+		privateBlock, _ := pem.Decode(private)
+		if privateBlock == nil || privateBlock.Type != PEMTypePrivateKey {
+			t.Fatalf("%T: expected PKCS#8 block, got %q", key, private)
+		}
+		if _, err := x509.ParsePKCS8PrivateKey(privateBlock.Bytes); err != nil {
+			t.Fatalf("%T: private key is not PKCS#8: %v", key, err)
+		}
+		publicBlock, _ := pem.Decode(public)
+		if publicBlock == nil || publicBlock.Type != PEMTypePublicKey {
+			t.Fatalf("%T: expected SPKI block, got %q", key, public)
+		}
+
+		decoded, err := codec.DecodePrivate(private)
+		if err != nil {
+			t.Fatalf("%T: decode private: %v", key, err)
+		}
+		if !reflect.DeepEqual(decoded.(stdlibcrypto.Signer).Public(), key.Public()) {
+			t.Fatalf("%T: private key did not round trip", key)
+		}
+		decodedPublic, err := codec.DecodePublic(public)
+		if err != nil {
+			t.Fatalf("%T: decode public: %v", key, err)
+		}
+		if !reflect.DeepEqual(decodedPublic, key.Public()) {
+			t.Fatalf("%T: public key did not round trip", key)
+		}
+	}
+}
+
+func TestPEMCodec_ReadsLegacyBlocks(t *testing.T) {
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("generate rsa: %v", err)
+	}
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("generate ecdsa: %v", err)
+	}
+	sec1, err := x509.MarshalECPrivateKey(ecKey)
+	if err != nil {
+		t.Fatalf("marshal sec1: %v", err)
+	}
+	ecSPKI, err := x509.MarshalPKIXPublicKey(&ecKey.PublicKey)
+	if err != nil {
+		t.Fatalf("marshal spki: %v", err)
+	}
+
+	codec := NewPEMCodec()
+	privates := map[string]*pem.Block{
+		"RSA_PRIVATE_KEY": {Type: "RSA_PRIVATE_KEY", Bytes: x509.MarshalPKCS1PrivateKey(rsaKey)},
+		"RSA PRIVATE KEY": {Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(rsaKey)},
+		"PRIVATE_KEY":     {Type: "PRIVATE_KEY", Bytes: sec1},
+		"EC PRIVATE KEY":  {Type: "EC PRIVATE KEY", Bytes: sec1},
+	}
+	for name, block := range privates {
+		if _, err := codec.DecodePrivate(pem.EncodeToMemory(block)); err != nil {
+			t.Fatalf("%s: decode private: %v", name, err)
+		}
+	}
+
+	publics := map[string]*pem.Block{
+		"RSA_PUBLIC_KEY": {Type: "RSA_PUBLIC_KEY", Bytes: x509.MarshalPKCS1PublicKey(&rsaKey.PublicKey)},
+		"RSA PUBLIC KEY": {Type: "RSA PUBLIC KEY", Bytes: x509.MarshalPKCS1PublicKey(&rsaKey.PublicKey)},
+		"PUBLIC_KEY":     {Type: "PUBLIC_KEY", Bytes: ecSPKI},
+	}
+	for name, block := range publics {
+		if _, err := codec.DecodePublic(pem.EncodeToMemory(block)); err != nil {
+			t.Fatalf("%s: decode public: %v", name, err)
+		}
+	}
+}
+
+func TestPEMCodec_MalformedInput(t *testing.T) {
+	codec := NewPEMCodec()
+
+	cases := map[string]struct {
+		input []byte
+		cause error
+	}{
+		"nil":           {nil, ErrNoPEMBlock},
+		"not pem":       {[]byte("garbage"), ErrNoPEMBlock},
+		"unknown block": {pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: []byte{0x30}}), ErrUnsupportedBlock},
+		"bad der":       {pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: []byte{0x30}}), nil},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			_, err := codec.DecodePrivate(tc.input)
+			var malformed MalformedKeyError
+			if !errors.As(err, &malformed) || malformed.Kind != "private" {
+				t.Fatalf("expected MalformedKeyError, got %v", err)
+			}
+			if tc.cause != nil && !errors.Is(err, tc.cause) {
+				t.Fatalf("expected cause %v, got %v", tc.cause, err)
+			}
+
+			if _, err := codec.DecodePublic(tc.input); !errors.As(err, &malformed) {
+				t.Fatalf("expected MalformedKeyError for public key, got %v", err)
+			}
+		})
+	}
+
+	rsaMarshaler := NewRSAMarshaler()
+	if _, err := rsaMarshaler.Unmarshal(nil); err == nil {
+		t.Fatal("expected RSAMarshaler to reject nil input")
+	}
+	if _, err := NewECCMarshaler().Decode([]byte("garbage")); err == nil {
+		t.Fatal("expected ECCMarshaler to reject garbage input")
+	}
+}
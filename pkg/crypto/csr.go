@@ -0,0 +1,290 @@
+package crypto
+
+import (
+	"bytes"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"encoding/pem"
+	"fmt"
+
+	"github.com/fiskaly/coding-challenges/signing-service-challenge/domain"
+	"github.com/fiskaly/coding-challenges/signing-service-challenge/internal/devices"
+)
+
+// maxChainLength bounds the number of certificates accepted by CheckChain.
+const maxChainLength = 16
+
+var (
+	oidExtensionRequest    = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 9, 14}
+	oidExtensionSubjectAlt = asn1.ObjectIdentifier{2, 5, 29, 17}
+	oidSignatureRSAPSS     = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 1, 10}
+	oidMGF1                = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 1, 8}
+	oidSignatureEd25519    = asn1.ObjectIdentifier{1, 3, 101, 112}
+)
+
+var digestOIDs = map[domain.Digest]asn1.ObjectIdentifier{
+	domain.DigestSHA256:   {2, 16, 840, 1, 101, 3, 4, 2, 1},
+	domain.DigestSHA384:   {2, 16, 840, 1, 101, 3, 4, 2, 2},
+	domain.DigestSHA512:   {2, 16, 840, 1, 101, 3, 4, 2, 3},
+	domain.DigestSHA3_256: {2, 16, 840, 1, 101, 3, 4, 2, 8},
+}
+
+var rsaPKCS1v15OIDs = map[domain.Digest]asn1.ObjectIdentifier{
+	domain.DigestSHA256:   {1, 2, 840, 113549, 1, 1, 11},
+	domain.DigestSHA384:   {1, 2, 840, 113549, 1, 1, 12},
+	domain.DigestSHA512:   {1, 2, 840, 113549, 1, 1, 13},
+	domain.DigestSHA3_256: {2, 16, 840, 1, 101, 3, 4, 3, 14},
+}
+
+var ecdsaOIDs = map[domain.Digest]asn1.ObjectIdentifier{
+	domain.DigestSHA256:   {1, 2, 840, 10045, 4, 3, 2},
+	domain.DigestSHA384:   {1, 2, 840, 10045, 4, 3, 3},
+	domain.DigestSHA512:   {1, 2, 840, 10045, 4, 3, 4},
+	domain.DigestSHA3_256: {2, 16, 840, 1, 101, 3, 4, 3, 10},
+}
+
+// CertificateRequester implements internal/devices.CertificateRequester. It
+// encodes PKCS#10 requests itself so the signature can come from any
+// devices.Signer, including one backed by the signer daemon, instead of a
+// local crypto.Signer.
+type CertificateRequester struct {
+	algorithms domain.Algorithms
+}
+
+var _ devices.CertificateRequester = CertificateRequester{}
+
+// NewCertificateRequester creates a CertificateRequester resolving default
+// schemes through algorithms.
+func NewCertificateRequester(algorithms domain.Algorithms) CertificateRequester {
+	return CertificateRequester{algorithms: algorithms}
+}
+
+type certificationRequestInfo struct {
+	Version    int
+	Subject    asn1.RawValue
+	PublicKey  asn1.RawValue
+	Attributes []asn1.RawValue `asn1:"tag:0"`
+}
+
+type certificationRequest struct {
+	Info      asn1.RawValue
+	Algorithm pkix.AlgorithmIdentifier
+	Signature asn1.BitString
+}
+
+type requestAttribute struct {
+	Type   asn1.ObjectIdentifier
+	Values []asn1.RawValue `asn1:"set"`
+}
+
+type pssParameters struct {
+	Hash         pkix.AlgorithmIdentifier `asn1:"explicit,tag:0"`
+	MGF          pkix.AlgorithmIdentifier `asn1:"explicit,tag:1"`
+	SaltLength   int                      `asn1:"explicit,tag:2"`
+	TrailerField int                      `asn1:"optional,explicit,tag:3,default:1"`
+}
+
+// CreateRequest returns a DER PKCS#10 request for the device public key. The
+// subject matches the certificates issued by CertificateAuthority: the device
+// ID as serialNumber and urn:uuid SAN, the label (or ID) as common name. The
+// signer must produce DER encoded signatures for ECDSA devices.
+func (r CertificateRequester) CreateRequest(device domain.Device, publicKeyPEM []byte, signer devices.Signer) ([]byte, error) {
+	public, err := NewPEMCodec().DecodePublic(publicKeyPEM)
+	if err != nil {
+		return nil, err
+	}
+	spki, err := x509.MarshalPKIXPublicKey(public)
+	if err != nil {
+		return nil, fmt.Errorf("marshal public key: %w", err)
+	}
+	algorithm, err := signatureAlgorithm(r.algorithms, device)
+	if err != nil {
+		return nil, err
+	}
+
+	commonName := device.Label
+	if commonName == "" {
+		commonName = device.ID.String()
+	}
+	subject, err := asn1.Marshal(pkix.Name{CommonName: commonName, SerialNumber: device.ID.String()}.ToRDNSequence())
+	if err != nil {
+		return nil, fmt.Errorf("marshal subject: %w", err)
+	}
+	attribute, err := extensionRequest("urn:uuid:" + device.ID.String())
+	if err != nil {
+		return nil, err
+	}
+
+	info, err := asn1.Marshal(certificationRequestInfo{
+		Subject:    asn1.RawValue{FullBytes: subject},
+		PublicKey:  asn1.RawValue{FullBytes: spki},
+		Attributes: []asn1.RawValue{attribute},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("marshal certification request info: %w", err)
+	}
+	signature, err := signer.Sign(info)
+	if err != nil {
+		return nil, fmt.Errorf("sign certification request: %w", err)
+	}
+
+	der, err := asn1.Marshal(certificationRequest{
+		Info:      asn1.RawValue{FullBytes: info},
+		Algorithm: algorithm,
+		Signature: asn1.BitString{Bytes: signature, BitLength: len(signature) * 8},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("marshal certification request: %w", err)
+	}
+	return der, nil
+}
+
+// CheckChain parses a PEM certificate chain, leaf first, and checks that the
+// leaf certifies publicKeyPEM and that every certificate is signed by the next
+// one. It returns the DER certificates; chain problems are validation errors.
+func (CertificateRequester) CheckChain(publicKeyPEM []byte, chainPEM []byte) ([][]byte, error) {
+	public, err := NewPEMCodec().DecodePublic(publicKeyPEM)
+	if err != nil {
+		return nil, err
+	}
+	spki, err := x509.MarshalPKIXPublicKey(public)
+	if err != nil {
+		return nil, fmt.Errorf("marshal public key: %w", err)
+	}
+
+	var certificates []*x509.Certificate
+	rest := chainPEM
+	for {
+		var block *pem.Block
+		block, rest = pem.Decode(rest)
+		if block == nil {
+			break
+		}
+		if block.Type != "CERTIFICATE" {
+			return nil, chainError("unexpected PEM block " + block.Type)
+		}
+		if len(certificates) == maxChainLength {
+			return nil, chainError(fmt.Sprintf("chain exceeds %d certificates", maxChainLength))
+		}
+		certificate, err := x509.ParseCertificate(block.Bytes)
+		if err != nil {
+			return nil, chainError(fmt.Sprintf("certificate %d: %v", len(certificates), err))
+		}
+		certificates = append(certificates, certificate)
+	}
+	if len(certificates) == 0 {
+		return nil, chainError("no PEM encoded certificates found")
+	}
+	if len(bytes.TrimSpace(rest)) != 0 {
+		return nil, chainError("unexpected data after the last certificate")
+	}
+
+	if !bytes.Equal(certificates[0].RawSubjectPublicKeyInfo, spki) {
+		return nil, chainError("leaf certificate does not match the device public key")
+	}
+	chain := make([][]byte, len(certificates))
+	for i, certificate := range certificates {
+		if i+1 < len(certificates) {
+			if err := certificate.CheckSignatureFrom(certificates[i+1]); err != nil {
+				return nil, chainError(fmt.Sprintf("certificate %d is not signed by certificate %d: %v", i, i+1, err))
+			}
+		}
+		chain[i] = certificate.Raw
+	}
+	return chain, nil
+}
+
+func chainError(message string) error {
+	return domain.ValidationError{Field: "certificate", Message: message}
+}
+
+// signatureAlgorithm returns the X.509 algorithm identifier for the device scheme and digest.
+// Devices created before schemes and digests were configurable use the
+// default scheme from algorithms and SHA-256, like the signers do.
+func signatureAlgorithm(algorithms domain.Algorithms, device domain.Device) (pkix.AlgorithmIdentifier, error) {
+	scheme := device.Scheme
+	if scheme == "" {
+		var err error
+		if scheme, err = domain.ResolveScheme(algorithms, device.Algorithm, ""); err != nil {
+			return pkix.AlgorithmIdentifier{}, err
+		}
+	}
+	digest := device.Digest
+	if digest == "" && scheme != domain.SchemeEd25519 {
+		digest = domain.DigestSHA256
+	}
+
+	var identifier pkix.AlgorithmIdentifier
+	switch scheme {
+	case domain.SchemeEd25519:
+		return pkix.AlgorithmIdentifier{Algorithm: oidSignatureEd25519}, nil
+	case domain.SchemeRSAPSS:
+		return pssAlgorithm(digest, device.SaltLength)
+	case domain.SchemeRSAPKCS1v15:
+		identifier.Algorithm = rsaPKCS1v15OIDs[digest]
+		// The SHA-2 variants carry NULL parameters, the SHA-3 ones none (RFC 4055, NIST CSOR).
+		if digest != domain.DigestSHA3_256 {
+			identifier.Parameters = asn1.NullRawValue
+		}
+	case domain.SchemeECDSA, domain.SchemeECDSADeterministic:
+		identifier.Algorithm = ecdsaOIDs[digest]
+	}
+	if identifier.Algorithm == nil {
+		return pkix.AlgorithmIdentifier{}, fmt.Errorf("no X.509 signature algorithm for %s with %s", scheme, digest)
+	}
+	return identifier, nil
+}
+
+// pssAlgorithm encodes RSASSA-PSS-params (RFC 4055) with MGF1 over the same
+// digest. A zero salt length means the digest size, matching the signer.
+func pssAlgorithm(digest domain.Digest, saltLength int) (pkix.AlgorithmIdentifier, error) {
+	oid, ok := digestOIDs[digest]
+	if !ok {
+		return pkix.AlgorithmIdentifier{}, fmt.Errorf("no X.509 signature algorithm for %s with %s", domain.SchemeRSAPSS, digest)
+	}
+	hash, err := HashForDigest(digest)
+	if err != nil {
+		return pkix.AlgorithmIdentifier{}, err
+	}
+	if saltLength == 0 {
+		saltLength = hash.Size()
+	}
+
+	hashAlgorithm := pkix.AlgorithmIdentifier{Algorithm: oid, Parameters: asn1.NullRawValue}
+	mgfParameters, err := asn1.Marshal(hashAlgorithm)
+	if err != nil {
+		return pkix.AlgorithmIdentifier{}, err
+	}
+	parameters, err := asn1.Marshal(pssParameters{
+		Hash:         hashAlgorithm,
+		MGF:          pkix.AlgorithmIdentifier{Algorithm: oidMGF1, Parameters: asn1.RawValue{FullBytes: mgfParameters}},
+		SaltLength:   saltLength,
+		TrailerField: 1,
+	})
+	if err != nil {
+		return pkix.AlgorithmIdentifier{}, err
+	}
+	return pkix.AlgorithmIdentifier{Algorithm: oidSignatureRSAPSS, Parameters: asn1.RawValue{FullBytes: parameters}}, nil
+}
+
+// extensionRequest builds the PKCS#9 extensionRequest attribute carrying a
+// single URI subject alternative name.
+func extensionRequest(uri string) (asn1.RawValue, error) {
+	names, err := asn1.Marshal([]asn1.RawValue{{Class: asn1.ClassContextSpecific, Tag: 6, Bytes: []byte(uri)}})
+	if err != nil {
+		return asn1.RawValue{}, fmt.Errorf("marshal subject alternative name: %w", err)
+	}
+	extensions, err := asn1.Marshal([]pkix.Extension{{Id: oidExtensionSubjectAlt, Value: names}})
+	if err != nil {
+		return asn1.RawValue{}, fmt.Errorf("marshal extensions: %w", err)
+	}
+	attribute, err := asn1.Marshal(requestAttribute{
+		Type:   oidExtensionRequest,
+		Values: []asn1.RawValue{{FullBytes: extensions}},
+	})
+	if err != nil {
+		return asn1.RawValue{}, fmt.Errorf("marshal extension request: %w", err)
+	}
+	return asn1.RawValue{FullBytes: attribute}, nil
+}
@@ -0,0 +1,161 @@
+package crypto
+
+import (
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/fiskaly/coding-challenges/signing-service-challenge/domain"
+	"github.com/google/uuid"
+)
+
+func TestCertificateRequesterCreateRequest(t *testing.T) {
+	cases := []struct {
+		name   string
+		device domain.Device
+		// checked reports whether crypto/x509 can verify the signature algorithm.
+		checked bool
+	}{
+		{"rsa-pkcs1", domain.Device{Algorithm: domain.AlgorithmRSA, Scheme: domain.SchemeRSAPKCS1v15, Digest: domain.DigestSHA256}, true},
+		{"rsa-pss", domain.Device{Algorithm: domain.AlgorithmRSA, Scheme: domain.SchemeRSAPSS, Digest: domain.DigestSHA384}, true},
+		{"rsa-pss-salt", domain.Device{Algorithm: domain.AlgorithmRSA, Scheme: domain.SchemeRSAPSS, Digest: domain.DigestSHA256, SaltLength: 20}, false},
+		{"rsa-sha3", domain.Device{Algorithm: domain.AlgorithmRSA, Scheme: domain.SchemeRSAPKCS1v15, Digest: domain.DigestSHA3_256}, false},
+		{"ecdsa", domain.Device{Algorithm: domain.AlgorithmECDSA, Scheme: domain.SchemeECDSA, Digest: domain.DigestSHA384, Encoding: domain.EncodingDER}, true},
+		{"ecdsa-rfc6979", domain.Device{Algorithm: domain.AlgorithmECDSA, Scheme: domain.SchemeECDSADeterministic, Digest: domain.DigestSHA256}, true},
+		{"ed25519", domain.Device{Algorithm: domain.AlgorithmEd25519, Scheme: domain.SchemeEd25519}, true},
+		{"legacy", domain.Device{Algorithm: domain.AlgorithmECDSA}, true},
+	}
+
+	generator := NewDefaultKeyGenerator()
+	factory := NewSignerFactory()
+	requester := NewCertificateRequester(DefaultRegistry())
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			device := tc.device
+			device.ID = uuid.New()
+			material, err := generator.Generate(device.Algorithm, "")
+			if err != nil {
+				t.Fatalf("generate: %v", err)
+			}
+			signer, err := factory.SignerFor(device, material)
+			if err != nil {
+				t.Fatalf("signer: %v", err)
+			}
+
+			der, err := requester.CreateRequest(device, material.Public, signer)
+			if err != nil {
+				t.Fatalf("create request: %v", err)
+			}
+			request, err := x509.ParseCertificateRequest(der)
+			if err != nil {
+				t.Fatalf("parse request: %v", err)
+			}
+			if tc.checked {
+				if err := request.CheckSignature(); err != nil {
+					t.Fatalf("check signature: %v", err)
+				}
+			}
+			verifier, err := factory.VerifierFor(device, material)
+			if err != nil {
+				t.Fatalf("verifier: %v", err)
+			}
+			if ok, err := verifier.Verify(request.RawTBSCertificateRequest, request.Signature); err != nil || !ok {
+				t.Fatalf("request signature does not verify with the device key: %v", err)
+			}
+
+			if request.Subject.SerialNumber != device.ID.String() || request.Subject.CommonName != device.ID.String() {
+				t.Fatalf("unexpected subject %s", request.Subject)
+			}
+			if len(request.URIs) != 1 || request.URIs[0].String() != "urn:uuid:"+device.ID.String() {
+				t.Fatalf("unexpected URIs %v", request.URIs)
+			}
+		})
+	}
+}
+
+func TestCertificateRequesterCheckChain(t *testing.T) {
+	ca, err := NewCertificateAuthority(CertificateAuthorityConfig{})
+	if err != nil {
+		t.Fatalf("new CA: %v", err)
+	}
+	foreign, err := NewCertificateAuthority(CertificateAuthorityConfig{})
+	if err != nil {
+		t.Fatalf("new CA: %v", err)
+	}
+	generator := NewDefaultKeyGenerator()
+	material, err := generator.Generate(domain.AlgorithmEd25519, "")
+	if err != nil {
+		t.Fatalf("generate: %v", err)
+	}
+	other, err := generator.Generate(domain.AlgorithmEd25519, "")
+	if err != nil {
+		t.Fatalf("generate: %v", err)
+	}
+	device := domain.Device{ID: uuid.New(), Algorithm: domain.AlgorithmEd25519}
+	leaf, err := ca.Issue(device, material.Public, time.Now())
+	if err != nil {
+		t.Fatalf("issue: %v", err)
+	}
+	encode := func(certificates ...[]byte) []byte {
+		var out []byte
+		for _, certificate := range certificates {
+			out = append(out, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: certificate})...)
+		}
+		return out
+	}
+
+	requester := NewCertificateRequester(DefaultRegistry())
+	chain, err := requester.CheckChain(material.Public, encode(leaf, ca.Root()))
+	if err != nil {
+		t.Fatalf("check chain: %v", err)
+	}
+	if len(chain) != 2 || string(chain[0]) != string(leaf) || string(chain[1]) != string(ca.Root()) {
+		t.Fatal("expected the DER chain leaf first")
+	}
+
+	for name, input := range map[string]struct {
+		public []byte
+		chain  []byte
+	}{
+		"empty":         {material.Public, nil},
+		"other key":     {other.Public, encode(leaf)},
+		"wrong issuer":  {material.Public, encode(leaf, foreign.Root())},
+		"private block": {material.Public, append(encode(leaf), material.Private...)},
+		"trailing data": {material.Public, append(encode(leaf), "garbage"...)},
+		"not a cert":    {material.Public, encode([]byte("garbage"))},
+	} {
+		_, err := requester.CheckChain(input.public, input.chain)
+		var validation domain.ValidationError
+		if !errors.As(err, &validation) || validation.Field != "certificate" {
+			t.Fatalf("%s: expected certificate validation error, got %v", name, err)
+		}
+	}
+}
+
+// pssByDefault is a catalog whose RSA descriptor defaults to RSA-PSS.
+type pssByDefault struct {
+	*Registry
+}
+
+func (c pssByDefault) DescribeAlgorithm(algorithm domain.Algorithm) (domain.AlgorithmDescriptor, bool) {
+	descriptor, ok := c.Registry.DescribeAlgorithm(algorithm)
+	if ok && algorithm == domain.AlgorithmRSA {
+		descriptor.DefaultScheme = domain.SchemeRSAPSS
+	}
+	return descriptor, ok
+}
+
+func TestSignatureAlgorithmUsesInjectedCatalog(t *testing.T) {
+	device := domain.Device{ID: uuid.New(), Algorithm: domain.AlgorithmRSA}
+
+	pkcs1, err := signatureAlgorithm(DefaultRegistry(), device)
+	if err != nil || !pkcs1.Algorithm.Equal(rsaPKCS1v15OIDs[domain.DigestSHA256]) {
+		t.Fatalf("expected PKCS#1 v1.5 from the default registry, got %v (%v)", pkcs1.Algorithm, err)
+	}
+	pss, err := signatureAlgorithm(pssByDefault{DefaultRegistry()}, device)
+	if err != nil || !pss.Algorithm.Equal(oidSignatureRSAPSS) {
+		t.Fatalf("expected RSA-PSS from the injected catalog, got %v (%v)", pss.Algorithm, err)
+	}
+}
@@ -0,0 +1,46 @@
+package crypto
+
+import (
+	stdlibcrypto "crypto"
+	_ "crypto/sha256" // registers SHA-256
+	_ "crypto/sha3"   // registers SHA3-256
+	_ "crypto/sha512" // registers SHA-384 and SHA-512
+	"fmt"
+
+	"github.com/fiskaly/coding-challenges/signing-service-challenge/domain"
+)
+
+// builtinDigests lists the digests offered by the RSA and ECDSA providers, weakest first.
+var builtinDigests = []domain.Digest{
+	domain.DigestSHA256,
+	domain.DigestSHA384,
+	domain.DigestSHA512,
+	domain.DigestSHA3_256,
+}
+
+var digestHashes = map[domain.Digest]stdlibcrypto.Hash{
+	domain.DigestSHA256:   stdlibcrypto.SHA256,
+	domain.DigestSHA384:   stdlibcrypto.SHA384,
+	domain.DigestSHA512:   stdlibcrypto.SHA512,
+	domain.DigestSHA3_256: stdlibcrypto.SHA3_256,
+}
+
+// HashForDigest maps a device digest onto the standard library hash. Devices
+// created before digests were configurable carry no digest and use SHA-256.
+func HashForDigest(digest domain.Digest) (stdlibcrypto.Hash, error) {
+	if digest == "" {
+		return stdlibcrypto.SHA256, nil
+	}
+	hash, ok := digestHashes[digest]
+	if !ok {
+		return 0, fmt.Errorf("unsupported digest %s", digest)
+	}
+	return hash, nil
+}
+
+// hashData returns the digest of data using hash.
+func hashData(hash stdlibcrypto.Hash, data []byte) []byte {
+	h := hash.New()
+	h.Write(data)
+	return h.Sum(nil)
+}
@@ -1,9 +1,13 @@
 package crypto
 
 import (
+	stdlibcrypto "crypto"
 	"crypto/ecdsa"
-	"crypto/x509"
-	"encoding/pem"
+	"crypto/elliptic"
+	"errors"
+	"fmt"
+
+	"github.com/fiskaly/coding-challenges/signing-service-challenge/domain"
 )
 
 // ECCKeyPair is a DTO that holds ECC private and public keys.
@@ -20,42 +24,101 @@ func NewECCMarshaler() ECCMarshaler {
 	return ECCMarshaler{}
 }
 
-// Encode takes an ECCKeyPair and encodes it to be written on disk.
+// Encode encodes an ECCKeyPair with PEMCodec (SPKI public key, PKCS#8 private key).
 // It returns the public and the private key as a byte slice.
 func (m ECCMarshaler) Encode(keyPair ECCKeyPair) ([]byte, []byte, error) {
-	privateKeyBytes, err := x509.MarshalECPrivateKey(keyPair.Private)
-	if err != nil {
-		return nil, nil, err
+	if keyPair.Private == nil {
+		return nil, nil, errors.New("ecdsa private key is required")
 	}
-
-	publicKeyBytes, err := x509.MarshalPKIXPublicKey(keyPair.Public)
-	if err != nil {
-		return nil, nil, err
-	}
-
-	encodedPrivate := pem.EncodeToMemory(&pem.Block{
-		Type:  "PRIVATE_KEY",
-		Bytes: privateKeyBytes,
-	})
-
-	encodedPublic := pem.EncodeToMemory(&pem.Block{
-		Type:  "PUBLIC_KEY",
-		Bytes: publicKeyBytes,
-	})
-
-	return encodedPublic, encodedPrivate, nil
+	return NewPEMCodec().Encode(keyPair.Private)
 }
 
-// Decode assembles an ECCKeyPair from an encoded private key.
+// Decode assembles an ECCKeyPair from a private key in any form PEMCodec reads,
+// including the legacy SEC1 "PRIVATE_KEY" blocks.
 func (m ECCMarshaler) Decode(privateKeyBytes []byte) (*ECCKeyPair, error) {
-	block, _ := pem.Decode(privateKeyBytes)
-	privateKey, err := x509.ParseECPrivateKey(block.Bytes)
+	key, err := NewPEMCodec().DecodePrivate(privateKeyBytes)
 	if err != nil {
 		return nil, err
 	}
+	privateKey, ok := key.(*ecdsa.PrivateKey)
+	if !ok {
+		return nil, unexpectedKeyType(key)
+	}
 
 	return &ECCKeyPair{
 		Private: privateKey,
 		Public:  &privateKey.PublicKey,
 	}, nil
 }
+
+var ecdsaKeySpecCurves = map[domain.KeySpec]elliptic.Curve{
+	domain.KeySpecP256: elliptic.P256(),
+	domain.KeySpecP384: elliptic.P384(),
+	domain.KeySpecP521: elliptic.P521(),
+}
+
+func ecdsaProvider() Provider {
+	return Provider{
+		Algorithm: domain.AlgorithmECDSA,
+		KeySpecs: []domain.KeySpecDescriptor{
+			{Spec: domain.KeySpecP256, Strength: 128},
+			{Spec: domain.KeySpecP384, Strength: 192},
+			{Spec: domain.KeySpecP521, Strength: 256},
+		},
+		DefaultKeySpec: domain.KeySpecP384,
+		Schemes:        []domain.SignatureScheme{domain.SchemeECDSA, domain.SchemeECDSADeterministic},
+		DefaultScheme:  domain.SchemeECDSA,
+		Digests:        builtinDigests,
+		Encodings:      []domain.SignatureEncoding{domain.EncodingDER, domain.EncodingP1363},
+		Generate: func(spec domain.KeySpec) (stdlibcrypto.PrivateKey, error) {
+			curve, ok := ecdsaKeySpecCurves[spec]
+			if !ok {
+				return nil, domain.ErrInvalidKeySpec
+			}
+			generator := ECCGenerator{Curve: curve}
+			pair, err := generator.Generate()
+			if err != nil {
+				return nil, err
+			}
+			return pair.Private, nil
+		},
+		Codec: NewPEMCodec(),
+		KeySpecOf: func(private stdlibcrypto.PrivateKey) (domain.KeySpec, error) {
+			key, ok := private.(*ecdsa.PrivateKey)
+			if !ok {
+				return "", importedKeyMismatch(domain.AlgorithmECDSA, private)
+			}
+			for spec, curve := range ecdsaKeySpecCurves {
+				if curve == key.Curve {
+					return spec, nil
+				}
+			}
+			return "", domain.ValidationError{Field: "private_key", Message: fmt.Sprintf("curve %s is not supported", key.Curve.Params().Name)}
+		},
+		NewSigner: func(private stdlibcrypto.PrivateKey, device domain.Device) (Signer, error) {
+			key, ok := private.(*ecdsa.PrivateKey)
+			if !ok {
+				return nil, unexpectedKeyType(private)
+			}
+			hash, err := HashForDigest(device.Digest)
+			if err != nil {
+				return nil, err
+			}
+			if device.Scheme == domain.SchemeECDSADeterministic {
+				return NewDeterministicECDSASigner(key, hash).WithEncoding(device.Encoding), nil
+			}
+			return NewECDSASigner(key, hash).WithEncoding(device.Encoding), nil
+		},
+		NewVerifier: func(public stdlibcrypto.PublicKey, device domain.Device) (Verifier, error) {
+			key, ok := public.(*ecdsa.PublicKey)
+			if !ok {
+				return nil, unexpectedKeyType(public)
+			}
+			hash, err := HashForDigest(device.Digest)
+			if err != nil {
+				return nil, err
+			}
+			return NewECDSAVerifier(key, hash).WithEncoding(device.Encoding), nil
+		},
+	}
+}
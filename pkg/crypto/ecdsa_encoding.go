@@ -0,0 +1,53 @@
+package crypto
+
+import (
+	"crypto/elliptic"
+	"encoding/asn1"
+	"errors"
+	"fmt"
+	"math/big"
+)
+
+// ecdsaSignature mirrors the ASN.1 Ecdsa-Sig-Value structure of RFC 3279.
+type ecdsaSignature struct {
+	R, S *big.Int
+}
+
+// ECDSAToP1363 converts an ASN.1 DER ECDSA signature into the fixed-width
+// r||s layout of IEEE P1363, each half padded to the curve order size.
+func ECDSAToP1363(curve elliptic.Curve, der []byte) ([]byte, error) {
+	var signature ecdsaSignature
+	rest, err := asn1.Unmarshal(der, &signature)
+	if err != nil {
+		return nil, fmt.Errorf("parse ecdsa signature: %w", err)
+	}
+	if len(rest) > 0 {
+		return nil, errors.New("parse ecdsa signature: trailing data")
+	}
+
+	size := ecdsaScalarSize(curve)
+	if signature.R.Sign() <= 0 || signature.S.Sign() <= 0 || signature.R.BitLen() > size*8 || signature.S.BitLen() > size*8 {
+		return nil, errors.New("ecdsa signature values out of range")
+	}
+	raw := make([]byte, 2*size)
+	signature.R.FillBytes(raw[:size])
+	signature.S.FillBytes(raw[size:])
+	return raw, nil
+}
+
+// ECDSAFromP1363 converts a fixed-width r||s signature back into ASN.1 DER.
+func ECDSAFromP1363(curve elliptic.Curve, raw []byte) ([]byte, error) {
+	size := ecdsaScalarSize(curve)
+	if len(raw) != 2*size {
+		return nil, fmt.Errorf("p1363 signature must be %d bytes, got %d", 2*size, len(raw))
+	}
+	return asn1.Marshal(ecdsaSignature{
+		R: new(big.Int).SetBytes(raw[:size]),
+		S: new(big.Int).SetBytes(raw[size:]),
+	})
+}
+
+// ecdsaScalarSize is the byte length of one P1363 signature half for the curve.
+func ecdsaScalarSize(curve elliptic.Curve) int {
+	return (curve.Params().N.BitLen() + 7) / 8
+}
@@ -0,0 +1,67 @@
+package crypto
+
+import (
+	"bytes"
+	stdlibcrypto "crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"testing"
+
+	"github.com/fiskaly/coding-challenges/signing-service-challenge/domain"
+)
+
+func TestECDSAP1363RoundTrip(t *testing.T) {
+	for _, curve := range []elliptic.Curve{elliptic.P256(), elliptic.P384(), elliptic.P521()} {
+		t.Run(curve.Params().Name, func(t *testing.T) {
+			key, err := ecdsa.GenerateKey(curve, rand.Reader)
+			if err != nil {
+				t.Fatalf("generate: %v", err)
+			}
+			digest := sha256.Sum256([]byte("payload"))
+			der, err := ecdsa.SignASN1(rand.Reader, key, digest[:])
+			if err != nil {
+				t.Fatalf("sign: %v", err)
+			}
+
+			raw, err := ECDSAToP1363(curve, der)
+			if err != nil {
+				t.Fatalf("to p1363: %v", err)
+			}
+			if len(raw) != 2*ecdsaScalarSize(curve) {
+				t.Fatalf("expected %d bytes, got %d", 2*ecdsaScalarSize(curve), len(raw))
+			}
+			back, err := ECDSAFromP1363(curve, raw)
+			if err != nil {
+				t.Fatalf("from p1363: %v", err)
+			}
+			if !bytes.Equal(back, der) {
+				t.Fatal("DER signature did not survive the round trip")
+			}
+		})
+	}
+}
+
+func TestECDSAVerifierRejectsMismatchedEncoding(t *testing.T) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("generate: %v", err)
+	}
+	signer := NewECDSASigner(key, stdlibcrypto.SHA256).WithEncoding(domain.EncodingP1363)
+	signature, err := signer.Sign([]byte("payload"))
+	if err != nil {
+		t.Fatalf("sign: %v", err)
+	}
+
+	if ok, err := NewECDSAVerifier(&key.PublicKey, stdlibcrypto.SHA256).Verify([]byte("payload"), signature); err != nil || ok {
+		t.Fatalf("expected DER verifier to reject a P1363 signature, got %v (%v)", ok, err)
+	}
+	p1363 := NewECDSAVerifier(&key.PublicKey, stdlibcrypto.SHA256).WithEncoding(domain.EncodingP1363)
+	if ok, err := p1363.Verify([]byte("payload"), signature); err != nil || !ok {
+		t.Fatalf("expected P1363 signature to verify, got %v (%v)", ok, err)
+	}
+	if ok, err := p1363.Verify([]byte("payload"), signature[1:]); err != nil || ok {
+		t.Fatalf("expected truncated signature to be invalid, got %v (%v)", ok, err)
+	}
+}
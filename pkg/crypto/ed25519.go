@@ -0,0 +1,90 @@
+package crypto
+
+import (
+	stdlibcrypto "crypto"
+	"crypto/ed25519"
+	"errors"
+
+	"github.com/fiskaly/coding-challenges/signing-service-challenge/domain"
+)
+
+// Ed25519KeyPair is a DTO that holds Ed25519 private and public keys.
+type Ed25519KeyPair struct {
+	Public  ed25519.PublicKey
+	Private ed25519.PrivateKey
+}
+
+// Ed25519Marshaler can encode and decode an Ed25519 key pair.
+type Ed25519Marshaler struct{}
+
+// NewEd25519Marshaler creates a new Ed25519Marshaler.
+func NewEd25519Marshaler() Ed25519Marshaler {
+	return Ed25519Marshaler{}
+}
+
+// Encode encodes an Ed25519KeyPair with PEMCodec (SPKI public key, PKCS#8 private key).
+// It returns the public and the private key as a byte slice.
+func (m Ed25519Marshaler) Encode(keyPair Ed25519KeyPair) ([]byte, []byte, error) {
+	if len(keyPair.Private) != ed25519.PrivateKeySize {
+		return nil, nil, errors.New("ed25519 private key is required")
+	}
+	return NewPEMCodec().Encode(keyPair.Private)
+}
+
+// Decode assembles an Ed25519KeyPair from a PKCS#8 encoded private key.
+func (m Ed25519Marshaler) Decode(privateKeyBytes []byte) (*Ed25519KeyPair, error) {
+	key, err := NewPEMCodec().DecodePrivate(privateKeyBytes)
+	if err != nil {
+		return nil, err
+	}
+	privateKey, ok := key.(ed25519.PrivateKey)
+	if !ok {
+		return nil, unexpectedKeyType(key)
+	}
+
+	return &Ed25519KeyPair{
+		Private: privateKey,
+		Public:  privateKey.Public().(ed25519.PublicKey),
+	}, nil
+}
+
+func ed25519Provider() Provider {
+	return Provider{
+		Algorithm: domain.AlgorithmEd25519,
+		KeySpecs: []domain.KeySpecDescriptor{
+			{Spec: domain.KeySpecEd25519, Strength: 128},
+		},
+		DefaultKeySpec: domain.KeySpecEd25519,
+		Schemes:        []domain.SignatureScheme{domain.SchemeEd25519},
+		DefaultScheme:  domain.SchemeEd25519,
+		Generate: func(domain.KeySpec) (stdlibcrypto.PrivateKey, error) {
+			generator := Ed25519Generator{}
+			pair, err := generator.Generate()
+			if err != nil {
+				return nil, err
+			}
+			return pair.Private, nil
+		},
+		Codec: NewPEMCodec(),
+		KeySpecOf: func(private stdlibcrypto.PrivateKey) (domain.KeySpec, error) {
+			if _, ok := private.(ed25519.PrivateKey); !ok {
+				return "", importedKeyMismatch(domain.AlgorithmEd25519, private)
+			}
+			return domain.KeySpecEd25519, nil
+		},
+		NewSigner: func(private stdlibcrypto.PrivateKey, _ domain.Device) (Signer, error) {
+			key, ok := private.(ed25519.PrivateKey)
+			if !ok {
+				return nil, unexpectedKeyType(private)
+			}
+			return NewEd25519Signer(key), nil
+		},
+		NewVerifier: func(public stdlibcrypto.PublicKey, _ domain.Device) (Verifier, error) {
+			key, ok := public.(ed25519.PublicKey)
+			if !ok {
+				return nil, unexpectedKeyType(public)
+			}
+			return NewEd25519Verifier(key), nil
+		},
+	}
+}
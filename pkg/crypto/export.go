@@ -0,0 +1,65 @@
+package crypto
+
+import (
+	"crypto/x509"
+	"fmt"
+	"strings"
+
+	"github.com/fiskaly/coding-challenges/signing-service-challenge/domain"
+	"github.com/fiskaly/coding-challenges/signing-service-challenge/internal/devices"
+)
+
+// KeyExporter converts stored public keys into SPKI and JWK representations.
+type KeyExporter struct {
+	registry *Registry
+}
+
+var _ devices.PublicKeyExporter = (*KeyExporter)(nil)
+
+// NewKeyExporter instantiates an exporter backed by the default registry.
+func NewKeyExporter() *KeyExporter {
+	return NewRegistryKeyExporter(DefaultRegistry())
+}
+
+// NewRegistryKeyExporter instantiates an exporter backed by the given registry.
+func NewRegistryKeyExporter(registry *Registry) *KeyExporter {
+	return &KeyExporter{registry: registry}
+}
+
+// ExportPublicKey decodes the device public key and re-encodes it as DER SPKI and JWK.
+func (e *KeyExporter) ExportPublicKey(device domain.Device, material domain.KeyMaterial) (devices.PublicKeyExport, error) {
+	provider, ok := e.registry.Lookup(device.Algorithm)
+	if !ok {
+		return devices.PublicKeyExport{}, domain.ErrInvalidAlgorithm
+	}
+	if provider.Symmetric {
+		return devices.PublicKeyExport{}, domain.NoPublicKeyError(device.Algorithm)
+	}
+
+	name := strings.ToLower(string(device.Algorithm))
+	publicKey, err := provider.Codec.DecodePublic(material.Public)
+	if err != nil {
+		return devices.PublicKeyExport{}, fmt.Errorf("decode %s public key: %w", name, err)
+	}
+
+	spki, err := x509.MarshalPKIXPublicKey(publicKey)
+	if err != nil {
+		return devices.PublicKeyExport{}, fmt.Errorf("marshal %s public key: %w", name, err)
+	}
+
+	jwk, err := PublicJWK(publicKey)
+	if err != nil {
+		return devices.PublicKeyExport{}, fmt.Errorf("convert %s public key to jwk: %w", name, err)
+	}
+	keyID, err := JWKThumbprint(jwk)
+	if err != nil {
+		return devices.PublicKeyExport{}, fmt.Errorf("compute jwk thumbprint: %w", err)
+	}
+	jwk["kid"] = keyID
+
+	return devices.PublicKeyExport{
+		SPKI:  spki,
+		JWK:   jwk,
+		KeyID: keyID,
+	}, nil
+}
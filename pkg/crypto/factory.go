@@ -1,72 +1,80 @@
 package crypto
 
 import (
-	"crypto/ecdsa"
-	"crypto/rsa"
-	"crypto/x509"
-	"encoding/pem"
+	stdlibcrypto "crypto"
 	"fmt"
+	"strings"
 
 	"github.com/fiskaly/coding-challenges/signing-service-challenge/domain"
 	"github.com/fiskaly/coding-challenges/signing-service-challenge/internal/devices"
 )
 
-// SignerFactory resolves crypto signers based on the device algorithm.
-type SignerFactory struct{}
+// SignerFactory resolves crypto signers and verifiers based on the device algorithm.
+type SignerFactory struct {
+	registry *Registry
+}
 
 var _ devices.SignerFactory = (*SignerFactory)(nil)
 
-// NewSignerFactory instantiates a default factory.
+// NewSignerFactory instantiates a factory backed by the default registry.
 func NewSignerFactory() *SignerFactory {
-	return &SignerFactory{}
+	return NewRegistrySignerFactory(DefaultRegistry())
+}
+
+// NewRegistrySignerFactory instantiates a factory backed by the given registry.
+func NewRegistrySignerFactory(registry *Registry) *SignerFactory {
+	return &SignerFactory{registry: registry}
 }
 
 // SignerFor decodes key material and returns the matching signer.
 func (f *SignerFactory) SignerFor(device domain.Device, material domain.KeyMaterial) (devices.Signer, error) {
-	switch device.Algorithm {
-	case domain.AlgorithmRSA:
-		privateKey, err := parseRSAPrivateKey(material.Private)
-		if err != nil {
-			return nil, fmt.Errorf("decode rsa private key: %w", err)
-		}
-		return NewRSASigner(privateKey), nil
-	case domain.AlgorithmECDSA:
-		privateKey, err := parseECDSAPrivateKey(material.Private)
-		if err != nil {
-			return nil, fmt.Errorf("decode ecdsa private key: %w", err)
-		}
-		return NewECDSASigner(privateKey), nil
-	default:
+	provider, ok := f.registry.Lookup(device.Algorithm)
+	if !ok {
 		return nil, domain.ErrInvalidAlgorithm
 	}
-}
 
-// parseRSAPrivateKey extracts a PKCS#1 private key from a PEM encoded block.
-func parseRSAPrivateKey(pemBytes []byte) (*rsa.PrivateKey, error) {
-	block, _ := pem.Decode(pemBytes)
-	if block == nil {
-		return nil, fmt.Errorf("invalid PEM block")
+	name := strings.ToLower(string(device.Algorithm))
+	privateKey, err := provider.Codec.DecodePrivate(material.Private)
+	if err != nil {
+		return nil, fmt.Errorf("decode %s private key: %w", name, err)
 	}
 
-	key, err := x509.ParsePKCS1PrivateKey(block.Bytes)
+	signer, err := provider.NewSigner(privateKey, device)
 	if err != nil {
-		return nil, err
+		return nil, fmt.Errorf("build %s signer: %w", name, err)
 	}
 
-	return key, nil
+	return signer, nil
 }
 
-// parseECDSAPrivateKey extracts an EC private key from a PEM encoded block.
-func parseECDSAPrivateKey(pemBytes []byte) (*ecdsa.PrivateKey, error) {
-	block, _ := pem.Decode(pemBytes)
-	if block == nil {
-		return nil, fmt.Errorf("invalid PEM block")
+// VerifierFor decodes the public key, or the secret of symmetric algorithms,
+// and returns the matching verifier.
+func (f *SignerFactory) VerifierFor(device domain.Device, material domain.KeyMaterial) (devices.Verifier, error) {
+	provider, ok := f.registry.Lookup(device.Algorithm)
+	if !ok {
+		return nil, domain.ErrInvalidAlgorithm
+	}
+
+	name := strings.ToLower(string(device.Algorithm))
+	var key stdlibcrypto.PublicKey
+	var err error
+	if provider.Symmetric {
+		// MACs are checked by recomputing them with the device secret.
+		key, err = provider.Codec.DecodePrivate(material.Private)
+		if err != nil {
+			return nil, fmt.Errorf("decode %s secret key: %w", name, err)
+		}
+	} else {
+		key, err = provider.Codec.DecodePublic(material.Public)
+		if err != nil {
+			return nil, fmt.Errorf("decode %s public key: %w", name, err)
+		}
 	}
 
-	key, err := x509.ParseECPrivateKey(block.Bytes)
+	verifier, err := provider.NewVerifier(key, device)
 	if err != nil {
-		return nil, err
+		return nil, fmt.Errorf("build %s verifier: %w", name, err)
 	}
 
-	return key, nil
+	return verifier, nil
 }
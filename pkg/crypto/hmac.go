@@ -0,0 +1,95 @@
+package crypto
+
+import (
+	stdlibcrypto "crypto"
+	"crypto/rand"
+	"encoding/pem"
+	"fmt"
+
+	"github.com/fiskaly/coding-challenges/signing-service-challenge/domain"
+)
+
+// PEMTypeHMACKey is the PEM block type HMACCodec stores device secrets under.
+const PEMTypeHMACKey = "HMAC SECRET KEY"
+
+// HMACKeySize is the length of generated HMAC-SHA256 secrets in bytes,
+// matching the hash output as RFC 2104 section 3 recommends.
+const HMACKeySize = 32
+
+// HMACKey is the secret of an HMAC-SHA256 device.
+type HMACKey []byte
+
+// HMACCodec stores HMAC secrets as raw bytes in a PEM block. Secrets have no
+// public half: Encode returns no public key and DecodePublic always fails.
+type HMACCodec struct{}
+
+var _ KeyCodec = HMACCodec{}
+
+// NewHMACCodec creates a new HMACCodec.
+func NewHMACCodec() HMACCodec {
+	return HMACCodec{}
+}
+
+// Encode returns a nil public key and the PEM encoded secret.
+func (HMACCodec) Encode(private stdlibcrypto.PrivateKey) ([]byte, []byte, error) {
+	key, ok := private.(HMACKey)
+	if !ok || len(key) == 0 {
+		return nil, nil, unexpectedKeyType(private)
+	}
+	return nil, pem.EncodeToMemory(&pem.Block{Type: PEMTypeHMACKey, Bytes: key}), nil
+}
+
+// DecodePrivate reads a secret written by Encode.
+func (HMACCodec) DecodePrivate(pemBytes []byte) (stdlibcrypto.PrivateKey, error) {
+	block, _ := pem.Decode(pemBytes)
+	switch {
+	case block == nil:
+		return nil, MalformedKeyError{Kind: "private", Err: ErrNoPEMBlock}
+	case block.Type != PEMTypeHMACKey:
+		return nil, MalformedKeyError{Kind: "private", BlockType: block.Type, Err: ErrUnsupportedBlock}
+	case len(block.Bytes) < HMACKeySize:
+		return nil, MalformedKeyError{Kind: "private", BlockType: block.Type, Err: fmt.Errorf("secret shorter than %d bytes", HMACKeySize)}
+	}
+	return HMACKey(block.Bytes), nil
+}
+
+// DecodePublic fails: HMAC devices have no public key.
+func (HMACCodec) DecodePublic([]byte) (stdlibcrypto.PublicKey, error) {
+	return nil, domain.NoPublicKeyError(domain.AlgorithmHMAC)
+}
+
+func hmacProvider() Provider {
+	return Provider{
+		Algorithm: domain.AlgorithmHMAC,
+		KeySpecs: []domain.KeySpecDescriptor{
+			{Spec: domain.KeySpecHMAC256, Strength: 256},
+		},
+		DefaultKeySpec: domain.KeySpecHMAC256,
+		Schemes:        []domain.SignatureScheme{domain.SchemeHMACSHA256},
+		DefaultScheme:  domain.SchemeHMACSHA256,
+		Symmetric:      true,
+		Generate: func(domain.KeySpec) (stdlibcrypto.PrivateKey, error) {
+			key := make(HMACKey, HMACKeySize)
+			if _, err := rand.Read(key); err != nil {
+				return nil, err
+			}
+			return key, nil
+		},
+		Codec: NewHMACCodec(),
+		NewSigner: func(private stdlibcrypto.PrivateKey, _ domain.Device) (Signer, error) {
+			key, ok := private.(HMACKey)
+			if !ok {
+				return nil, unexpectedKeyType(private)
+			}
+			return NewHMACSigner(key), nil
+		},
+		// The factory hands symmetric verifiers the secret instead of a public key.
+		NewVerifier: func(secret stdlibcrypto.PublicKey, _ domain.Device) (Verifier, error) {
+			key, ok := secret.(HMACKey)
+			if !ok {
+				return nil, unexpectedKeyType(secret)
+			}
+			return NewHMACVerifier(key), nil
+		},
+	}
+}
@@ -0,0 +1,83 @@
+package crypto
+
+import (
+	stdlibcrypto "crypto"
+	"errors"
+	"fmt"
+
+	"github.com/fiskaly/coding-challenges/signing-service-challenge/domain"
+	"github.com/fiskaly/coding-challenges/signing-service-challenge/internal/devices"
+)
+
+// KeyImporter turns caller-supplied PEM private keys into device key material.
+type KeyImporter struct {
+	registry *Registry
+}
+
+var _ devices.KeyImporter = (*KeyImporter)(nil)
+
+// NewKeyImporter instantiates an importer backed by the default registry.
+func NewKeyImporter() *KeyImporter {
+	return NewRegistryKeyImporter(DefaultRegistry())
+}
+
+// NewRegistryKeyImporter instantiates an importer backed by the given registry.
+func NewRegistryKeyImporter(registry *Registry) *KeyImporter {
+	return &KeyImporter{registry: registry}
+}
+
+// Import parses a PKCS#8, PKCS#1 or SEC1 PEM private key, checks it belongs to
+// the algorithm and re-encodes it with the provider codec.
+func (i *KeyImporter) Import(algorithm domain.Algorithm, privateKeyPEM []byte) (devices.ImportedKey, error) {
+	provider, ok := i.registry.Lookup(algorithm)
+	if !ok {
+		return devices.ImportedKey{}, domain.ErrInvalidAlgorithm
+	}
+	if provider.KeySpecOf == nil {
+		return devices.ImportedKey{}, domain.ValidationError{Field: "algorithm", Message: fmt.Sprintf("%s does not support key import", algorithm)}
+	}
+
+	private, err := parsePrivateKeyPEM(privateKeyPEM)
+	if err != nil {
+		return devices.ImportedKey{}, err
+	}
+
+	spec, err := provider.KeySpecOf(private)
+	if err != nil {
+		return devices.ImportedKey{}, err
+	}
+
+	public, encodedPrivate, err := provider.Codec.Encode(private)
+	if err != nil {
+		return devices.ImportedKey{}, fmt.Errorf("encode imported key: %w", err)
+	}
+
+	return devices.ImportedKey{
+		KeySpec:  spec,
+		Material: domain.KeyMaterial{Public: public, Private: encodedPrivate},
+	}, nil
+}
+
+// parsePrivateKeyPEM accepts every private key form PEMCodec reads, most
+// notably PKCS#8 ("PRIVATE KEY"), PKCS#1 ("RSA PRIVATE KEY") and SEC1 ("EC PRIVATE KEY").
+func parsePrivateKeyPEM(privateKeyPEM []byte) (stdlibcrypto.PrivateKey, error) {
+	private, err := NewPEMCodec().DecodePrivate(privateKeyPEM)
+	if err == nil {
+		return private, nil
+	}
+
+	var malformed MalformedKeyError
+	errors.As(err, &malformed)
+	switch {
+	case errors.Is(err, ErrNoPEMBlock):
+		return nil, domain.ValidationError{Field: "private_key", Message: "private key must be PEM encoded"}
+	case errors.Is(err, ErrUnsupportedBlock):
+		return nil, domain.ValidationError{Field: "private_key", Message: fmt.Sprintf("unsupported PEM block %q", malformed.BlockType)}
+	default:
+		return nil, domain.ValidationError{Field: "private_key", Message: "private key could not be parsed"}
+	}
+}
+
+func importedKeyMismatch(algorithm domain.Algorithm, key stdlibcrypto.PrivateKey) error {
+	return domain.ValidationError{Field: "private_key", Message: fmt.Sprintf("%T is not a %s key", key, algorithm)}
+}
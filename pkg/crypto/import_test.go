@@ -0,0 +1,116 @@
+package crypto
+
+import (
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"testing"
+
+	"github.com/fiskaly/coding-challenges/signing-service-challenge/domain"
+)
+
+func TestKeyImporter_Formats(t *testing.T) {
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("generate rsa: %v", err)
+	}
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("generate ecdsa: %v", err)
+	}
+	_, edKey, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatalf("generate ed25519: %v", err)
+	}
+	sec1, err := x509.MarshalECPrivateKey(ecKey)
+	if err != nil {
+		t.Fatalf("marshal sec1: %v", err)
+	}
+
+	cases := []struct {
+		name      string
+		algorithm domain.Algorithm
+		block     *pem.Block
+		spec      domain.KeySpec
+	}{
+		{"pkcs1", domain.AlgorithmRSA, &pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(rsaKey)}, domain.KeySpecRSA2048},
+		{"pkcs8 rsa", domain.AlgorithmRSA, &pem.Block{Type: "PRIVATE KEY", Bytes: mustPKCS8(t, rsaKey)}, domain.KeySpecRSA2048},
+		{"sec1", domain.AlgorithmECDSA, &pem.Block{Type: "EC PRIVATE KEY", Bytes: sec1}, domain.KeySpecP256},
+		{"pkcs8 ecdsa", domain.AlgorithmECDSA, &pem.Block{Type: "PRIVATE KEY", Bytes: mustPKCS8(t, ecKey)}, domain.KeySpecP256},
+		{"pkcs8 ed25519", domain.AlgorithmEd25519, &pem.Block{Type: "PRIVATE KEY", Bytes: mustPKCS8(t, edKey)}, domain.KeySpecEd25519},
+	}
+
+	importer := NewKeyImporter()
+	factory := NewSignerFactory()
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			imported, err := importer.Import(tc.algorithm, pem.EncodeToMemory(tc.block))
+			if err != nil {
+				t.Fatalf("import: %v", err)
+			}
+			if imported.KeySpec != tc.spec {
+				t.Fatalf("expected key spec %s, got %s", tc.spec, imported.KeySpec)
+			}
+
+			device := domain.Device{Algorithm: tc.algorithm, KeySpec: tc.spec}
+			signer, err := factory.SignerFor(device, imported.Material)
+			if err != nil {
+				t.Fatalf("signer: %v", err)
+			}
+			signature, err := signer.Sign([]byte("payload"))
+			if err != nil {
+				t.Fatalf("sign: %v", err)
+			}
+			verifier, err := factory.VerifierFor(device, imported.Material)
+			if err != nil {
+				t.Fatalf("verifier: %v", err)
+			}
+			if ok, err := verifier.Verify([]byte("payload"), signature); err != nil || !ok {
+				t.Fatalf("expected imported key to sign, got %v (%v)", ok, err)
+			}
+		})
+	}
+}
+
+func TestKeyImporter_Rejects(t *testing.T) {
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("generate ecdsa: %v", err)
+	}
+	ecPEM := pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: mustPKCS8(t, ecKey)})
+
+	cases := map[string]struct {
+		algorithm domain.Algorithm
+		input     []byte
+	}{
+		"algorithm mismatch": {domain.AlgorithmRSA, ecPEM},
+		"not pem":            {domain.AlgorithmECDSA, []byte("not a key")},
+		"public key block":   {domain.AlgorithmECDSA, pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: []byte{0x30}})},
+		"garbage der":        {domain.AlgorithmECDSA, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: []byte{0x30}})},
+	}
+
+	importer := NewKeyImporter()
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			_, err := importer.Import(tc.algorithm, tc.input)
+			var vErr domain.ValidationError
+			if !errors.As(err, &vErr) || vErr.Field != "private_key" {
+				t.Fatalf("expected private_key validation error, got %v", err)
+			}
+		})
+	}
+}
+
+func mustPKCS8(t *testing.T, key interface{}) []byte {
+	t.Helper()
+	der, err := x509.MarshalPKCS8PrivateKey(key)
+	if err != nil {
+		t.Fatalf("marshal pkcs8: %v", err)
+	}
+	return der
+}
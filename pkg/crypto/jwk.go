@@ -0,0 +1,80 @@
+package crypto
+
+import (
+	stdlibcrypto "crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"math/big"
+)
+
+// jwkRequiredMembers lists the members that take part in the RFC 7638 thumbprint per key type.
+var jwkRequiredMembers = map[string][]string{
+	"RSA": {"e", "kty", "n"},
+	"EC":  {"crv", "kty", "x", "y"},
+	"OKP": {"crv", "kty", "x"},
+}
+
+// PublicJWK converts a public key into its JSON Web Key (RFC 7517) members.
+func PublicJWK(public stdlibcrypto.PublicKey) (map[string]string, error) {
+	switch key := public.(type) {
+	case *rsa.PublicKey:
+		return map[string]string{
+			"kty": "RSA",
+			"n":   encodeJWKBytes(key.N.Bytes()),
+			"e":   encodeJWKBytes(big.NewInt(int64(key.E)).Bytes()),
+		}, nil
+	case *ecdsa.PublicKey:
+		params := key.Curve.Params()
+		size := (params.BitSize + 7) / 8
+		return map[string]string{
+			"kty": "EC",
+			"crv": params.Name,
+			"x":   encodeJWKBytes(key.X.FillBytes(make([]byte, size))),
+			"y":   encodeJWKBytes(key.Y.FillBytes(make([]byte, size))),
+		}, nil
+	case ed25519.PublicKey:
+		return map[string]string{
+			"kty": "OKP",
+			"crv": "Ed25519",
+			"x":   encodeJWKBytes(key),
+		}, nil
+	default:
+		return nil, unexpectedKeyType(public)
+	}
+}
+
+// JWKThumbprint computes the base64url SHA-256 thumbprint of a JWK as defined by RFC 7638.
+func JWKThumbprint(jwk map[string]string) (string, error) {
+	members, ok := jwkRequiredMembers[jwk["kty"]]
+	if !ok {
+		return "", fmt.Errorf("unsupported jwk key type %q", jwk["kty"])
+	}
+
+	required := make(map[string]string, len(members))
+	for _, member := range members {
+		value, ok := jwk[member]
+		if !ok {
+			return "", fmt.Errorf("jwk member %q missing", member)
+		}
+		required[member] = value
+	}
+
+	// encoding/json sorts map keys and emits no whitespace, which is exactly
+	// the canonical form RFC 7638 asks for.
+	canonical, err := json.Marshal(required)
+	if err != nil {
+		return "", err
+	}
+
+	digest := sha256.Sum256(canonical)
+	return encodeJWKBytes(digest[:]), nil
+}
+
+func encodeJWKBytes(value []byte) string {
+	return base64.RawURLEncoding.EncodeToString(value)
+}
@@ -2,74 +2,80 @@ package crypto
 
 import (
 	"fmt"
+	"strings"
 
 	"github.com/fiskaly/coding-challenges/signing-service-challenge/domain"
 	"github.com/fiskaly/coding-challenges/signing-service-challenge/internal/devices"
 )
 
 // DefaultKeyGenerator marshals generated key pairs into PEM encoded material.
+// With a KeyPool attached it serves pre-generated keys and only generates
+// inline when the pool is empty.
 type DefaultKeyGenerator struct {
-	rsaGenerator RSAGenerator
-	rsaMarshaler RSAMarshaler
-	eccGenerator ECCGenerator
-	eccMarshaler ECCMarshaler
+	registry *Registry
+	pool     *KeyPool
 }
 
 var _ devices.KeyGenerator = (*DefaultKeyGenerator)(nil)
 
-// NewDefaultKeyGenerator instantiates a generator for RSA and ECDSA keys.
+// NewDefaultKeyGenerator instantiates a generator backed by the default registry.
 func NewDefaultKeyGenerator() *DefaultKeyGenerator {
-	return &DefaultKeyGenerator{
-		rsaGenerator: RSAGenerator{},
-		rsaMarshaler: NewRSAMarshaler(),
-		eccGenerator: ECCGenerator{},
-		eccMarshaler: NewECCMarshaler(),
-	}
+	return NewRegistryKeyGenerator(DefaultRegistry())
 }
 
-// Generate produces PEM encoded key material for the requested algorithm.
-func (g *DefaultKeyGenerator) Generate(algorithm domain.Algorithm) (domain.KeyMaterial, error) {
-	switch algorithm {
-	case domain.AlgorithmRSA:
-		return g.generateRSA()
-	case domain.AlgorithmECDSA:
-		return g.generateECDSA()
-	default:
-		return domain.KeyMaterial{}, fmt.Errorf("unsupported algorithm: %s", algorithm)
+// NewRegistryKeyGenerator instantiates a generator backed by the given registry.
+func NewRegistryKeyGenerator(registry *Registry) *DefaultKeyGenerator {
+	return &DefaultKeyGenerator{registry: registry}
+}
+
+// WithKeyPool makes the generator draw keys from pool first.
+func (g *DefaultKeyGenerator) WithKeyPool(pool *KeyPool) {
+	if pool != nil {
+		g.pool = pool
 	}
 }
 
-func (g *DefaultKeyGenerator) generateRSA() (domain.KeyMaterial, error) {
-	pair, err := g.rsaGenerator.Generate()
-	if err != nil {
-		return domain.KeyMaterial{}, fmt.Errorf("generate rsa key pair: %w", err)
+// Generate produces PEM encoded key material for the requested algorithm and key spec.
+// An empty spec selects the provider default.
+func (g *DefaultKeyGenerator) Generate(algorithm domain.Algorithm, spec domain.KeySpec) (domain.KeyMaterial, error) {
+	provider, ok := g.registry.Lookup(algorithm)
+	if !ok {
+		return domain.KeyMaterial{}, fmt.Errorf("unsupported algorithm: %s", algorithm)
 	}
 
-	publicBytes, privateBytes, err := g.rsaMarshaler.Marshal(*pair)
+	spec, err := provider.resolveKeySpec(spec)
 	if err != nil {
-		return domain.KeyMaterial{}, fmt.Errorf("marshal rsa key pair: %w", err)
+		return domain.KeyMaterial{}, err
 	}
 
-	return domain.KeyMaterial{
-		// Copy slices so callers cannot mutate the generator's buffers.
-		Public:  append([]byte(nil), publicBytes...),
-		Private: append([]byte(nil), privateBytes...),
-	}, nil
+	if g.pool != nil {
+		if material, ok := g.pool.Take(algorithm, spec); ok {
+			return material, nil
+		}
+	}
+	return g.generate(algorithm, spec)
 }
 
-func (g *DefaultKeyGenerator) generateECDSA() (domain.KeyMaterial, error) {
-	pair, err := g.eccGenerator.Generate()
+// generate creates a key pair inline for an already resolved key spec.
+func (g *DefaultKeyGenerator) generate(algorithm domain.Algorithm, spec domain.KeySpec) (domain.KeyMaterial, error) {
+	provider, ok := g.registry.Lookup(algorithm)
+	if !ok {
+		return domain.KeyMaterial{}, fmt.Errorf("unsupported algorithm: %s", algorithm)
+	}
+
+	name := strings.ToLower(string(algorithm))
+	privateKey, err := provider.Generate(spec)
 	if err != nil {
-		return domain.KeyMaterial{}, fmt.Errorf("generate ecdsa key pair: %w", err)
+		return domain.KeyMaterial{}, fmt.Errorf("generate %s key pair: %w", name, err)
 	}
 
-	publicBytes, privateBytes, err := g.eccMarshaler.Encode(*pair)
+	publicBytes, privateBytes, err := provider.Codec.Encode(privateKey)
 	if err != nil {
-		return domain.KeyMaterial{}, fmt.Errorf("marshal ecdsa key pair: %w", err)
+		return domain.KeyMaterial{}, fmt.Errorf("marshal %s key pair: %w", name, err)
 	}
 
 	return domain.KeyMaterial{
-		// Copy slices so callers cannot mutate the generator's buffers.
+		// Copy slices so callers cannot mutate the codec's buffers.
 		Public:  append([]byte(nil), publicBytes...),
 		Private: append([]byte(nil), privateBytes...),
 	}, nil
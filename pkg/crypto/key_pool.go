@@ -0,0 +1,206 @@
+package crypto
+
+import (
+	"errors"
+	"sort"
+	"sync"
+	"sync/atomic"
+
+	"github.com/fiskaly/coding-challenges/signing-service-challenge/domain"
+)
+
+// KeyPoolConfig tunes a KeyPool.
+type KeyPoolConfig struct {
+	// High is the number of keys kept ready per algorithm and key spec.
+	High int
+	// Low is the depth at which workers start refilling up to High.
+	Low int
+	// Workers is the number of generator goroutines per pool; defaults to 1.
+	Workers int
+}
+
+// KeyPoolStats reports the state of the pool for one algorithm and key spec.
+type KeyPoolStats struct {
+	Algorithm domain.Algorithm `json:"algorithm"`
+	KeySpec   domain.KeySpec   `json:"key_spec"`
+	Depth     int              `json:"depth"`
+	Low       int              `json:"low"`
+	High      int              `json:"high"`
+	Hits      uint64           `json:"hits"`     // keys served from the pool
+	Misses    uint64           `json:"misses"`   // requests generated inline because the pool was empty
+	Failures  uint64           `json:"failures"` // background generation errors
+}
+
+type poolKey struct {
+	algorithm domain.Algorithm
+	spec      domain.KeySpec
+}
+
+// KeyPool keeps pre-generated key material per algorithm and key spec so slow
+// generators (RSA in particular) run ahead of device creation. Pools are
+// created on first use or by Warm, and worker goroutines refill a pool up to
+// the high watermark whenever it drops below the low one.
+type KeyPool struct {
+	generator *DefaultKeyGenerator
+	config    KeyPoolConfig
+	mu        sync.Mutex
+	pools     map[poolKey]*specPool
+	stop      chan struct{}
+	wg        sync.WaitGroup
+	closeOnce sync.Once
+}
+
+// NewKeyPool creates a pool generating keys through the providers of registry.
+func NewKeyPool(registry *Registry, config KeyPoolConfig) (*KeyPool, error) {
+	if config.High <= 0 {
+		return nil, errors.New("key pool high watermark must be positive")
+	}
+	if config.Low < 0 || config.Low > config.High {
+		return nil, errors.New("key pool low watermark must be between 0 and the high watermark")
+	}
+	if config.Workers <= 0 {
+		config.Workers = 1
+	}
+	return &KeyPool{
+		generator: NewRegistryKeyGenerator(registry),
+		config:    config,
+		pools:     make(map[poolKey]*specPool),
+		stop:      make(chan struct{}),
+	}, nil
+}
+
+// Warm starts filling the pool for an algorithm and key spec ahead of demand.
+// An empty spec selects the provider default.
+func (p *KeyPool) Warm(algorithm domain.Algorithm, spec domain.KeySpec) error {
+	provider, ok := p.generator.registry.Lookup(algorithm)
+	if !ok {
+		return domain.ErrInvalidAlgorithm
+	}
+	spec, err := provider.resolveKeySpec(spec)
+	if err != nil {
+		return err
+	}
+	p.pool(algorithm, spec).signal(p.config.Workers)
+	return nil
+}
+
+// Take returns pooled key material if available. It never blocks: on a miss
+// the caller generates inline while the workers refill.
+func (p *KeyPool) Take(algorithm domain.Algorithm, spec domain.KeySpec) (domain.KeyMaterial, bool) {
+	pool := p.pool(algorithm, spec)
+	select {
+	case material := <-pool.keys:
+		pool.hits.Add(1)
+		if len(pool.keys) < p.config.Low {
+			pool.signal(p.config.Workers)
+		}
+		return material, true
+	default:
+		pool.misses.Add(1)
+		pool.signal(p.config.Workers)
+		return domain.KeyMaterial{}, false
+	}
+}
+
+// Stats reports the depth and counters of every pool, ordered by algorithm and key spec.
+func (p *KeyPool) Stats() []KeyPoolStats {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	stats := make([]KeyPoolStats, 0, len(p.pools))
+	for key, pool := range p.pools {
+		stats = append(stats, KeyPoolStats{
+			Algorithm: key.algorithm,
+			KeySpec:   key.spec,
+			Depth:     len(pool.keys),
+			Low:       p.config.Low,
+			High:      p.config.High,
+			Hits:      pool.hits.Load(),
+			Misses:    pool.misses.Load(),
+			Failures:  pool.failures.Load(),
+		})
+	}
+	sort.Slice(stats, func(i, j int) bool {
+		if stats[i].Algorithm != stats[j].Algorithm {
+			return stats[i].Algorithm < stats[j].Algorithm
+		}
+		return stats[i].KeySpec < stats[j].KeySpec
+	})
+	return stats
+}
+
+// Close stops the workers. Pooled keys are dropped; Take keeps reporting misses.
+func (p *KeyPool) Close() {
+	p.closeOnce.Do(func() {
+		close(p.stop)
+	})
+	p.wg.Wait()
+}
+
+func (p *KeyPool) pool(algorithm domain.Algorithm, spec domain.KeySpec) *specPool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	key := poolKey{algorithm: algorithm, spec: spec}
+	if pool, ok := p.pools[key]; ok {
+		return pool
+	}
+	pool := &specPool{
+		keys:   make(chan domain.KeyMaterial, p.config.High),
+		refill: make(chan struct{}, p.config.Workers),
+	}
+	p.pools[key] = pool
+	select {
+	case <-p.stop:
+		return pool
+	default:
+	}
+	for i := 0; i < p.config.Workers; i++ {
+		p.wg.Add(1)
+		go p.work(key, pool)
+	}
+	return pool
+}
+
+// work tops the pool up to the high watermark each time it is signalled.
+func (p *KeyPool) work(key poolKey, pool *specPool) {
+	defer p.wg.Done()
+	for {
+		select {
+		case <-p.stop:
+			return
+		case <-pool.refill:
+		}
+		for len(pool.keys) < p.config.High {
+			material, err := p.generator.generate(key.algorithm, key.spec)
+			if err != nil {
+				pool.failures.Add(1)
+				break
+			}
+			select {
+			case pool.keys <- material:
+			case <-p.stop:
+				return
+			}
+		}
+	}
+}
+
+type specPool struct {
+	keys     chan domain.KeyMaterial
+	refill   chan struct{}
+	hits     atomic.Uint64
+	misses   atomic.Uint64
+	failures atomic.Uint64
+}
+
+// signal wakes up to n idle workers without blocking.
+func (p *specPool) signal(n int) {
+	for i := 0; i < n; i++ {
+		select {
+		case p.refill <- struct{}{}:
+		default:
+			return
+		}
+	}
+}
@@ -0,0 +1,99 @@
+package crypto
+
+import (
+	"testing"
+	"time"
+
+	"github.com/fiskaly/coding-challenges/signing-service-challenge/domain"
+)
+
+func waitForDepth(t *testing.T, pool *KeyPool, spec domain.KeySpec, depth int) KeyPoolStats {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		for _, stats := range pool.Stats() {
+			if stats.KeySpec == spec && stats.Depth >= depth {
+				return stats
+			}
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("pool for %s did not reach depth %d: %+v", spec, depth, pool.Stats())
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+func TestKeyPoolConfigValidation(t *testing.T) {
+	for _, config := range []KeyPoolConfig{
+		{High: 0},
+		{High: 4, Low: 5},
+		{High: 4, Low: -1},
+	} {
+		if _, err := NewKeyPool(DefaultRegistry(), config); err == nil {
+			t.Fatalf("expected %+v to be rejected", config)
+		}
+	}
+}
+
+func TestKeyPoolServesAndRefills(t *testing.T) {
+	pool, err := NewKeyPool(DefaultRegistry(), KeyPoolConfig{High: 4, Low: 2, Workers: 2})
+	if err != nil {
+		t.Fatalf("new pool: %v", err)
+	}
+	defer pool.Close()
+
+	if err := pool.Warm(domain.AlgorithmEd25519, ""); err != nil {
+		t.Fatalf("warm: %v", err)
+	}
+	waitForDepth(t, pool, domain.KeySpecEd25519, 4)
+
+	generator := NewDefaultKeyGenerator()
+	generator.WithKeyPool(pool)
+
+	seen := make(map[string]bool)
+	for i := 0; i < 4; i++ {
+		material, err := generator.Generate(domain.AlgorithmEd25519, "")
+		if err != nil {
+			t.Fatalf("generate: %v", err)
+		}
+		if seen[string(material.Public)] {
+			t.Fatal("pool handed out the same key twice")
+		}
+		seen[string(material.Public)] = true
+		if _, err := NewPEMCodec().DecodePrivate(material.Private); err != nil {
+			t.Fatalf("pooled key does not decode: %v", err)
+		}
+	}
+
+	stats := waitForDepth(t, pool, domain.KeySpecEd25519, 4)
+	if stats.Hits < 1 || stats.Low != 2 || stats.High != 4 {
+		t.Fatalf("unexpected stats %+v", stats)
+	}
+}
+
+func TestKeyPoolFallsBackInline(t *testing.T) {
+	pool, err := NewKeyPool(DefaultRegistry(), KeyPoolConfig{High: 1})
+	if err != nil {
+		t.Fatalf("new pool: %v", err)
+	}
+	pool.Close()
+
+	generator := NewDefaultKeyGenerator()
+	generator.WithKeyPool(pool)
+
+	material, err := generator.Generate(domain.AlgorithmECDSA, domain.KeySpecP256)
+	if err != nil {
+		t.Fatalf("generate: %v", err)
+	}
+	if len(material.Private) == 0 {
+		t.Fatal("expected inline generated key")
+	}
+	stats := pool.Stats()
+	if len(stats) != 1 || stats[0].KeySpec != domain.KeySpecP256 || stats[0].Misses != 1 || stats[0].Depth != 0 {
+		t.Fatalf("expected a recorded miss, got %+v", stats)
+	}
+
+	if err := pool.Warm("DSA", ""); err == nil {
+		t.Fatal("expected unknown algorithm to be rejected")
+	}
+}
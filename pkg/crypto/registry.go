@@ -0,0 +1,193 @@
+package crypto
+
+import (
+	stdlibcrypto "crypto"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+
+	"github.com/fiskaly/coding-challenges/signing-service-challenge/domain"
+)
+
+// KeyCodec serialises private keys to PEM and reads them back.
+type KeyCodec interface {
+	// Encode returns the PEM encoded public and private key.
+	Encode(private stdlibcrypto.PrivateKey) ([]byte, []byte, error)
+	DecodePrivate(pemBytes []byte) (stdlibcrypto.PrivateKey, error)
+	DecodePublic(pemBytes []byte) (stdlibcrypto.PublicKey, error)
+}
+
+// Provider bundles everything the service needs to support one algorithm.
+type Provider struct {
+	Algorithm domain.Algorithm
+	// KeySpecs lists the key sizes or curves offered for the algorithm.
+	KeySpecs       []domain.KeySpecDescriptor
+	DefaultKeySpec domain.KeySpec
+	// Schemes lists the signature schemes a device of this algorithm may use.
+	Schemes       []domain.SignatureScheme
+	DefaultScheme domain.SignatureScheme
+	// Digests lists the selectable digests, weakest first; empty when the
+	// algorithm hashes internally.
+	Digests []domain.Digest
+	// Encodings lists the selectable signature encodings, default first; empty
+	// when the algorithm has a single signature layout.
+	Encodings []domain.SignatureEncoding
+	// Symmetric marks MAC algorithms: the codec yields no public key and
+	// NewVerifier receives the decoded secret instead.
+	Symmetric bool
+	// Generate creates a private key for one of the offered key specs.
+	Generate func(spec domain.KeySpec) (stdlibcrypto.PrivateKey, error)
+	Codec    KeyCodec
+	// KeySpecOf classifies an imported private key, rejecting keys of another
+	// type. Nil means the algorithm does not accept imported keys.
+	KeySpecOf func(private stdlibcrypto.PrivateKey) (domain.KeySpec, error)
+	// NewSigner and NewVerifier apply the device's signing parameters (scheme, salt length, digest, encoding).
+	NewSigner   func(private stdlibcrypto.PrivateKey, device domain.Device) (Signer, error)
+	NewVerifier func(public stdlibcrypto.PublicKey, device domain.Device) (Verifier, error)
+}
+
+func (p Provider) validate() error {
+	switch {
+	case p.Algorithm == "":
+		return errors.New("provider algorithm is required")
+	case len(p.KeySpecs) == 0:
+		return fmt.Errorf("provider %s: at least one key spec is required", p.Algorithm)
+	case len(p.Schemes) == 0:
+		return fmt.Errorf("provider %s: at least one signature scheme is required", p.Algorithm)
+	case p.Generate == nil:
+		return fmt.Errorf("provider %s: key generator is required", p.Algorithm)
+	case p.Codec == nil:
+		return fmt.Errorf("provider %s: key codec is required", p.Algorithm)
+	case p.NewSigner == nil:
+		return fmt.Errorf("provider %s: signer constructor is required", p.Algorithm)
+	case p.NewVerifier == nil:
+		return fmt.Errorf("provider %s: verifier constructor is required", p.Algorithm)
+	}
+	return nil
+}
+
+func (p Provider) descriptor() domain.AlgorithmDescriptor {
+	return domain.AlgorithmDescriptor{
+		Algorithm:      p.Algorithm,
+		KeySpecs:       p.KeySpecs,
+		DefaultKeySpec: p.DefaultKeySpec,
+		Schemes:        p.Schemes,
+		DefaultScheme:  p.DefaultScheme,
+		Digests:        p.Digests,
+		Encodings:      p.Encodings,
+		Symmetric:      p.Symmetric,
+	}
+}
+
+// resolveKeySpec maps an empty spec to the provider default and rejects unknown specs.
+func (p Provider) resolveKeySpec(spec domain.KeySpec) (domain.KeySpec, error) {
+	if spec == "" {
+		if p.DefaultKeySpec != "" {
+			return p.DefaultKeySpec, nil
+		}
+		return p.KeySpecs[0].Spec, nil
+	}
+	for _, candidate := range p.KeySpecs {
+		if candidate.Spec == spec {
+			return spec, nil
+		}
+	}
+	return "", domain.ErrInvalidKeySpec
+}
+
+// Registry maps algorithms to their providers. It implements
+// domain.Algorithms, so domain validation sees exactly the algorithms
+// registered here; registries are independent of each other.
+type Registry struct {
+	mu          sync.RWMutex
+	providers   map[domain.Algorithm]Provider
+	descriptors map[domain.Algorithm]domain.AlgorithmDescriptor
+}
+
+var _ domain.Algorithms = (*Registry)(nil)
+
+// NewRegistry creates an empty registry.
+func NewRegistry() *Registry {
+	return &Registry{
+		providers:   make(map[domain.Algorithm]Provider),
+		descriptors: make(map[domain.Algorithm]domain.AlgorithmDescriptor),
+	}
+}
+
+var defaultRegistry = newDefaultRegistry()
+
+// DefaultRegistry returns the process-wide registry holding the built-in algorithms.
+func DefaultRegistry() *Registry {
+	return defaultRegistry
+}
+
+func newDefaultRegistry() *Registry {
+	registry := NewRegistry()
+	for _, provider := range []Provider{rsaProvider(), ecdsaProvider(), ed25519Provider(), hmacProvider()} {
+		if err := registry.Register(provider); err != nil {
+			panic(err)
+		}
+	}
+	return registry
+}
+
+// Register adds a provider and makes its algorithm known to validation
+// through this registry.
+func (r *Registry) Register(provider Provider) error {
+	if err := provider.validate(); err != nil {
+		return err
+	}
+	descriptor, err := domain.ValidateDescriptor(provider.descriptor())
+	if err != nil {
+		return fmt.Errorf("register algorithm %s: %w", provider.Algorithm, err)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, exists := r.providers[provider.Algorithm]; exists {
+		return fmt.Errorf("algorithm %s already registered", provider.Algorithm)
+	}
+	r.providers[provider.Algorithm] = provider
+	r.descriptors[provider.Algorithm] = descriptor
+	return nil
+}
+
+// Lookup returns the provider registered for an algorithm.
+func (r *Registry) Lookup(algorithm domain.Algorithm) (Provider, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	provider, ok := r.providers[algorithm]
+	return provider, ok
+}
+
+// DescribeAlgorithm returns the descriptor of a registered algorithm.
+func (r *Registry) DescribeAlgorithm(algorithm domain.Algorithm) (domain.AlgorithmDescriptor, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	descriptor, ok := r.descriptors[algorithm]
+	if !ok {
+		return domain.AlgorithmDescriptor{}, false
+	}
+	return descriptor.Clone(), true
+}
+
+// SupportedAlgorithms lists the registered algorithms in lexical order.
+func (r *Registry) SupportedAlgorithms() []domain.Algorithm {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	result := make([]domain.Algorithm, 0, len(r.providers))
+	for algorithm := range r.providers {
+		result = append(result, algorithm)
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i] < result[j] })
+	return result
+}
+
+func unexpectedKeyType(key interface{}) error {
+	return fmt.Errorf("unexpected key type %T", key)
+}
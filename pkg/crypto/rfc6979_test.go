@@ -0,0 +1,117 @@
+package crypto
+
+import (
+	"bytes"
+	stdlibcrypto "crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"encoding/asn1"
+	"encoding/hex"
+	"math/big"
+	"strings"
+	"testing"
+)
+
+// rfc6979Vectors are the known-answer tests of RFC 6979 appendix A.2.5 (P-256)
+// and A.2.6 (P-384).
+var rfc6979Vectors = []struct {
+	name    string
+	curve   elliptic.Curve
+	private string
+	hash    stdlibcrypto.Hash
+	message string
+	r, s    string
+}{
+	{
+		name:    "P-256/SHA-256/sample",
+		curve:   elliptic.P256(),
+		private: "C9AFA9D845BA75166B5C215767B1D6934E50C3DB36E89B127B8A622B120F6721",
+		hash:    stdlibcrypto.SHA256,
+		message: "sample",
+		r:       "EFD48B2AACB6A8FD1140DD9CD45E81D69D2C877B56AAF991C34D0EA84EAF3716",
+		s:       "F7CB1C942D657C41D436C7A1B6E29F65F3E900DBB9AFF4064DC4AB2F843ACDA8",
+	},
+	{
+		name:    "P-256/SHA-256/test",
+		curve:   elliptic.P256(),
+		private: "C9AFA9D845BA75166B5C215767B1D6934E50C3DB36E89B127B8A622B120F6721",
+		hash:    stdlibcrypto.SHA256,
+		message: "test",
+		r:       "F1ABB023518351CD71D881567B1EA663ED3EFCF6C5132B354F28D3B0B7D38367",
+		s:       "019F4113742A2B14BD25926B49C649155F267E60D3814B4C0CC84250E46F0083",
+	},
+	{
+		name:    "P-384/SHA-384/sample",
+		curve:   elliptic.P384(),
+		private: "6B9D3DAD2E1B8C1C05B19875B6659F4DE23C3B667BF297BA9AA47740787137D896D5724E4C70A825F872C9EA60D2EDF5",
+		hash:    stdlibcrypto.SHA384,
+		message: "sample",
+		r:       "94EDBB92A5ECB8AAD4736E56C691916B3F88140666CE9FA73D64C4EA95AD133C81A648152E44ACF96E36DD1E80FABE46",
+		s:       "99EF4AEB15F178CEA1FE40DB2603138F130E740A19624526203B6351D0A3A94FA329C145786E679E7B82C71A38628AC8",
+	},
+}
+
+func TestDeterministicECDSAKnownAnswers(t *testing.T) {
+	for _, vector := range rfc6979Vectors {
+		t.Run(vector.name, func(t *testing.T) {
+			key := rfc6979Key(t, vector.curve, vector.private)
+			signer := NewDeterministicECDSASigner(key, vector.hash)
+
+			signature, err := signer.Sign([]byte(vector.message))
+			if err != nil {
+				t.Fatalf("sign: %v", err)
+			}
+			var parsed struct{ R, S *big.Int }
+			if _, err := asn1.Unmarshal(signature, &parsed); err != nil {
+				t.Fatalf("decode signature: %v", err)
+			}
+			if got := hexInt(parsed.R, len(vector.r)); got != vector.r {
+				t.Fatalf("unexpected r\n got %s\nwant %s", got, vector.r)
+			}
+			if got := hexInt(parsed.S, len(vector.s)); got != vector.s {
+				t.Fatalf("unexpected s\n got %s\nwant %s", got, vector.s)
+			}
+
+			again, err := signer.Sign([]byte(vector.message))
+			if err != nil || !bytes.Equal(signature, again) {
+				t.Fatalf("expected repeated signing to be stable (%v)", err)
+			}
+			if ok, err := NewECDSAVerifier(&key.PublicKey, vector.hash).Verify([]byte(vector.message), signature); err != nil || !ok {
+				t.Fatalf("expected signature to verify, got %v (%v)", ok, err)
+			}
+		})
+	}
+}
+
+func TestRandomizedECDSAIsNotDeterministic(t *testing.T) {
+	key := rfc6979Key(t, elliptic.P256(), rfc6979Vectors[0].private)
+	signer := NewECDSASigner(key, stdlibcrypto.SHA256)
+
+	first, err := signer.Sign([]byte("sample"))
+	if err != nil {
+		t.Fatalf("sign: %v", err)
+	}
+	second, err := signer.Sign([]byte("sample"))
+	if err != nil {
+		t.Fatalf("sign: %v", err)
+	}
+	if bytes.Equal(first, second) {
+		t.Fatal("expected randomized nonces to produce distinct signatures")
+	}
+}
+
+func rfc6979Key(t *testing.T, curve elliptic.Curve, private string) *ecdsa.PrivateKey {
+	t.Helper()
+	d, ok := new(big.Int).SetString(private, 16)
+	if !ok {
+		t.Fatalf("decode private key %q", private)
+	}
+	key := &ecdsa.PrivateKey{PublicKey: ecdsa.PublicKey{Curve: curve}, D: d}
+	key.PublicKey.X, key.PublicKey.Y = curve.ScalarBaseMult(d.Bytes())
+	return key
+}
+
+// hexInt renders n as upper-case hex padded to width digits.
+func hexInt(n *big.Int, width int) string {
+	return strings.ToUpper(hex.EncodeToString(n.FillBytes(make([]byte, width/2))))
+}
@@ -1,9 +1,12 @@
 package crypto
 
 import (
+	stdlibcrypto "crypto"
 	"crypto/rsa"
-	"crypto/x509"
-	"encoding/pem"
+	"errors"
+	"fmt"
+
+	"github.com/fiskaly/coding-challenges/signing-service-challenge/domain"
 )
 
 // RSAKeyPair is a DTO that holds RSA private and public keys.
@@ -20,35 +23,104 @@ func NewRSAMarshaler() RSAMarshaler {
 	return RSAMarshaler{}
 }
 
-// Marshal takes an RSAKeyPair and encodes it to be written on disk.
+// Marshal encodes an RSAKeyPair with PEMCodec (SPKI public key, PKCS#8 private key).
 // It returns the public and the private key as a byte slice.
 func (m *RSAMarshaler) Marshal(keyPair RSAKeyPair) ([]byte, []byte, error) {
-	privateKeyBytes := x509.MarshalPKCS1PrivateKey(keyPair.Private)
-	publicKeyBytes := x509.MarshalPKCS1PublicKey(keyPair.Public)
-
-	encodedPrivate := pem.EncodeToMemory(&pem.Block{
-		Type:  "RSA_PRIVATE_KEY",
-		Bytes: privateKeyBytes,
-	})
-
-	encodePublic := pem.EncodeToMemory(&pem.Block{
-		Type:  "RSA_PUBLIC_KEY",
-		Bytes: publicKeyBytes,
-	})
-
-	return encodePublic, encodedPrivate, nil
+	if keyPair.Private == nil {
+		return nil, nil, errors.New("rsa private key is required")
+	}
+	return NewPEMCodec().Encode(keyPair.Private)
 }
 
-// Unmarshal takes an encoded RSA private key and transforms it into a rsa.PrivateKey.
+// Unmarshal decodes an RSA private key in any form PEMCodec reads, including
+// the legacy PKCS#1 "RSA_PRIVATE_KEY" blocks.
 func (m *RSAMarshaler) Unmarshal(privateKeyBytes []byte) (*RSAKeyPair, error) {
-	block, _ := pem.Decode(privateKeyBytes)
-	privateKey, err := x509.ParsePKCS1PrivateKey(block.Bytes)
+	key, err := NewPEMCodec().DecodePrivate(privateKeyBytes)
 	if err != nil {
 		return nil, err
 	}
+	privateKey, ok := key.(*rsa.PrivateKey)
+	if !ok {
+		return nil, unexpectedKeyType(key)
+	}
 
 	return &RSAKeyPair{
 		Private: privateKey,
 		Public:  &privateKey.PublicKey,
 	}, nil
 }
+
+var rsaKeySpecBits = map[domain.KeySpec]int{
+	domain.KeySpecRSA2048: 2048,
+	domain.KeySpecRSA3072: 3072,
+	domain.KeySpecRSA4096: 4096,
+}
+
+func rsaProvider() Provider {
+	return Provider{
+		Algorithm: domain.AlgorithmRSA,
+		KeySpecs: []domain.KeySpecDescriptor{
+			{Spec: domain.KeySpecRSA2048, Strength: 112},
+			{Spec: domain.KeySpecRSA3072, Strength: 128},
+			{Spec: domain.KeySpecRSA4096, Strength: 140},
+		},
+		DefaultKeySpec: domain.KeySpecRSA2048,
+		Schemes:        []domain.SignatureScheme{domain.SchemeRSAPKCS1v15, domain.SchemeRSAPSS},
+		DefaultScheme:  domain.SchemeRSAPKCS1v15,
+		Digests:        builtinDigests,
+		Generate: func(spec domain.KeySpec) (stdlibcrypto.PrivateKey, error) {
+			bits, ok := rsaKeySpecBits[spec]
+			if !ok {
+				return nil, domain.ErrInvalidKeySpec
+			}
+			generator := RSAGenerator{Bits: bits}
+			pair, err := generator.Generate()
+			if err != nil {
+				return nil, err
+			}
+			return pair.Private, nil
+		},
+		Codec: NewPEMCodec(),
+		KeySpecOf: func(private stdlibcrypto.PrivateKey) (domain.KeySpec, error) {
+			key, ok := private.(*rsa.PrivateKey)
+			if !ok {
+				return "", importedKeyMismatch(domain.AlgorithmRSA, private)
+			}
+			bits := key.N.BitLen()
+			for spec, specBits := range rsaKeySpecBits {
+				if specBits == bits {
+					return spec, nil
+				}
+			}
+			return "", domain.ValidationError{Field: "private_key", Message: fmt.Sprintf("RSA key size %d is not supported", bits)}
+		},
+		NewSigner: func(private stdlibcrypto.PrivateKey, device domain.Device) (Signer, error) {
+			key, ok := private.(*rsa.PrivateKey)
+			if !ok {
+				return nil, unexpectedKeyType(private)
+			}
+			hash, err := HashForDigest(device.Digest)
+			if err != nil {
+				return nil, err
+			}
+			if device.Scheme == domain.SchemeRSAPSS {
+				return NewRSAPSSSigner(key, hash, device.SaltLength), nil
+			}
+			return NewRSASigner(key, hash), nil
+		},
+		NewVerifier: func(public stdlibcrypto.PublicKey, device domain.Device) (Verifier, error) {
+			key, ok := public.(*rsa.PublicKey)
+			if !ok {
+				return nil, unexpectedKeyType(public)
+			}
+			hash, err := HashForDigest(device.Digest)
+			if err != nil {
+				return nil, err
+			}
+			if device.Scheme == domain.SchemeRSAPSS {
+				return NewRSAPSSVerifier(key, hash, device.SaltLength), nil
+			}
+			return NewRSAVerifier(key, hash), nil
+		},
+	}
+}
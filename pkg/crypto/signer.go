@@ -4,3 +4,10 @@ package crypto
 type Signer interface {
 	Sign(dataToBeSigned []byte) ([]byte, error)
 }
+
+// Verifier checks signatures produced by the matching Signer.
+type Verifier interface {
+	// Verify reports whether signature is valid for data. An error is only
+	// returned when verification could not be performed at all.
+	Verify(data, signature []byte) (bool, error)
+}
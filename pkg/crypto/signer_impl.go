@@ -3,32 +3,52 @@ package crypto
 import (
 	stdlibcrypto "crypto"
 	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/hmac"
 	"crypto/rand"
 	"crypto/rsa"
 	"crypto/sha256"
 	"errors"
 	"fmt"
+
+	"github.com/fiskaly/coding-challenges/signing-service-challenge/domain"
 )
 
-// RSASigner wraps an RSA private key and signs payloads using PKCS#1 v1.5.
+// RSASigner wraps an RSA private key and signs payloads using PKCS#1 v1.5 or PSS.
 type RSASigner struct {
-	key *rsa.PrivateKey
+	key        *rsa.PrivateKey
+	hash       stdlibcrypto.Hash
+	scheme     domain.SignatureScheme
+	saltLength int
+}
+
+// NewRSASigner constructs an RSASigner using PKCS#1 v1.5 over the given hash.
+func NewRSASigner(key *rsa.PrivateKey, hash stdlibcrypto.Hash) *RSASigner {
+	return &RSASigner{key: key, hash: hash, scheme: domain.SchemeRSAPKCS1v15}
 }
 
-// NewRSASigner constructs an RSASigner from a private key.
-func NewRSASigner(key *rsa.PrivateKey) *RSASigner {
-	return &RSASigner{key: key}
+// NewRSAPSSSigner constructs an RSASigner using RSASSA-PSS. A zero salt
+// length uses a salt as long as the digest.
+func NewRSAPSSSigner(key *rsa.PrivateKey, hash stdlibcrypto.Hash, saltLength int) *RSASigner {
+	return &RSASigner{key: key, hash: hash, scheme: domain.SchemeRSAPSS, saltLength: saltLength}
 }
 
-// Sign signs the payload using SHA-256 and PKCS#1 v1.5.
+// Sign signs the digest of the payload with the configured scheme.
 func (s *RSASigner) Sign(dataToBeSigned []byte) ([]byte, error) {
 	if s == nil || s.key == nil {
 		// Prevent panics when the factory failed to wire up the signer.
 		return nil, errors.New("rsa signer not initialised")
 	}
 
-	hash := sha256.Sum256(dataToBeSigned)
-	signature, err := rsa.SignPKCS1v15(rand.Reader, s.key, stdlibcrypto.SHA256, hash[:])
+	digest := hashData(s.hash, dataToBeSigned)
+	var signature []byte
+	var err error
+	switch s.scheme {
+	case domain.SchemeRSAPSS:
+		signature, err = rsa.SignPSS(rand.Reader, s.key, s.hash, digest, pssOptions(s.hash, s.saltLength))
+	default:
+		signature, err = rsa.SignPKCS1v15(rand.Reader, s.key, s.hash, digest)
+	}
 	if err != nil {
 		return nil, fmt.Errorf("rsa sign: %w", err)
 	}
@@ -36,28 +56,107 @@ func (s *RSASigner) Sign(dataToBeSigned []byte) ([]byte, error) {
 	return signature, nil
 }
 
-// ECDSASigner signs using ECDSA and returns ASN.1 DER encoded signatures.
+// pssOptions maps the device salt length onto crypto/rsa options.
+func pssOptions(hash stdlibcrypto.Hash, saltLength int) *rsa.PSSOptions {
+	if saltLength == 0 {
+		saltLength = rsa.PSSSaltLengthEqualsHash
+	}
+	return &rsa.PSSOptions{SaltLength: saltLength, Hash: hash}
+}
+
+// ECDSASigner signs using ECDSA and returns ASN.1 DER encoded signatures
+// unless another encoding is selected with WithEncoding.
 type ECDSASigner struct {
-	key *ecdsa.PrivateKey
+	key           *ecdsa.PrivateKey
+	hash          stdlibcrypto.Hash
+	deterministic bool
+	encoding      domain.SignatureEncoding
 }
 
-// NewECDSASigner constructs an ECDSASigner from a private key.
-func NewECDSASigner(key *ecdsa.PrivateKey) *ECDSASigner {
-	return &ECDSASigner{key: key}
+// NewECDSASigner constructs an ECDSASigner hashing payloads with hash and
+// drawing a random nonce per signature.
+func NewECDSASigner(key *ecdsa.PrivateKey, hash stdlibcrypto.Hash) *ECDSASigner {
+	return &ECDSASigner{key: key, hash: hash}
 }
 
-// Sign signs the payload using SHA-256 and returns ASN.1 encoded signature.
+// NewDeterministicECDSASigner constructs an ECDSASigner deriving nonces from
+// the key and digest per RFC 6979, so equal payloads yield equal signatures.
+func NewDeterministicECDSASigner(key *ecdsa.PrivateKey, hash stdlibcrypto.Hash) *ECDSASigner {
+	return &ECDSASigner{key: key, hash: hash, deterministic: true}
+}
+
+// WithEncoding selects the signature layout; empty or EncodingDER keeps ASN.1 DER.
+func (s *ECDSASigner) WithEncoding(encoding domain.SignatureEncoding) *ECDSASigner {
+	s.encoding = encoding
+	return s
+}
+
+// Sign signs the digest of the payload and returns the encoded signature.
 func (s *ECDSASigner) Sign(dataToBeSigned []byte) ([]byte, error) {
 	if s == nil || s.key == nil {
 		// Fail fast if the signer misses required key material.
 		return nil, errors.New("ecdsa signer not initialised")
 	}
 
-	hash := sha256.Sum256(dataToBeSigned)
-	signature, err := ecdsa.SignASN1(rand.Reader, s.key, hash[:])
+	var (
+		signature []byte
+		err       error
+	)
+	digest := hashData(s.hash, dataToBeSigned)
+	if s.deterministic {
+		// A nil random source selects RFC 6979 nonce derivation.
+		signature, err = s.key.Sign(nil, digest, s.hash)
+	} else {
+		signature, err = ecdsa.SignASN1(rand.Reader, s.key, digest)
+	}
 	if err != nil {
 		return nil, fmt.Errorf("ecdsa sign: %w", err)
 	}
 
+	if s.encoding == domain.EncodingP1363 {
+		return ECDSAToP1363(s.key.Curve, signature)
+	}
 	return signature, nil
 }
+
+// Ed25519Signer signs using pure Ed25519 (RFC 8032) without pre-hashing.
+type Ed25519Signer struct {
+	key ed25519.PrivateKey
+}
+
+// NewEd25519Signer constructs an Ed25519Signer from a private key.
+func NewEd25519Signer(key ed25519.PrivateKey) *Ed25519Signer {
+	return &Ed25519Signer{key: key}
+}
+
+// Sign signs the payload and returns the 64-byte Ed25519 signature.
+func (s *Ed25519Signer) Sign(dataToBeSigned []byte) ([]byte, error) {
+	if s == nil || len(s.key) != ed25519.PrivateKeySize {
+		// Guard against truncated keys, ed25519.Sign panics on them.
+		return nil, errors.New("ed25519 signer not initialised")
+	}
+
+	return ed25519.Sign(s.key, dataToBeSigned), nil
+}
+
+// HMACSigner computes HMAC-SHA256 tags (RFC 2104) under a device secret.
+type HMACSigner struct {
+	key HMACKey
+}
+
+// NewHMACSigner constructs an HMACSigner from a secret key.
+func NewHMACSigner(key HMACKey) *HMACSigner {
+	return &HMACSigner{key: key}
+}
+
+// Sign returns the 32-byte HMAC-SHA256 tag of the payload.
+func (s *HMACSigner) Sign(dataToBeSigned []byte) ([]byte, error) {
+	if s == nil || len(s.key) == 0 {
+		// An empty secret would still produce a tag, just not an authenticating one.
+		return nil, errors.New("hmac signer not initialised")
+	}
+
+	mac := hmac.New(sha256.New, s.key)
+	mac.Write(dataToBeSigned)
+	return mac.Sum(nil), nil
+}
@@ -0,0 +1,59 @@
+package crypto
+
+import (
+	"testing"
+
+	"github.com/fiskaly/coding-challenges/signing-service-challenge/domain"
+	"github.com/google/uuid"
+)
+
+func TestSignerVerifierRoundTrip(t *testing.T) {
+	cases := []domain.Device{
+		{Algorithm: domain.AlgorithmRSA},
+		{Algorithm: domain.AlgorithmRSA, Scheme: domain.SchemeRSAPSS},
+		{Algorithm: domain.AlgorithmRSA, Scheme: domain.SchemeRSAPSS, SaltLength: 48},
+		{Algorithm: domain.AlgorithmRSA, Digest: domain.DigestSHA512},
+		{Algorithm: domain.AlgorithmRSA, Scheme: domain.SchemeRSAPSS, Digest: domain.DigestSHA3_256},
+		{Algorithm: domain.AlgorithmECDSA, KeySpec: domain.KeySpecP256},
+		{Algorithm: domain.AlgorithmECDSA, KeySpec: domain.KeySpecP256, Digest: domain.DigestSHA3_256},
+		{Algorithm: domain.AlgorithmECDSA, KeySpec: domain.KeySpecP384, Digest: domain.DigestSHA384},
+		{Algorithm: domain.AlgorithmECDSA, KeySpec: domain.KeySpecP256, Scheme: domain.SchemeECDSADeterministic},
+		{Algorithm: domain.AlgorithmECDSA, KeySpec: domain.KeySpecP521, Scheme: domain.SchemeECDSADeterministic, Digest: domain.DigestSHA3_256},
+		{Algorithm: domain.AlgorithmECDSA, KeySpec: domain.KeySpecP256, Encoding: domain.EncodingP1363},
+		{Algorithm: domain.AlgorithmECDSA, KeySpec: domain.KeySpecP521, Scheme: domain.SchemeECDSADeterministic, Encoding: domain.EncodingP1363},
+		{Algorithm: domain.AlgorithmEd25519},
+		{Algorithm: domain.AlgorithmHMAC},
+	}
+
+	generator := NewDefaultKeyGenerator()
+	for _, device := range cases {
+		device.ID = uuid.New()
+		t.Run(string(device.Algorithm)+"/"+string(device.Scheme)+"/"+string(device.Digest)+"/"+string(device.Encoding), func(t *testing.T) {
+			material, err := generator.Generate(device.Algorithm, device.KeySpec)
+			if err != nil {
+				t.Fatalf("generate: %v", err)
+			}
+
+			factory := NewSignerFactory()
+			signer, err := factory.SignerFor(device, material)
+			if err != nil {
+				t.Fatalf("signer: %v", err)
+			}
+			signature, err := signer.Sign([]byte("payload"))
+			if err != nil {
+				t.Fatalf("sign: %v", err)
+			}
+
+			verifier, err := factory.VerifierFor(device, material)
+			if err != nil {
+				t.Fatalf("verifier: %v", err)
+			}
+			if ok, err := verifier.Verify([]byte("payload"), signature); err != nil || !ok {
+				t.Fatalf("expected valid signature, got %v (%v)", ok, err)
+			}
+			if ok, _ := verifier.Verify([]byte("tampered"), signature); ok {
+				t.Fatal("expected tampered payload to fail verification")
+			}
+		})
+	}
+}
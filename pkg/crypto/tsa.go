@@ -0,0 +1,249 @@
+package crypto
+
+import (
+	"bytes"
+	"context"
+	stdlibcrypto "crypto"
+	"crypto/ecdsa"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"errors"
+	"fmt"
+	"math/big"
+	"sort"
+	"time"
+
+	"github.com/fiskaly/coding-challenges/signing-service-challenge/domain"
+	"github.com/fiskaly/coding-challenges/signing-service-challenge/internal/devices"
+)
+
+// DefaultTSACommonName names the TSA certificate subject unless configured.
+const DefaultTSACommonName = "Signing Service Time-Stamping Authority"
+
+// DefaultTSAPolicy is the policy under which tokens are issued unless
+// configured. It is the example policy of the OpenSSL TSA configuration;
+// deployments should use an OID from their own arc.
+var DefaultTSAPolicy = asn1.ObjectIdentifier{1, 2, 3, 4, 1}
+
+var (
+	oidContentTSTInfo         = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 9, 16, 1, 4}
+	oidAttributeContentType   = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 9, 3}
+	oidAttributeMessageDigest = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 9, 4}
+	oidAttributeSigningCertV2 = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 9, 16, 2, 47}
+)
+
+var errUnsupportedImprint = errors.New("message imprint must be a SHA-256 digest")
+
+// TimestampAuthorityConfig configures the built-in TSA.
+type TimestampAuthorityConfig struct {
+	// CommonName names the TSA certificate subject.
+	CommonName string
+	// Key is a PEM encoded ECDSA or RSA private key; nil generates an ECDSA
+	// P-384 key that lives as long as the process.
+	Key []byte
+	// Policy is the TSA policy stated in every token.
+	Policy asn1.ObjectIdentifier
+	// Clock supplies the genTime of tokens; nil uses time.Now.
+	Clock func() time.Time
+}
+
+// TimestampAuthority implements internal/devices.Timestamper with a local
+// RFC 3161 TSA whose certificate is issued by the service root CA, so tokens
+// verify against the same trust anchor as device certificates.
+type TimestampAuthority struct {
+	key         stdlibcrypto.Signer
+	certificate *x509.Certificate
+	algorithm   pkix.AlgorithmIdentifier
+	policy      asn1.ObjectIdentifier
+	clock       func() time.Time
+}
+
+var _ devices.Timestamper = (*TimestampAuthority)(nil)
+
+// NewTimestampAuthority loads or generates the TSA key and certifies it under ca.
+func NewTimestampAuthority(ca *CertificateAuthority, config TimestampAuthorityConfig) (*TimestampAuthority, error) {
+	if ca == nil {
+		return nil, errors.New("time-stamping authority requires a certificate authority")
+	}
+	if config.CommonName == "" {
+		config.CommonName = DefaultTSACommonName
+	}
+	if config.Policy == nil {
+		config.Policy = DefaultTSAPolicy
+	}
+	if config.Clock == nil {
+		config.Clock = time.Now
+	}
+
+	key, err := loadSigningKey(config.Key, "TSA")
+	if err != nil {
+		return nil, err
+	}
+	var algorithm pkix.AlgorithmIdentifier
+	switch key.Public().(type) {
+	case *ecdsa.PublicKey:
+		algorithm = pkix.AlgorithmIdentifier{Algorithm: ecdsaOIDs[domain.DigestSHA256]}
+	case *rsa.PublicKey:
+		algorithm = pkix.AlgorithmIdentifier{Algorithm: oidRSAEncryption, Parameters: asn1.NullRawValue}
+	default:
+		return nil, unexpectedKeyType(key)
+	}
+	certificate, err := ca.IssueTimestamping(key.Public(), config.CommonName, config.Clock())
+	if err != nil {
+		return nil, err
+	}
+
+	return &TimestampAuthority{
+		key:         key,
+		certificate: certificate,
+		algorithm:   algorithm,
+		policy:      config.Policy,
+		clock:       config.Clock,
+	}, nil
+}
+
+// Certificate returns the DER encoded TSA certificate.
+func (a *TimestampAuthority) Certificate() []byte {
+	return a.certificate.Raw
+}
+
+type tstMessageImprint struct {
+	HashAlgorithm pkix.AlgorithmIdentifier
+	HashedMessage []byte
+}
+
+type tstInfo struct {
+	Version        int
+	Policy         asn1.ObjectIdentifier
+	MessageImprint tstMessageImprint
+	SerialNumber   *big.Int
+	GenTime        time.Time `asn1:"generalized"`
+}
+
+type cmsAttribute struct {
+	Type   asn1.ObjectIdentifier
+	Values []asn1.RawValue `asn1:"set"`
+}
+
+// essCertIDv2 identifies the signing certificate by its SHA-256 hash, the
+// default hash algorithm, which is therefore omitted (RFC 5035 section 4).
+type essCertIDv2 struct {
+	CertHash []byte
+}
+
+type signingCertificateV2 struct {
+	Certs []essCertIDv2
+}
+
+// Timestamp issues a TimeStampToken (RFC 3161 section 2.4.2) over a SHA-256
+// digest: a SignedData encapsulating the TSTInfo, signed over the content
+// type, message digest and ESS signing certificate attributes.
+func (a *TimestampAuthority) Timestamp(_ context.Context, digest []byte) ([]byte, error) {
+	if len(digest) != sha256.Size {
+		return nil, errUnsupportedImprint
+	}
+	serial, err := newSerialNumber()
+	if err != nil {
+		return nil, err
+	}
+	sha256Algorithm := pkix.AlgorithmIdentifier{Algorithm: digestOIDs[domain.DigestSHA256]}
+
+	info, err := asn1.Marshal(tstInfo{
+		Version:        1,
+		Policy:         a.policy,
+		MessageImprint: tstMessageImprint{HashAlgorithm: sha256Algorithm, HashedMessage: digest},
+		SerialNumber:   serial,
+		GenTime:        a.clock().UTC().Truncate(time.Second),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("marshal tst info: %w", err)
+	}
+	attributes, err := a.signedAttributes(info)
+	if err != nil {
+		return nil, err
+	}
+	// The signature covers the DER SET OF encoding of the attributes (RFC 5652 section 5.4).
+	set, err := asn1.Marshal(asn1.RawValue{Class: asn1.ClassUniversal, Tag: asn1.TagSet, IsCompound: true, Bytes: attributes})
+	if err != nil {
+		return nil, fmt.Errorf("marshal signed attributes: %w", err)
+	}
+	hashed := sha256.Sum256(set)
+	signature, err := a.key.Sign(rand.Reader, hashed[:], stdlibcrypto.SHA256)
+	if err != nil {
+		return nil, fmt.Errorf("sign time-stamp token: %w", err)
+	}
+
+	identifier, err := issuerAndSerialNumber(a.certificate)
+	if err != nil {
+		return nil, err
+	}
+	content, err := asn1.Marshal(info)
+	if err != nil {
+		return nil, fmt.Errorf("marshal tst info content: %w", err)
+	}
+	signed, err := asn1.Marshal(cmsSignedData{
+		Version:          cmsVersionKeyID, // eContentType other than id-data
+		DigestAlgorithms: []pkix.AlgorithmIdentifier{sha256Algorithm},
+		EncapContentInfo: cmsEncapsulatedContentInfo{
+			ContentType: oidContentTSTInfo,
+			Content:     asn1.RawValue{Class: asn1.ClassContextSpecific, Tag: 0, IsCompound: true, Bytes: content},
+		},
+		Certificates: asn1.RawValue{Class: asn1.ClassContextSpecific, Tag: 0, IsCompound: true, Bytes: a.certificate.Raw},
+		SignerInfos: []cmsSignerInfo{{
+			Version:            cmsVersionIssuerSerial,
+			SignerIdentifier:   asn1.RawValue{FullBytes: identifier},
+			DigestAlgorithm:    sha256Algorithm,
+			SignedAttributes:   asn1.RawValue{Class: asn1.ClassContextSpecific, Tag: 0, IsCompound: true, Bytes: attributes},
+			SignatureAlgorithm: a.algorithm,
+			Signature:          signature,
+		}},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("marshal signed data: %w", err)
+	}
+	token, err := asn1.Marshal(cmsContentInfo{
+		ContentType: oidContentSignedData,
+		Content:     asn1.RawValue{Class: asn1.ClassContextSpecific, Tag: 0, IsCompound: true, Bytes: signed},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("marshal time-stamp token: %w", err)
+	}
+	return token, nil
+}
+
+// signedAttributes returns the concatenated DER attributes in SET OF order.
+func (a *TimestampAuthority) signedAttributes(info []byte) ([]byte, error) {
+	contentType, err := asn1.Marshal(oidContentTSTInfo)
+	if err != nil {
+		return nil, err
+	}
+	messageDigest := sha256.Sum256(info)
+	digestValue, err := asn1.Marshal(messageDigest[:])
+	if err != nil {
+		return nil, err
+	}
+	certificateHash := sha256.Sum256(a.certificate.Raw)
+	certificateValue, err := asn1.Marshal(signingCertificateV2{Certs: []essCertIDv2{{CertHash: certificateHash[:]}}})
+	if err != nil {
+		return nil, err
+	}
+
+	var encoded [][]byte
+	for _, attribute := range []cmsAttribute{
+		{Type: oidAttributeContentType, Values: []asn1.RawValue{{FullBytes: contentType}}},
+		{Type: oidAttributeMessageDigest, Values: []asn1.RawValue{{FullBytes: digestValue}}},
+		{Type: oidAttributeSigningCertV2, Values: []asn1.RawValue{{FullBytes: certificateValue}}},
+	} {
+		der, err := asn1.Marshal(attribute)
+		if err != nil {
+			return nil, fmt.Errorf("marshal attribute %v: %w", attribute.Type, err)
+		}
+		encoded = append(encoded, der)
+	}
+	sort.Slice(encoded, func(i, j int) bool { return bytes.Compare(encoded[i], encoded[j]) < 0 })
+	return bytes.Join(encoded, nil), nil
+}
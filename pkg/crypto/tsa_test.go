@@ -0,0 +1,99 @@
+package crypto
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/asn1"
+	"testing"
+	"time"
+
+	"github.com/fiskaly/coding-challenges/signing-service-challenge/domain"
+)
+
+func TestTimestampAuthorityIssuesTokens(t *testing.T) {
+	ca, err := NewCertificateAuthority(CertificateAuthorityConfig{})
+	if err != nil {
+		t.Fatalf("new CA: %v", err)
+	}
+	root, err := x509.ParseCertificate(ca.Root())
+	if err != nil {
+		t.Fatalf("parse root: %v", err)
+	}
+	rsaKey, err := NewDefaultKeyGenerator().Generate(domain.AlgorithmRSA, "")
+	if err != nil {
+		t.Fatalf("generate: %v", err)
+	}
+	now := time.Now().UTC().Truncate(time.Second)
+
+	for name, tc := range map[string]struct {
+		key       []byte
+		algorithm x509.SignatureAlgorithm
+	}{
+		"ecdsa": {nil, x509.ECDSAWithSHA256},
+		"rsa":   {rsaKey.Private, x509.SHA256WithRSA},
+	} {
+		t.Run(name, func(t *testing.T) {
+			tsa, err := NewTimestampAuthority(ca, TimestampAuthorityConfig{Key: tc.key, Clock: func() time.Time { return now }})
+			if err != nil {
+				t.Fatalf("new TSA: %v", err)
+			}
+			certificate, err := x509.ParseCertificate(tsa.Certificate())
+			if err != nil {
+				t.Fatalf("parse TSA certificate: %v", err)
+			}
+			roots := x509.NewCertPool()
+			roots.AddCert(root)
+			if _, err := certificate.Verify(x509.VerifyOptions{Roots: roots, CurrentTime: now, KeyUsages: []x509.ExtKeyUsage{x509.ExtKeyUsageTimeStamping}}); err != nil {
+				t.Fatalf("TSA certificate does not chain to the root: %v", err)
+			}
+
+			digest := sha256.Sum256([]byte("signature"))
+			token, err := tsa.Timestamp(context.Background(), digest[:])
+			if err != nil {
+				t.Fatalf("timestamp: %v", err)
+			}
+
+			signed := parseSignedData(t, token)
+			if signed.Version != cmsVersionKeyID || !bytes.Equal(signed.Certificates.Bytes, tsa.Certificate()) {
+				t.Fatalf("unexpected signed data version %d or certificates", signed.Version)
+			}
+			var encapsulated cmsEncapsulatedContentInfo
+			if _, err := asn1.Unmarshal(signed.EncapContentInfo.FullBytes, &encapsulated); err != nil {
+				t.Fatalf("parse encapsulated content: %v", err)
+			}
+			var content []byte
+			if _, err := asn1.Unmarshal(encapsulated.Content.Bytes, &content); err != nil || !encapsulated.ContentType.Equal(oidContentTSTInfo) {
+				t.Fatalf("expected encapsulated TSTInfo: %v", err)
+			}
+			var info tstInfo
+			if _, err := asn1.Unmarshal(content, &info); err != nil {
+				t.Fatalf("parse TSTInfo: %v", err)
+			}
+			if !bytes.Equal(info.MessageImprint.HashedMessage, digest[:]) || !info.GenTime.Equal(now) || !info.Policy.Equal(DefaultTSAPolicy) {
+				t.Fatalf("unexpected TSTInfo %+v", info)
+			}
+
+			signer := signed.SignerInfos[0]
+			attributes := signer.SignedAttributes.Bytes
+			contentDigest := sha256.Sum256(content)
+			certificateHash := sha256.Sum256(tsa.Certificate())
+			if !bytes.Contains(attributes, contentDigest[:]) || !bytes.Contains(attributes, certificateHash[:]) {
+				t.Fatal("expected message digest and signing certificate attributes")
+			}
+			set, _ := asn1.Marshal(asn1.RawValue{Class: asn1.ClassUniversal, Tag: asn1.TagSet, IsCompound: true, Bytes: attributes})
+			if err := certificate.CheckSignature(tc.algorithm, set, signer.Signature); err != nil {
+				t.Fatalf("token signature does not verify: %v", err)
+			}
+		})
+	}
+
+	tsa, err := NewTimestampAuthority(ca, TimestampAuthorityConfig{})
+	if err != nil {
+		t.Fatalf("new TSA: %v", err)
+	}
+	if _, err := tsa.Timestamp(context.Background(), []byte("short")); err == nil {
+		t.Fatal("expected a non SHA-256 imprint to be rejected")
+	}
+}
@@ -0,0 +1,127 @@
+package crypto
+
+import (
+	stdlibcrypto "crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/hmac"
+	"crypto/rsa"
+	"errors"
+
+	"github.com/fiskaly/coding-challenges/signing-service-challenge/domain"
+)
+
+// RSAVerifier checks PKCS#1 v1.5 or PSS signatures.
+type RSAVerifier struct {
+	key        *rsa.PublicKey
+	hash       stdlibcrypto.Hash
+	scheme     domain.SignatureScheme
+	saltLength int
+}
+
+// NewRSAVerifier constructs a PKCS#1 v1.5 RSAVerifier over the given hash.
+func NewRSAVerifier(key *rsa.PublicKey, hash stdlibcrypto.Hash) *RSAVerifier {
+	return &RSAVerifier{key: key, hash: hash, scheme: domain.SchemeRSAPKCS1v15}
+}
+
+// NewRSAPSSVerifier constructs an RSASSA-PSS RSAVerifier expecting the given salt length.
+func NewRSAPSSVerifier(key *rsa.PublicKey, hash stdlibcrypto.Hash, saltLength int) *RSAVerifier {
+	return &RSAVerifier{key: key, hash: hash, scheme: domain.SchemeRSAPSS, saltLength: saltLength}
+}
+
+// Verify reports whether signature matches the digest of data.
+func (v *RSAVerifier) Verify(data, signature []byte) (bool, error) {
+	if v == nil || v.key == nil {
+		return false, errors.New("rsa verifier not initialised")
+	}
+
+	digest := hashData(v.hash, data)
+	var err error
+	switch v.scheme {
+	case domain.SchemeRSAPSS:
+		err = rsa.VerifyPSS(v.key, v.hash, digest, signature, pssOptions(v.hash, v.saltLength))
+	default:
+		err = rsa.VerifyPKCS1v15(v.key, v.hash, digest, signature)
+	}
+	return err == nil, nil
+}
+
+// ECDSAVerifier checks ECDSA signatures, ASN.1 DER encoded unless another
+// encoding is selected with WithEncoding.
+type ECDSAVerifier struct {
+	key      *ecdsa.PublicKey
+	hash     stdlibcrypto.Hash
+	encoding domain.SignatureEncoding
+}
+
+// NewECDSAVerifier constructs an ECDSAVerifier hashing payloads with hash.
+func NewECDSAVerifier(key *ecdsa.PublicKey, hash stdlibcrypto.Hash) *ECDSAVerifier {
+	return &ECDSAVerifier{key: key, hash: hash}
+}
+
+// WithEncoding selects the expected signature layout; empty or EncodingDER keeps ASN.1 DER.
+func (v *ECDSAVerifier) WithEncoding(encoding domain.SignatureEncoding) *ECDSAVerifier {
+	v.encoding = encoding
+	return v
+}
+
+// Verify reports whether signature matches the digest of data.
+func (v *ECDSAVerifier) Verify(data, signature []byte) (bool, error) {
+	if v == nil || v.key == nil {
+		return false, errors.New("ecdsa verifier not initialised")
+	}
+
+	if v.encoding == domain.EncodingP1363 {
+		der, err := ECDSAFromP1363(v.key.Curve, signature)
+		if err != nil {
+			// A signature of the wrong width is invalid, not a verification failure.
+			return false, nil
+		}
+		signature = der
+	}
+	return ecdsa.VerifyASN1(v.key, hashData(v.hash, data), signature), nil
+}
+
+// Ed25519Verifier checks pure Ed25519 signatures.
+type Ed25519Verifier struct {
+	key ed25519.PublicKey
+}
+
+// NewEd25519Verifier constructs an Ed25519Verifier from a public key.
+func NewEd25519Verifier(key ed25519.PublicKey) *Ed25519Verifier {
+	return &Ed25519Verifier{key: key}
+}
+
+// Verify reports whether signature is a valid Ed25519 signature of data.
+func (v *Ed25519Verifier) Verify(data, signature []byte) (bool, error) {
+	if v == nil || len(v.key) != ed25519.PublicKeySize {
+		// ed25519.Verify panics on malformed public keys.
+		return false, errors.New("ed25519 verifier not initialised")
+	}
+
+	return ed25519.Verify(v.key, data, signature), nil
+}
+
+// HMACVerifier recomputes HMAC-SHA256 tags with the device secret, so it only
+// runs where the secret is held.
+type HMACVerifier struct {
+	signer *HMACSigner
+}
+
+// NewHMACVerifier constructs an HMACVerifier from a secret key.
+func NewHMACVerifier(key HMACKey) *HMACVerifier {
+	return &HMACVerifier{signer: NewHMACSigner(key)}
+}
+
+// Verify reports whether signature is the HMAC-SHA256 tag of data, comparing
+// in constant time.
+func (v *HMACVerifier) Verify(data, signature []byte) (bool, error) {
+	if v == nil {
+		return false, errors.New("hmac verifier not initialised")
+	}
+	expected, err := v.signer.Sign(data)
+	if err != nil {
+		return false, err
+	}
+	return hmac.Equal(expected, signature), nil
+}
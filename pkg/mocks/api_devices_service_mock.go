@@ -37,6 +37,21 @@ func (m *MockDevicesService) EXPECT() *MockDevicesServiceMockRecorder {
 	return m.recorder
 }
 
+// CreateCertificateRequest mocks base method.
+func (m *MockDevicesService) CreateCertificateRequest(arg0 context.Context, arg1 uuid.UUID) (*devices.CertificateRequestResult, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateCertificateRequest", arg0, arg1)
+	ret0, _ := ret[0].(*devices.CertificateRequestResult)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateCertificateRequest indicates an expected call of CreateCertificateRequest.
+func (mr *MockDevicesServiceMockRecorder) CreateCertificateRequest(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateCertificateRequest", reflect.TypeOf((*MockDevicesService)(nil).CreateCertificateRequest), arg0, arg1)
+}
+
 // CreateDevice mocks base method.
 func (m *MockDevicesService) CreateDevice(arg0 context.Context, arg1 devices.CreateDeviceInput) (*devices.CreateDeviceResult, error) {
 	m.ctrl.T.Helper()
@@ -66,6 +81,21 @@ func (mr *MockDevicesServiceMockRecorder) DeleteDevice(arg0, arg1 interface{}) *
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteDevice", reflect.TypeOf((*MockDevicesService)(nil).DeleteDevice), arg0, arg1)
 }
 
+// GetCertificate mocks base method.
+func (m *MockDevicesService) GetCertificate(arg0 context.Context, arg1 uuid.UUID, arg2 int) (*devices.CertificateResult, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetCertificate", arg0, arg1, arg2)
+	ret0, _ := ret[0].(*devices.CertificateResult)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetCertificate indicates an expected call of GetCertificate.
+func (mr *MockDevicesServiceMockRecorder) GetCertificate(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetCertificate", reflect.TypeOf((*MockDevicesService)(nil).GetCertificate), arg0, arg1, arg2)
+}
+
 // GetCounters mocks base method.
 func (m *MockDevicesService) GetCounters(arg0 context.Context, arg1 []uuid.UUID) (map[uuid.UUID]uint64, error) {
 	m.ctrl.T.Helper()
@@ -96,6 +126,36 @@ func (mr *MockDevicesServiceMockRecorder) GetDevice(arg0, arg1 interface{}) *gom
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetDevice", reflect.TypeOf((*MockDevicesService)(nil).GetDevice), arg0, arg1)
 }
 
+// GetPublicKey mocks base method.
+func (m *MockDevicesService) GetPublicKey(arg0 context.Context, arg1 uuid.UUID, arg2 int) (*devices.PublicKeyResult, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetPublicKey", arg0, arg1, arg2)
+	ret0, _ := ret[0].(*devices.PublicKeyResult)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetPublicKey indicates an expected call of GetPublicKey.
+func (mr *MockDevicesServiceMockRecorder) GetPublicKey(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPublicKey", reflect.TypeOf((*MockDevicesService)(nil).GetPublicKey), arg0, arg1, arg2)
+}
+
+// GetRootCertificate mocks base method.
+func (m *MockDevicesService) GetRootCertificate(arg0 context.Context) ([]byte, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetRootCertificate", arg0)
+	ret0, _ := ret[0].([]byte)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetRootCertificate indicates an expected call of GetRootCertificate.
+func (mr *MockDevicesServiceMockRecorder) GetRootCertificate(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetRootCertificate", reflect.TypeOf((*MockDevicesService)(nil).GetRootCertificate), arg0)
+}
+
 // GetSignature mocks base method.
 func (m *MockDevicesService) GetSignature(arg0 context.Context, arg1 uuid.UUID, arg2 uint64) (devices.SignatureRecord, error) {
 	m.ctrl.T.Helper()
@@ -111,6 +171,51 @@ func (mr *MockDevicesServiceMockRecorder) GetSignature(arg0, arg1, arg2 interfac
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetSignature", reflect.TypeOf((*MockDevicesService)(nil).GetSignature), arg0, arg1, arg2)
 }
 
+// GetSignatureCMS mocks base method.
+func (m *MockDevicesService) GetSignatureCMS(arg0 context.Context, arg1 uuid.UUID, arg2 uint64) ([]byte, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetSignatureCMS", arg0, arg1, arg2)
+	ret0, _ := ret[0].([]byte)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetSignatureCMS indicates an expected call of GetSignatureCMS.
+func (mr *MockDevicesServiceMockRecorder) GetSignatureCMS(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetSignatureCMS", reflect.TypeOf((*MockDevicesService)(nil).GetSignatureCMS), arg0, arg1, arg2)
+}
+
+// GetSignatureCOSE mocks base method.
+func (m *MockDevicesService) GetSignatureCOSE(arg0 context.Context, arg1 uuid.UUID, arg2 uint64) ([]byte, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetSignatureCOSE", arg0, arg1, arg2)
+	ret0, _ := ret[0].([]byte)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetSignatureCOSE indicates an expected call of GetSignatureCOSE.
+func (mr *MockDevicesServiceMockRecorder) GetSignatureCOSE(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetSignatureCOSE", reflect.TypeOf((*MockDevicesService)(nil).GetSignatureCOSE), arg0, arg1, arg2)
+}
+
+// ImportDevice mocks base method.
+func (m *MockDevicesService) ImportDevice(arg0 context.Context, arg1 devices.ImportDeviceInput) (*devices.CreateDeviceResult, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ImportDevice", arg0, arg1)
+	ret0, _ := ret[0].(*devices.CreateDeviceResult)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ImportDevice indicates an expected call of ImportDevice.
+func (mr *MockDevicesServiceMockRecorder) ImportDevice(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ImportDevice", reflect.TypeOf((*MockDevicesService)(nil).ImportDevice), arg0, arg1)
+}
+
 // ListDevices mocks base method.
 func (m *MockDevicesService) ListDevices(arg0 context.Context) ([]domain.Device, error) {
 	m.ctrl.T.Helper()
@@ -141,6 +246,21 @@ func (mr *MockDevicesServiceMockRecorder) ListSignatures(arg0, arg1 interface{})
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListSignatures", reflect.TypeOf((*MockDevicesService)(nil).ListSignatures), arg0, arg1)
 }
 
+// RotateKey mocks base method.
+func (m *MockDevicesService) RotateKey(arg0 context.Context, arg1 uuid.UUID) (domain.Device, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RotateKey", arg0, arg1)
+	ret0, _ := ret[0].(domain.Device)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RotateKey indicates an expected call of RotateKey.
+func (mr *MockDevicesServiceMockRecorder) RotateKey(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RotateKey", reflect.TypeOf((*MockDevicesService)(nil).RotateKey), arg0, arg1)
+}
+
 // SignTransaction mocks base method.
 func (m *MockDevicesService) SignTransaction(arg0 context.Context, arg1 devices.SignTransactionInput) (*devices.SignatureResult, error) {
 	m.ctrl.T.Helper()
@@ -170,3 +290,33 @@ func (mr *MockDevicesServiceMockRecorder) UpdateDeviceLabel(arg0, arg1, arg2 int
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateDeviceLabel", reflect.TypeOf((*MockDevicesService)(nil).UpdateDeviceLabel), arg0, arg1, arg2)
 }
+
+// UploadCertificate mocks base method.
+func (m *MockDevicesService) UploadCertificate(arg0 context.Context, arg1 uuid.UUID, arg2 []byte) (*devices.CertificateResult, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UploadCertificate", arg0, arg1, arg2)
+	ret0, _ := ret[0].(*devices.CertificateResult)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UploadCertificate indicates an expected call of UploadCertificate.
+func (mr *MockDevicesServiceMockRecorder) UploadCertificate(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UploadCertificate", reflect.TypeOf((*MockDevicesService)(nil).UploadCertificate), arg0, arg1, arg2)
+}
+
+// VerifySignature mocks base method.
+func (m *MockDevicesService) VerifySignature(arg0 context.Context, arg1 devices.VerifySignatureInput) (*devices.VerificationResult, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "VerifySignature", arg0, arg1)
+	ret0, _ := ret[0].(*devices.VerificationResult)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// VerifySignature indicates an expected call of VerifySignature.
+func (mr *MockDevicesServiceMockRecorder) VerifySignature(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "VerifySignature", reflect.TypeOf((*MockDevicesService)(nil).VerifySignature), arg0, arg1)
+}
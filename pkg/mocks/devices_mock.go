@@ -1,5 +1,5 @@
 // Code generated by MockGen. DO NOT EDIT.
-// Source: github.com/fiskaly/coding-challenges/signing-service-challenge/internal/devices (interfaces: Repository,KeyStore,KeyGenerator,SignerFactory,Signer,SignatureStore)
+// Source: github.com/fiskaly/coding-challenges/signing-service-challenge/internal/devices (interfaces: Repository,KeyStore,KeyGenerator,SignerFactory,Signer,SignatureStore,Verifier,PublicKeyExporter,KeyImporter,CertificateIssuer,CertificateStore,CertificateRequester,SignedDataEncoder,Timestamper,KeyReleaser)
 
 // Package mocks is a generated GoMock package.
 package mocks
@@ -7,6 +7,7 @@ package mocks
 import (
 	context "context"
 	reflect "reflect"
+	time "time"
 
 	domain "github.com/fiskaly/coding-challenges/signing-service-challenge/domain"
 	devices "github.com/fiskaly/coding-challenges/signing-service-challenge/internal/devices"
@@ -146,33 +147,62 @@ func (mr *MockKeyStoreMockRecorder) Delete(arg0, arg1 interface{}) *gomock.Call
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Delete", reflect.TypeOf((*MockKeyStore)(nil).Delete), arg0, arg1)
 }
 
+// DeleteVersion mocks base method.
+func (m *MockKeyStore) DeleteVersion(arg0 context.Context, arg1 uuid.UUID, arg2 int) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteVersion", arg0, arg1, arg2)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteVersion indicates an expected call of DeleteVersion.
+func (mr *MockKeyStoreMockRecorder) DeleteVersion(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteVersion", reflect.TypeOf((*MockKeyStore)(nil).DeleteVersion), arg0, arg1, arg2)
+}
+
 // Load mocks base method.
-func (m *MockKeyStore) Load(arg0 context.Context, arg1 uuid.UUID) (domain.KeyMaterial, error) {
+func (m *MockKeyStore) Load(arg0 context.Context, arg1 uuid.UUID, arg2 int) (domain.KeyMaterial, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "Load", arg0, arg1)
+	ret := m.ctrl.Call(m, "Load", arg0, arg1, arg2)
 	ret0, _ := ret[0].(domain.KeyMaterial)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // Load indicates an expected call of Load.
-func (mr *MockKeyStoreMockRecorder) Load(arg0, arg1 interface{}) *gomock.Call {
+func (mr *MockKeyStoreMockRecorder) Load(arg0, arg1, arg2 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Load", reflect.TypeOf((*MockKeyStore)(nil).Load), arg0, arg1)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Load", reflect.TypeOf((*MockKeyStore)(nil).Load), arg0, arg1, arg2)
 }
 
 // Store mocks base method.
-func (m *MockKeyStore) Store(arg0 context.Context, arg1 uuid.UUID, arg2 domain.KeyMaterial) error {
+func (m *MockKeyStore) Store(arg0 context.Context, arg1 uuid.UUID, arg2 int, arg3 domain.KeyMaterial) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "Store", arg0, arg1, arg2)
+	ret := m.ctrl.Call(m, "Store", arg0, arg1, arg2, arg3)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
 // Store indicates an expected call of Store.
-func (mr *MockKeyStoreMockRecorder) Store(arg0, arg1, arg2 interface{}) *gomock.Call {
+func (mr *MockKeyStoreMockRecorder) Store(arg0, arg1, arg2, arg3 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Store", reflect.TypeOf((*MockKeyStore)(nil).Store), arg0, arg1, arg2, arg3)
+}
+
+// Versions mocks base method.
+func (m *MockKeyStore) Versions(arg0 context.Context, arg1 uuid.UUID) ([]int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Versions", arg0, arg1)
+	ret0, _ := ret[0].([]int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Versions indicates an expected call of Versions.
+func (mr *MockKeyStoreMockRecorder) Versions(arg0, arg1 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Store", reflect.TypeOf((*MockKeyStore)(nil).Store), arg0, arg1, arg2)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Versions", reflect.TypeOf((*MockKeyStore)(nil).Versions), arg0, arg1)
 }
 
 // MockKeyGenerator is a mock of KeyGenerator interface.
@@ -199,18 +229,18 @@ func (m *MockKeyGenerator) EXPECT() *MockKeyGeneratorMockRecorder {
 }
 
 // Generate mocks base method.
-func (m *MockKeyGenerator) Generate(arg0 domain.Algorithm) (domain.KeyMaterial, error) {
+func (m *MockKeyGenerator) Generate(arg0 domain.Algorithm, arg1 domain.KeySpec) (domain.KeyMaterial, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "Generate", arg0)
+	ret := m.ctrl.Call(m, "Generate", arg0, arg1)
 	ret0, _ := ret[0].(domain.KeyMaterial)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // Generate indicates an expected call of Generate.
-func (mr *MockKeyGeneratorMockRecorder) Generate(arg0 interface{}) *gomock.Call {
+func (mr *MockKeyGeneratorMockRecorder) Generate(arg0, arg1 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Generate", reflect.TypeOf((*MockKeyGenerator)(nil).Generate), arg0)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Generate", reflect.TypeOf((*MockKeyGenerator)(nil).Generate), arg0, arg1)
 }
 
 // MockSignerFactory is a mock of SignerFactory interface.
@@ -251,6 +281,21 @@ func (mr *MockSignerFactoryMockRecorder) SignerFor(arg0, arg1 interface{}) *gomo
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SignerFor", reflect.TypeOf((*MockSignerFactory)(nil).SignerFor), arg0, arg1)
 }
 
+// VerifierFor mocks base method.
+func (m *MockSignerFactory) VerifierFor(arg0 domain.Device, arg1 domain.KeyMaterial) (devices.Verifier, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "VerifierFor", arg0, arg1)
+	ret0, _ := ret[0].(devices.Verifier)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// VerifierFor indicates an expected call of VerifierFor.
+func (mr *MockSignerFactoryMockRecorder) VerifierFor(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "VerifierFor", reflect.TypeOf((*MockSignerFactory)(nil).VerifierFor), arg0, arg1)
+}
+
 // MockSigner is a mock of Signer interface.
 type MockSigner struct {
 	ctrl     *gomock.Controller
@@ -401,3 +446,415 @@ func (mr *MockSignatureStoreMockRecorder) List(arg0, arg1 interface{}) *gomock.C
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "List", reflect.TypeOf((*MockSignatureStore)(nil).List), arg0, arg1)
 }
+
+// MockVerifier is a mock of Verifier interface.
+type MockVerifier struct {
+	ctrl     *gomock.Controller
+	recorder *MockVerifierMockRecorder
+}
+
+// MockVerifierMockRecorder is the mock recorder for MockVerifier.
+type MockVerifierMockRecorder struct {
+	mock *MockVerifier
+}
+
+// NewMockVerifier creates a new mock instance.
+func NewMockVerifier(ctrl *gomock.Controller) *MockVerifier {
+	mock := &MockVerifier{ctrl: ctrl}
+	mock.recorder = &MockVerifierMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockVerifier) EXPECT() *MockVerifierMockRecorder {
+	return m.recorder
+}
+
+// Verify mocks base method.
+func (m *MockVerifier) Verify(arg0, arg1 []byte) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Verify", arg0, arg1)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Verify indicates an expected call of Verify.
+func (mr *MockVerifierMockRecorder) Verify(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Verify", reflect.TypeOf((*MockVerifier)(nil).Verify), arg0, arg1)
+}
+
+// MockPublicKeyExporter is a mock of PublicKeyExporter interface.
+type MockPublicKeyExporter struct {
+	ctrl     *gomock.Controller
+	recorder *MockPublicKeyExporterMockRecorder
+}
+
+// MockPublicKeyExporterMockRecorder is the mock recorder for MockPublicKeyExporter.
+type MockPublicKeyExporterMockRecorder struct {
+	mock *MockPublicKeyExporter
+}
+
+// NewMockPublicKeyExporter creates a new mock instance.
+func NewMockPublicKeyExporter(ctrl *gomock.Controller) *MockPublicKeyExporter {
+	mock := &MockPublicKeyExporter{ctrl: ctrl}
+	mock.recorder = &MockPublicKeyExporterMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockPublicKeyExporter) EXPECT() *MockPublicKeyExporterMockRecorder {
+	return m.recorder
+}
+
+// ExportPublicKey mocks base method.
+func (m *MockPublicKeyExporter) ExportPublicKey(arg0 domain.Device, arg1 domain.KeyMaterial) (devices.PublicKeyExport, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ExportPublicKey", arg0, arg1)
+	ret0, _ := ret[0].(devices.PublicKeyExport)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ExportPublicKey indicates an expected call of ExportPublicKey.
+func (mr *MockPublicKeyExporterMockRecorder) ExportPublicKey(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ExportPublicKey", reflect.TypeOf((*MockPublicKeyExporter)(nil).ExportPublicKey), arg0, arg1)
+}
+
+// MockKeyImporter is a mock of KeyImporter interface.
+type MockKeyImporter struct {
+	ctrl     *gomock.Controller
+	recorder *MockKeyImporterMockRecorder
+}
+
+// MockKeyImporterMockRecorder is the mock recorder for MockKeyImporter.
+type MockKeyImporterMockRecorder struct {
+	mock *MockKeyImporter
+}
+
+// NewMockKeyImporter creates a new mock instance.
+func NewMockKeyImporter(ctrl *gomock.Controller) *MockKeyImporter {
+	mock := &MockKeyImporter{ctrl: ctrl}
+	mock.recorder = &MockKeyImporterMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockKeyImporter) EXPECT() *MockKeyImporterMockRecorder {
+	return m.recorder
+}
+
+// Import mocks base method.
+func (m *MockKeyImporter) Import(arg0 domain.Algorithm, arg1 []byte) (devices.ImportedKey, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Import", arg0, arg1)
+	ret0, _ := ret[0].(devices.ImportedKey)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Import indicates an expected call of Import.
+func (mr *MockKeyImporterMockRecorder) Import(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Import", reflect.TypeOf((*MockKeyImporter)(nil).Import), arg0, arg1)
+}
+
+// MockCertificateIssuer is a mock of CertificateIssuer interface.
+type MockCertificateIssuer struct {
+	ctrl     *gomock.Controller
+	recorder *MockCertificateIssuerMockRecorder
+}
+
+// MockCertificateIssuerMockRecorder is the mock recorder for MockCertificateIssuer.
+type MockCertificateIssuerMockRecorder struct {
+	mock *MockCertificateIssuer
+}
+
+// NewMockCertificateIssuer creates a new mock instance.
+func NewMockCertificateIssuer(ctrl *gomock.Controller) *MockCertificateIssuer {
+	mock := &MockCertificateIssuer{ctrl: ctrl}
+	mock.recorder = &MockCertificateIssuerMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockCertificateIssuer) EXPECT() *MockCertificateIssuerMockRecorder {
+	return m.recorder
+}
+
+// Issue mocks base method.
+func (m *MockCertificateIssuer) Issue(arg0 domain.Device, arg1 []byte, arg2 time.Time) ([]byte, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Issue", arg0, arg1, arg2)
+	ret0, _ := ret[0].([]byte)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Issue indicates an expected call of Issue.
+func (mr *MockCertificateIssuerMockRecorder) Issue(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Issue", reflect.TypeOf((*MockCertificateIssuer)(nil).Issue), arg0, arg1, arg2)
+}
+
+// Root mocks base method.
+func (m *MockCertificateIssuer) Root() []byte {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Root")
+	ret0, _ := ret[0].([]byte)
+	return ret0
+}
+
+// Root indicates an expected call of Root.
+func (mr *MockCertificateIssuerMockRecorder) Root() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Root", reflect.TypeOf((*MockCertificateIssuer)(nil).Root))
+}
+
+// MockCertificateStore is a mock of CertificateStore interface.
+type MockCertificateStore struct {
+	ctrl     *gomock.Controller
+	recorder *MockCertificateStoreMockRecorder
+}
+
+// MockCertificateStoreMockRecorder is the mock recorder for MockCertificateStore.
+type MockCertificateStoreMockRecorder struct {
+	mock *MockCertificateStore
+}
+
+// NewMockCertificateStore creates a new mock instance.
+func NewMockCertificateStore(ctrl *gomock.Controller) *MockCertificateStore {
+	mock := &MockCertificateStore{ctrl: ctrl}
+	mock.recorder = &MockCertificateStoreMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockCertificateStore) EXPECT() *MockCertificateStoreMockRecorder {
+	return m.recorder
+}
+
+// Delete mocks base method.
+func (m *MockCertificateStore) Delete(arg0 context.Context, arg1 uuid.UUID) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Delete", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Delete indicates an expected call of Delete.
+func (mr *MockCertificateStoreMockRecorder) Delete(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Delete", reflect.TypeOf((*MockCertificateStore)(nil).Delete), arg0, arg1)
+}
+
+// DeleteVersion mocks base method.
+func (m *MockCertificateStore) DeleteVersion(arg0 context.Context, arg1 uuid.UUID, arg2 int) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteVersion", arg0, arg1, arg2)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteVersion indicates an expected call of DeleteVersion.
+func (mr *MockCertificateStoreMockRecorder) DeleteVersion(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteVersion", reflect.TypeOf((*MockCertificateStore)(nil).DeleteVersion), arg0, arg1, arg2)
+}
+
+// Get mocks base method.
+func (m *MockCertificateStore) Get(arg0 context.Context, arg1 uuid.UUID, arg2 int) ([][]byte, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Get", arg0, arg1, arg2)
+	ret0, _ := ret[0].([][]byte)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Get indicates an expected call of Get.
+func (mr *MockCertificateStoreMockRecorder) Get(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Get", reflect.TypeOf((*MockCertificateStore)(nil).Get), arg0, arg1, arg2)
+}
+
+// Put mocks base method.
+func (m *MockCertificateStore) Put(arg0 context.Context, arg1 uuid.UUID, arg2 int, arg3 [][]byte) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Put", arg0, arg1, arg2, arg3)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Put indicates an expected call of Put.
+func (mr *MockCertificateStoreMockRecorder) Put(arg0, arg1, arg2, arg3 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Put", reflect.TypeOf((*MockCertificateStore)(nil).Put), arg0, arg1, arg2, arg3)
+}
+
+// MockCertificateRequester is a mock of CertificateRequester interface.
+type MockCertificateRequester struct {
+	ctrl     *gomock.Controller
+	recorder *MockCertificateRequesterMockRecorder
+}
+
+// MockCertificateRequesterMockRecorder is the mock recorder for MockCertificateRequester.
+type MockCertificateRequesterMockRecorder struct {
+	mock *MockCertificateRequester
+}
+
+// NewMockCertificateRequester creates a new mock instance.
+func NewMockCertificateRequester(ctrl *gomock.Controller) *MockCertificateRequester {
+	mock := &MockCertificateRequester{ctrl: ctrl}
+	mock.recorder = &MockCertificateRequesterMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockCertificateRequester) EXPECT() *MockCertificateRequesterMockRecorder {
+	return m.recorder
+}
+
+// CheckChain mocks base method.
+func (m *MockCertificateRequester) CheckChain(arg0, arg1 []byte) ([][]byte, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CheckChain", arg0, arg1)
+	ret0, _ := ret[0].([][]byte)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CheckChain indicates an expected call of CheckChain.
+func (mr *MockCertificateRequesterMockRecorder) CheckChain(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CheckChain", reflect.TypeOf((*MockCertificateRequester)(nil).CheckChain), arg0, arg1)
+}
+
+// CreateRequest mocks base method.
+func (m *MockCertificateRequester) CreateRequest(arg0 domain.Device, arg1 []byte, arg2 devices.Signer) ([]byte, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateRequest", arg0, arg1, arg2)
+	ret0, _ := ret[0].([]byte)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateRequest indicates an expected call of CreateRequest.
+func (mr *MockCertificateRequesterMockRecorder) CreateRequest(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateRequest", reflect.TypeOf((*MockCertificateRequester)(nil).CreateRequest), arg0, arg1, arg2)
+}
+
+// MockSignedDataEncoder is a mock of SignedDataEncoder interface.
+type MockSignedDataEncoder struct {
+	ctrl     *gomock.Controller
+	recorder *MockSignedDataEncoderMockRecorder
+}
+
+// MockSignedDataEncoderMockRecorder is the mock recorder for MockSignedDataEncoder.
+type MockSignedDataEncoderMockRecorder struct {
+	mock *MockSignedDataEncoder
+}
+
+// NewMockSignedDataEncoder creates a new mock instance.
+func NewMockSignedDataEncoder(ctrl *gomock.Controller) *MockSignedDataEncoder {
+	mock := &MockSignedDataEncoder{ctrl: ctrl}
+	mock.recorder = &MockSignedDataEncoderMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockSignedDataEncoder) EXPECT() *MockSignedDataEncoderMockRecorder {
+	return m.recorder
+}
+
+// EncodeSignedData mocks base method.
+func (m *MockSignedDataEncoder) EncodeSignedData(arg0 domain.Device, arg1 []byte, arg2 [][]byte, arg3 []byte) ([]byte, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "EncodeSignedData", arg0, arg1, arg2, arg3)
+	ret0, _ := ret[0].([]byte)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// EncodeSignedData indicates an expected call of EncodeSignedData.
+func (mr *MockSignedDataEncoderMockRecorder) EncodeSignedData(arg0, arg1, arg2, arg3 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "EncodeSignedData", reflect.TypeOf((*MockSignedDataEncoder)(nil).EncodeSignedData), arg0, arg1, arg2, arg3)
+}
+
+// MockTimestamper is a mock of Timestamper interface.
+type MockTimestamper struct {
+	ctrl     *gomock.Controller
+	recorder *MockTimestamperMockRecorder
+}
+
+// MockTimestamperMockRecorder is the mock recorder for MockTimestamper.
+type MockTimestamperMockRecorder struct {
+	mock *MockTimestamper
+}
+
+// NewMockTimestamper creates a new mock instance.
+func NewMockTimestamper(ctrl *gomock.Controller) *MockTimestamper {
+	mock := &MockTimestamper{ctrl: ctrl}
+	mock.recorder = &MockTimestamperMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockTimestamper) EXPECT() *MockTimestamperMockRecorder {
+	return m.recorder
+}
+
+// Timestamp mocks base method.
+func (m *MockTimestamper) Timestamp(arg0 context.Context, arg1 []byte) ([]byte, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Timestamp", arg0, arg1)
+	ret0, _ := ret[0].([]byte)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Timestamp indicates an expected call of Timestamp.
+func (mr *MockTimestamperMockRecorder) Timestamp(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Timestamp", reflect.TypeOf((*MockTimestamper)(nil).Timestamp), arg0, arg1)
+}
+
+// MockKeyReleaser is a mock of KeyReleaser interface.
+type MockKeyReleaser struct {
+	ctrl     *gomock.Controller
+	recorder *MockKeyReleaserMockRecorder
+}
+
+// MockKeyReleaserMockRecorder is the mock recorder for MockKeyReleaser.
+type MockKeyReleaserMockRecorder struct {
+	mock *MockKeyReleaser
+}
+
+// NewMockKeyReleaser creates a new mock instance.
+func NewMockKeyReleaser(ctrl *gomock.Controller) *MockKeyReleaser {
+	mock := &MockKeyReleaser{ctrl: ctrl}
+	mock.recorder = &MockKeyReleaserMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockKeyReleaser) EXPECT() *MockKeyReleaserMockRecorder {
+	return m.recorder
+}
+
+// Release mocks base method.
+func (m *MockKeyReleaser) Release(arg0 domain.KeyMaterial) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Release", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Release indicates an expected call of Release.
+func (mr *MockKeyReleaserMockRecorder) Release(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Release", reflect.TypeOf((*MockKeyReleaser)(nil).Release), arg0)
+}